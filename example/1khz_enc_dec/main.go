@@ -3,11 +3,13 @@ package main
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
 	"os"
 
 	"github.com/justa-cai/go-libopus/ogg"
 	"github.com/justa-cai/go-libopus/opus"
+	"github.com/justa-cai/go-libopus/wav"
 )
 
 const (
@@ -16,11 +18,13 @@ const (
 	duration      = 10   // seconds
 	frequency     = 1000 // 1kHz
 	frameSize     = 480  // 10ms at 48kHz
-	maxFrameSize  = 6 * frameSize
 	maxPacketSize = 3 * 1276
 	bitrate       = 64000
 )
 
+// wavFormat is the format of the WAV files written
+var wavFormat = wav.Format{SampleRate: sampleRate, Channels: channels, SampleFormat: wav.PCM16}
+
 // generateSineWave generates a 1kHz sine wave for the specified duration
 func generateSineWave() []int16 {
 	samples := sampleRate * duration
@@ -34,89 +38,31 @@ func generateSineWave() []int16 {
 	return data
 }
 
-// writeWavHeader writes a WAV file header
-func writeWavHeader(file *os.File, numSamples int) error {
-	// RIFF header
-	if _, err := file.Write([]byte("RIFF")); err != nil {
-		return err
-	}
-	dataSize := numSamples * 2 // 2 bytes per sample
-	if err := binary.Write(file, binary.LittleEndian, uint32(36+dataSize)); err != nil {
-		return err
-	}
-	if _, err := file.Write([]byte("WAVE")); err != nil {
-		return err
-	}
-
-	// fmt chunk
-	if _, err := file.Write([]byte("fmt ")); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint32(16)); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint16(1)); err != nil { // PCM format
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint16(channels)); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint32(sampleRate)); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint32(sampleRate*channels*2)); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint16(channels*2)); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint16(16)); err != nil {
-		return err
-	}
-
-	// data chunk
-	if _, err := file.Write([]byte("data")); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, uint32(dataSize)); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // writeOpusHeader writes the Opus header packet
-func writeOpusHeader(stream *ogg.OggStreamState) error {
-	// OpusHead header format:
+func writeOpusHeader(writer *ogg.PacketWriter) error {
+	// OpusHead header format, multi-byte fields little endian:
 	// - Magic signature: "OpusHead" (8 bytes)
 	// - Version number: 1 (1 byte)
 	// - Channel count: 1 (1 byte)
-	// - Pre-skip: 0 (2 bytes, little endian)
-	// - Input sample rate: 48000 (4 bytes, little endian)
-	// - Output gain: 0 (2 bytes, little endian)
+	// - Pre-skip: 0 (2 bytes)
+	// - Input sample rate: 48000 (4 bytes)
+	// - Output gain: 0 (2 bytes)
 	// - Channel mapping family: 0 (1 byte)
-	header := make([]byte, 19)
-
-	// Magic signature
-	copy(header[0:8], []byte("OpusHead"))
-
-	// Version number
-	header[8] = 1
-
-	// Channel count
-	header[9] = byte(channels)
-
-	// Pre-skip (0)
-	binary.LittleEndian.PutUint16(header[10:12], 0)
-
-	// Input sample rate (48000)
-	binary.LittleEndian.PutUint32(header[12:16], uint32(sampleRate))
-
-	// Output gain (0)
-	binary.LittleEndian.PutUint16(header[16:18], 0)
-
-	// Channel mapping family (0)
-	header[18] = 0
+	bits, err := ogg.NewBitWriter(ogg.LSBFirst)
+	if err != nil {
+		return err
+	}
+	defer bits.Close()
+	bits.WriteBytes([]byte("OpusHead"))
+	bits.Write(1, 8)
+	bits.Write(channels, 8)
+	bits.Write(0, 16)
+	bits.Write(sampleRate, 32)
+	bits.Write(0, 16)
+	if err := bits.Write(0, 8); err != nil {
+		return err
+	}
+	header := bits.Bytes()
 
 	packet := &ogg.OggPacket{
 		Packet:     header,
@@ -127,35 +73,31 @@ func writeOpusHeader(stream *ogg.OggStreamState) error {
 		Packetno:   0,
 	}
 
-	return stream.PacketIn(packet)
+	return writer.WritePacket(packet)
 }
 
 // writeOpusComments writes the Opus comments packet
-func writeOpusComments(stream *ogg.OggStreamState) error {
-	// OpusTags header format:
+func writeOpusComments(writer *ogg.PacketWriter) error {
+	// OpusTags header format, lengths little endian:
 	// - Magic signature: "OpusTags" (8 bytes)
-	// - Vendor string length (4 bytes, little endian)
+	// - Vendor string length (4 bytes)
 	// - Vendor string (variable length)
-	// - User comment list length (4 bytes, little endian)
+	// - User comment list length (4 bytes)
 	// - User comments (variable length)
 	vendor := "go-libopus 1.0.0"
-	vendorLength := len(vendor)
 
-	// Calculate total size
-	totalSize := 8 + 4 + vendorLength + 4
-	header := make([]byte, totalSize)
-
-	// Magic signature
-	copy(header[0:8], []byte("OpusTags"))
-
-	// Vendor string length
-	binary.LittleEndian.PutUint32(header[8:12], uint32(vendorLength))
-
-	// Vendor string
-	copy(header[12:12+vendorLength], []byte(vendor))
-
-	// User comment list length (0)
-	binary.LittleEndian.PutUint32(header[12+vendorLength:], 0)
+	bits, err := ogg.NewBitWriter(ogg.LSBFirst)
+	if err != nil {
+		return err
+	}
+	defer bits.Close()
+	bits.WriteBytes([]byte("OpusTags"))
+	bits.Write(uint32(len(vendor)), 32)
+	bits.WriteBytes([]byte(vendor))
+	if err := bits.Write(0, 32); err != nil {
+		return err
+	}
+	header := bits.Bytes()
 
 	packet := &ogg.OggPacket{
 		Packet:     header,
@@ -166,7 +108,11 @@ func writeOpusComments(stream *ogg.OggStreamState) error {
 		Packetno:   1,
 	}
 
-	return stream.PacketIn(packet)
+	// The comment header ends its page, audio starts on a new one
+	if err := writer.WritePacket(packet); err != nil {
+		return err
+	}
+	return writer.Flush()
 }
 
 // encodeAndSave encodes the audio data and saves it as OGG
@@ -186,13 +132,6 @@ func encodeAndSave(audioData []int16, filename string) error {
 		return fmt.Errorf("failed to set complexity: %v", err)
 	}
 
-	// Create OGG stream
-	stream, err := ogg.NewOggStreamState(1)
-	if err != nil {
-		return fmt.Errorf("failed to create ogg stream: %v", err)
-	}
-	defer stream.Clear()
-
 	// Create output file
 	file, err := os.Create(filename)
 	if err != nil {
@@ -200,52 +139,20 @@ func encodeAndSave(audioData []int16, filename string) error {
 	}
 	defer file.Close()
 
-	// Write headers
-	if err := writeOpusHeader(stream); err != nil {
-		return fmt.Errorf("failed to write opus header: %v", err)
+	// Create OGG packet writer
+	writer, err := ogg.NewPacketWriter(file, 1)
+	if err != nil {
+		return fmt.Errorf("failed to create ogg writer: %v", err)
 	}
 
-	// Write header pages
-	for {
-		page := &ogg.OggPage{}
-		ret, err := stream.PageOut(page)
-		if err != nil {
-			return fmt.Errorf("failed to get page: %v", err)
-		}
-		if ret == 0 {
-			break
-		}
-		if _, err := file.Write(page.Header); err != nil {
-			return fmt.Errorf("failed to write page header: %v", err)
-		}
-		if _, err := file.Write(page.Body); err != nil {
-			return fmt.Errorf("failed to write page body: %v", err)
-		}
+	// Write headers
+	if err := writeOpusHeader(writer); err != nil {
+		return fmt.Errorf("failed to write opus header: %v", err)
 	}
-
-	// Write comments
-	if err := writeOpusComments(stream); err != nil {
+	if err := writeOpusComments(writer); err != nil {
 		return fmt.Errorf("failed to write opus comments: %v", err)
 	}
 
-	// Write comment pages
-	for {
-		page := &ogg.OggPage{}
-		ret, err := stream.PageOut(page)
-		if err != nil {
-			return fmt.Errorf("failed to get page: %v", err)
-		}
-		if ret == 0 {
-			break
-		}
-		if _, err := file.Write(page.Header); err != nil {
-			return fmt.Errorf("failed to write page header: %v", err)
-		}
-		if _, err := file.Write(page.Body); err != nil {
-			return fmt.Errorf("failed to write page body: %v", err)
-		}
-	}
-
 	// Encode frames
 	numFrames := len(audioData) / frameSize
 	packetNo := int64(2)
@@ -283,46 +190,15 @@ func encodeAndSave(audioData []int16, filename string) error {
 		packetNo++
 		totalSamples += frameSize
 
-		// Add packet to stream
-		if err := stream.PacketIn(packet); err != nil {
-			return fmt.Errorf("failed to add packet to stream: %v", err)
-		}
-
-		// Write pages
-		for {
-			page := &ogg.OggPage{}
-			ret, err := stream.PageOut(page)
-			if err != nil {
-				return fmt.Errorf("failed to get page: %v", err)
-			}
-			if ret == 0 {
-				break
-			}
-			if _, err := file.Write(page.Header); err != nil {
-				return fmt.Errorf("failed to write page header: %v", err)
-			}
-			if _, err := file.Write(page.Body); err != nil {
-				return fmt.Errorf("failed to write page body: %v", err)
-			}
+		// Add packet to stream, writing out completed pages
+		if err := writer.WritePacket(packet); err != nil {
+			return fmt.Errorf("failed to write packet: %v", err)
 		}
 	}
 
 	// Flush remaining pages
-	for {
-		page := &ogg.OggPage{}
-		ret, err := stream.Flush(page)
-		if err != nil {
-			return fmt.Errorf("failed to flush stream: %v", err)
-		}
-		if ret == 0 {
-			break
-		}
-		if _, err := file.Write(page.Header); err != nil {
-			return fmt.Errorf("failed to write page header: %v", err)
-		}
-		if _, err := file.Write(page.Body); err != nil {
-			return fmt.Errorf("failed to write page body: %v", err)
-		}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to flush stream: %v", err)
 	}
 
 	return nil
@@ -344,12 +220,12 @@ func decodeAndSave(inputFile, outputFile string) error {
 	}
 	defer decoder.Close()
 
-	// Create OGG sync state
-	sync, err := ogg.NewOggSyncState()
+	// Create OGG packet reader
+	reader, err := ogg.NewPacketReader(file)
 	if err != nil {
-		return fmt.Errorf("failed to create sync state: %v", err)
+		return fmt.Errorf("failed to create ogg reader: %v", err)
 	}
-	defer sync.Clear()
+	defer reader.Close()
 
 	// Create output WAV file
 	outFile, err := os.Create(outputFile)
@@ -358,101 +234,54 @@ func decodeAndSave(inputFile, outputFile string) error {
 	}
 	defer outFile.Close()
 
-	// Write WAV header (will be updated later)
-	if err := writeWavHeader(outFile, 0); err != nil {
+	// Write WAV header, sizes are fixed up on Close
+	wavWriter, err := wav.NewWriter(outFile, wavFormat)
+	if err != nil {
 		return fmt.Errorf("failed to write WAV header: %v", err)
 	}
 
-	// Read and process OGG pages
-	buffer := make([]byte, 4096)
+	// Read and process OGG packets
 	packetCount := 0
 	totalSamples := 0
-	var stream *ogg.OggStreamState
 
 	for {
-		// Read data into buffer
-		n, err := file.Read(buffer)
-		if err != nil {
+		packet, err := reader.Next()
+		if err == io.EOF {
 			break
 		}
-
-		// Get buffer from sync state
-		oggBuffer, err := sync.Buffer(n)
-		if err != nil {
-			return fmt.Errorf("failed to get buffer: %v", err)
-		}
-
-		// Copy data to OGG buffer
-		copy(oggBuffer, buffer[:n])
-
-		// Mark bytes as written
-		if err := sync.Wrote(n); err != nil {
-			return fmt.Errorf("failed to mark bytes as written: %v", err)
-		}
-
-		// Process pages
-		for {
-			page := &ogg.OggPage{}
-			ret, err := sync.PageOut(page)
+		if err == ogg.ErrGap {
+			// Packets were lost, conceal one frame of audio
+			concealed := make([]byte, frameSize*2)
+			n, err := decoder.DecodePLC(concealed, frameSize)
 			if err != nil {
-				return fmt.Errorf("failed to get page: %v", err)
+				return fmt.Errorf("failed to conceal lost packets: %v", err)
 			}
-			if ret == 0 {
-				break
-			}
-
-			// Initialize stream if needed
-			if stream == nil {
-				stream, err = ogg.NewOggStreamState(1)
-				if err != nil {
-					return fmt.Errorf("failed to create stream: %v", err)
-				}
-				defer stream.Clear()
+			if err := wavWriter.WritePCM16(concealed[:n*2]); err != nil {
+				return fmt.Errorf("failed to write decoded data: %v", err)
 			}
+			totalSamples += n
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get packet: %v", err)
+		}
 
-			// Add page to stream
-			if err := stream.PageIn(page); err != nil {
-				return fmt.Errorf("failed to add page to stream: %v", err)
-			}
+		// Skip header packets
+		if packetCount < 2 {
+			packetCount++
+			continue
+		}
 
-			// Process packets
-			for {
-				packet := &ogg.OggPacket{}
-				ret, err := stream.PacketOut(packet)
-				if err != nil {
-					return fmt.Errorf("failed to get packet: %v", err)
-				}
-				if ret == 0 {
-					break
-				}
-
-				// Skip header packets
-				if packetCount < 2 {
-					packetCount++
-					continue
-				}
-
-				// Decode packet
-				decodedData := make([]byte, maxFrameSize*2)
-				decodedSize, err := decoder.Decode(packet.Packet, decodedData)
-				if err != nil {
-					return fmt.Errorf("failed to decode data: %v", err)
-				}
-
-				// Write decoded data
-				if _, err := outFile.Write(decodedData[:decodedSize*2]); err != nil {
-					return fmt.Errorf("failed to write decoded data: %v", err)
-				}
-				totalSamples += decodedSize
-			}
+		// Decode packet and write decoded data
+		decodedSize, err := wavWriter.DecodePacket(decoder, packet.Packet)
+		if err != nil {
+			return fmt.Errorf("failed to decode data: %v", err)
 		}
+		totalSamples += decodedSize
 	}
 
 	// Update WAV header with correct size
-	if _, err := outFile.Seek(0, 0); err != nil {
-		return fmt.Errorf("failed to seek to start of file: %v", err)
-	}
-	if err := writeWavHeader(outFile, totalSamples); err != nil {
+	if err := wavWriter.Close(); err != nil {
 		return fmt.Errorf("failed to update WAV header: %v", err)
 	}
 
@@ -481,7 +310,8 @@ func main() {
 	}
 	defer rawFile.Close()
 
-	if err := writeWavHeader(rawFile, len(audioData)); err != nil {
+	rawWriter, err := wav.NewWriter(rawFile, wavFormat)
+	if err != nil {
 		fmt.Printf("Error writing WAV header: %v\n", err)
 		return
 	}
@@ -491,10 +321,14 @@ func main() {
 	for i, sample := range audioData {
 		binary.LittleEndian.PutUint16(rawData[i*2:], uint16(sample))
 	}
-	if _, err := rawFile.Write(rawData); err != nil {
+	if err := rawWriter.WritePCM16(rawData); err != nil {
 		fmt.Printf("Error writing raw data: %v\n", err)
 		return
 	}
+	if err := rawWriter.Close(); err != nil {
+		fmt.Printf("Error writing WAV header: %v\n", err)
+		return
+	}
 
 	// Encode and save as OGG
 	fmt.Println("Encoding and saving as OGG...")
@@ -0,0 +1,296 @@
+// Command vendorc vendors the libopus or libogg C sources for the vendored
+// build tag. It extracts a release archive into the upstream directory of
+// the current package and writes one wrapper .c file per source file, since
+// cgo only compiles .c files in the package directory.
+//
+// It is run by go generate in internal/libopus and internal/libogg:
+//
+//	go generate ./internal/libopus ./internal/libogg
+//
+// The -src flag takes a local archive or extracted source tree instead of
+// downloading the release.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// header marks the files written by vendorc, which are removed on the next run
+const header = "// Code generated by vendorc; DO NOT EDIT.\n"
+
+// library describes how to vendor one library
+type library struct {
+	pkg     string                                // Go package name
+	url     string                                // release archive, %s is the version
+	keep    func(name string) bool                // reports whether to extract a file
+	sources func(files map[string][]byte) []group // source files to compile
+}
+
+// group is a set of source files compiled under the same build constraint
+type group struct {
+	constraint string
+	files      []string
+}
+
+var libraries = map[string]library{
+	"opus": {
+		pkg: "libopus",
+		url: "https://downloads.xiph.org/releases/opus/opus-%s.tar.gz",
+		keep: func(name string) bool {
+			if strings.Contains(name, "/tests/") {
+				return false
+			}
+			dir, _, _ := strings.Cut(name, "/")
+			switch dir {
+			case "include", "celt", "silk", "src":
+				return true
+			case "dnn":
+				// Only headers, the neural network features are not built
+				return strings.HasSuffix(name, ".h")
+			}
+			return name == "COPYING" || name == "AUTHORS"
+		},
+		sources: func(files map[string][]byte) []group {
+			vars := makeVars(files, "celt_sources.mk", "silk_sources.mk", "opus_sources.mk")
+			return []group{
+				{"vendored", concat(vars["CELT_SOURCES"], vars["SILK_SOURCES"], vars["OPUS_SOURCES"], vars["OPUS_SOURCES_FLOAT"])},
+				{"vendored && !opus_fixed", vars["SILK_SOURCES_FLOAT"]},
+				{"vendored && opus_fixed", vars["SILK_SOURCES_FIXED"]},
+			}
+		},
+	},
+	"ogg": {
+		pkg: "libogg",
+		url: "https://downloads.xiph.org/releases/ogg/libogg-%s.tar.gz",
+		keep: func(name string) bool {
+			return strings.HasPrefix(name, "src/") && !strings.Contains(name, "test") || name == "COPYING"
+		},
+		sources: func(files map[string][]byte) []group {
+			return []group{{"vendored", []string{"src/bitwise.c", "src/framing.c"}}}
+		},
+	},
+}
+
+func main() {
+	name := flag.String("lib", "", "library to vendor: opus or ogg")
+	version := flag.String("version", "", "release version")
+	src := flag.String("src", "", "local archive or source tree instead of the release download")
+	out := flag.String("out", ".", "package directory")
+	flag.Parse()
+
+	lib, ok := libraries[*name]
+	if !ok || *version == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, sum, err := load(fmt.Sprintf(lib.url, *version), *src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := clean(*out); err != nil {
+		log.Fatal(err)
+	}
+
+	upstream := filepath.Join(*out, "upstream")
+	for name, data := range files {
+		if !lib.keep(name) {
+			continue
+		}
+		target := filepath.Join(upstream, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Included by the sources when HAVE_CONFIG_H is defined
+	config := fmt.Sprintf("/* Code generated by vendorc; DO NOT EDIT. */\n\n#define PACKAGE_VERSION %q\n", *version)
+	if err := os.WriteFile(filepath.Join(upstream, "config.h"), []byte(config), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, g := range lib.sources(files) {
+		for _, file := range g.files {
+			if _, ok := files[file]; !ok {
+				log.Fatalf("source %s missing from archive", file)
+			}
+			wrapper := fmt.Sprintf("%s\n//go:build %s\n\n#include \"upstream/%s\"\n", header, g.constraint, file)
+			target := filepath.Join(*out, strings.ReplaceAll(file, "/", "_"))
+			if err := os.WriteFile(target, []byte(wrapper), 0o644); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	generated := fmt.Sprintf(`%s
+//go:build vendored
+
+package %s
+
+// Version is the version of the vendored sources
+const Version = %q
+
+// SourceSHA256 is the checksum of the release archive the sources were taken
+// from, empty if they were taken from a source tree
+const SourceSHA256 = %q
+`, header, lib.pkg, *version, sum)
+	if err := os.WriteFile(filepath.Join(*out, "generated.go"), []byte(generated), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// load returns the files of the release archive at url, or of src if set,
+// keyed by slash separated path relative to the top directory, and the
+// checksum of the archive
+func load(url, src string) (map[string][]byte, string, error) {
+	if info, err := os.Stat(src); err == nil && info.IsDir() {
+		return loadDir(src)
+	}
+
+	var archive []byte
+	var err error
+	if src != "" {
+		archive, err = os.ReadFile(src)
+	} else {
+		archive, err = download(url)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(archive)
+	files, err := untar(archive)
+	return files, hex.EncodeToString(sum[:]), err
+}
+
+// download fetches url
+func download(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// untar reads the regular files of a gzipped tar archive, stripping the top
+// directory
+func untar(archive []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, name, ok := strings.Cut(path.Clean(hdr.Name), "/")
+		if hdr.Typeflag != tar.TypeReg || !ok {
+			continue
+		}
+		if files[name], err = io.ReadAll(tr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// loadDir reads the regular files of an extracted source tree
+func loadDir(dir string) (map[string][]byte, string, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)], err = os.ReadFile(file)
+		return err
+	})
+	return files, "", err
+}
+
+// clean removes the files written by an earlier run
+func clean(dir string) error {
+	if err := os.RemoveAll(filepath.Join(dir, "upstream")); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		file := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(file)
+		if err != nil || entry.IsDir() || !bytes.HasPrefix(data, []byte(header)) {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeVars parses the variable assignments of automake fragments
+func makeVars(files map[string][]byte, names ...string) map[string][]string {
+	vars := make(map[string][]string)
+	for _, name := range names {
+		data, ok := files[name]
+		if !ok {
+			log.Fatalf("%s missing from archive", name)
+		}
+		text := strings.ReplaceAll(string(data), "\\\n", " ")
+		for _, line := range strings.Split(text, "\n") {
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key = strings.TrimSpace(key)
+			if base, ok := strings.CutSuffix(key, "+"); ok {
+				key = strings.TrimSpace(base)
+				vars[key] = append(vars[key], strings.Fields(value)...)
+				continue
+			}
+			vars[key] = strings.Fields(value)
+		}
+	}
+	return vars
+}
+
+// concat joins lists of files in a stable order
+func concat(lists ...[]string) []string {
+	var files []string
+	for _, list := range lists {
+		files = append(files, list...)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,123 @@
+// Package dl locates shared libraries at run time for the dlopen builds of
+// the opus and ogg packages. The loading itself is done in C by dl.h, which
+// those packages include.
+package dl
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Library describes a shared library to be located at run time and tracks
+// whether it was loaded
+type Library struct {
+	Name  string   // name used in errors, e.g. "libopus"
+	Env   string   // environment variable holding the path of the library
+	Names []string // file names tried in order when no path is given
+
+	mu   sync.Mutex
+	path string // path the library was loaded from, "" until loaded
+	err  error  // failure of the implicit load
+}
+
+// Names returns the usual file names of the library with the given base name
+// and major version on the current platform, e.g. libopus.so.0 and
+// libopus.so for "opus" and "0" on Linux
+func Names(base, major string) []string {
+	switch runtime.GOOS {
+	case "windows":
+		return []string{base + ".dll", "lib" + base + "-" + major + ".dll", "lib" + base + ".dll"}
+	case "darwin":
+		names := []string{"lib" + base + "." + major + ".dylib", "lib" + base + ".dylib"}
+		// Homebrew prefixes are not searched by dlopen
+		for _, dir := range []string{"/opt/homebrew/lib/", "/usr/local/lib/"} {
+			names = append(names, dir+names[0])
+		}
+		return names
+	default:
+		return []string{"lib" + base + ".so." + major, "lib" + base + ".so"}
+	}
+}
+
+// Error reports that a library could not be loaded from any candidate
+type Error struct {
+	Library  string
+	Env      string
+	Paths    []string // candidates tried, in order
+	Failures []string // why each candidate failed
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Library + " could not be loaded, set " + e.Env + " to its path")
+	sep := ": "
+	for i, path := range e.Paths {
+		sb.WriteString(sep)
+		sep = "; "
+		// dlerror names the path, LoadLibrary errors do not
+		if !strings.Contains(e.Failures[i], path) {
+			sb.WriteString(path + ": ")
+		}
+		sb.WriteString(e.Failures[i])
+	}
+	return sb.String()
+}
+
+// Load loads the library with open, which returns a description of the
+// failure or "" on success. It tries path, or if path is empty the path in
+// the environment variable, or else each of the default names. It does
+// nothing once the library is loaded.
+func (l *Library) Load(path string, open func(path string) string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.load(path, open)
+}
+
+// Implicit loads the library like Load with an empty path on first use. A
+// failure is remembered, so later calls fail quickly until Load succeeds.
+func (l *Library) Implicit(open func(path string) string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.path == "" && l.err == nil {
+		l.err = l.load("", open)
+	}
+	if l.path != "" {
+		return nil
+	}
+	return l.err
+}
+
+// Path returns the path the library was loaded from, or "" if it was not
+// loaded
+func (l *Library) Path() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.path
+}
+
+func (l *Library) load(path string, open func(path string) string) error {
+	if l.path != "" {
+		return nil
+	}
+	if path == "" {
+		path = os.Getenv(l.Env)
+	}
+	paths := l.Names
+	if path != "" {
+		paths = []string{path}
+	}
+
+	err := &Error{Library: l.Name, Env: l.Env}
+	for _, candidate := range paths {
+		failure := open(candidate)
+		if failure == "" {
+			l.path = candidate
+			return nil
+		}
+		err.Paths = append(err.Paths, candidate)
+		err.Failures = append(err.Failures, failure)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+/* Loading of shared libraries for the dlopen builds of the opus and ogg
+ * packages, which define the library functions as stubs calling through the
+ * pointers resolved here. */
+#ifndef GO_LIBOPUS_DL_H
+#define GO_LIBOPUS_DL_H
+
+#include <stdio.h>
+
+#ifdef _WIN32
+#include <windows.h>
+
+static void *dl_open(const char *path) {
+    return (void *)LoadLibraryA(path);
+}
+
+static void *dl_sym(void *handle, const char *name) {
+    return (void *)GetProcAddress((HMODULE)handle, name);
+}
+
+static void dl_close(void *handle) {
+    FreeLibrary((HMODULE)handle);
+}
+
+static const char *dl_error(void) {
+    static char msg[64];
+    snprintf(msg, sizeof(msg), "error %lu", (unsigned long)GetLastError());
+    return msg;
+}
+#else
+#include <dlfcn.h>
+
+static void *dl_open(const char *path) {
+    return dlopen(path, RTLD_NOW | RTLD_LOCAL);
+}
+
+static void *dl_sym(void *handle, const char *name) {
+    return dlsym(handle, name);
+}
+
+static void dl_close(void *handle) {
+    dlclose(handle);
+}
+
+static const char *dl_error(void) {
+    const char *msg = dlerror();
+    return msg ? msg : "unknown error";
+}
+#endif
+
+/* dl_missing formats the error for a symbol missing from a library */
+static const char *dl_missing(const char *name) {
+    static char msg[128];
+    snprintf(msg, sizeof(msg), "missing symbol %s", name);
+    return msg;
+}
+
+#endif
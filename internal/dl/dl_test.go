@@ -0,0 +1,94 @@
+package dl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/internal/dl"
+)
+
+// opener returns an open function that loads only the given path and records
+// the paths tried
+func opener(loadable string, tried *[]string) func(path string) string {
+	return func(path string) string {
+		*tried = append(*tried, path)
+		if path == loadable {
+			return ""
+		}
+		return "cannot open shared object file"
+	}
+}
+
+func TestLoadOrder(t *testing.T) {
+	t.Setenv("DL_TEST_PATH", "")
+	lib := &dl.Library{Name: "libtest", Env: "DL_TEST_PATH", Names: []string{"libtest.so.1", "libtest.so"}}
+
+	var tried []string
+	if err := lib.Load("", opener("libtest.so", &tried)); err != nil {
+		t.Fatalf("Failed to load library: %v", err)
+	}
+	if strings.Join(tried, ",") != "libtest.so.1,libtest.so" || lib.Path() != "libtest.so" {
+		t.Errorf("Expected both names tried and libtest.so loaded, tried %v and loaded %q", tried, lib.Path())
+	}
+
+	// Loading again does nothing
+	tried = nil
+	if err := lib.Load("/other/libtest.so", opener("", &tried)); err != nil || len(tried) != 0 {
+		t.Errorf("Expected no further load, got %v after trying %v", err, tried)
+	}
+}
+
+func TestLoadPath(t *testing.T) {
+	t.Setenv("DL_TEST_PATH", "/env/libtest.so")
+	names := []string{"libtest.so.1"}
+
+	// The environment variable replaces the default names
+	var tried []string
+	lib := &dl.Library{Name: "libtest", Env: "DL_TEST_PATH", Names: names}
+	err := lib.Load("", opener("libtest.so.1", &tried))
+	if err == nil || strings.Join(tried, ",") != "/env/libtest.so" {
+		t.Fatalf("Expected only the environment path tried, got %v after trying %v", err, tried)
+	}
+	expected := "libtest could not be loaded, set DL_TEST_PATH to its path: /env/libtest.so: cannot open shared object file"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err)
+	}
+
+	// An explicit path replaces the environment variable
+	tried = nil
+	lib = &dl.Library{Name: "libtest", Env: "DL_TEST_PATH", Names: names}
+	if err := lib.Load("/explicit/libtest.so", opener("/explicit/libtest.so", &tried)); err != nil {
+		t.Fatalf("Failed to load library: %v", err)
+	}
+	if strings.Join(tried, ",") != "/explicit/libtest.so" {
+		t.Errorf("Expected only the explicit path tried, tried %v", tried)
+	}
+}
+
+func TestImplicitLoad(t *testing.T) {
+	t.Setenv("DL_TEST_PATH", "")
+	lib := &dl.Library{Name: "libtest", Env: "DL_TEST_PATH", Names: []string{"libtest.so.1", "libtest.so"}}
+
+	var tried []string
+	err := lib.Implicit(opener("", &tried))
+	if _, ok := err.(*dl.Error); !ok {
+		t.Fatalf("Expected *dl.Error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "libtest.so.1: cannot open") || !strings.Contains(err.Error(), "; libtest.so: cannot open") {
+		t.Errorf("Expected both names in error, got %q", err)
+	}
+
+	// The failure is remembered
+	tried = nil
+	if err := lib.Implicit(opener("libtest.so", &tried)); err == nil || len(tried) != 0 {
+		t.Errorf("Expected remembered failure, got %v after trying %v", err, tried)
+	}
+
+	// until an explicit load succeeds
+	if err := lib.Load("/opt/libtest.so", opener("/opt/libtest.so", &tried)); err != nil {
+		t.Fatalf("Failed to load library: %v", err)
+	}
+	if err := lib.Implicit(opener("", &tried)); err != nil {
+		t.Errorf("Expected implicit load to succeed after Load, got %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package libogg compiles the vendored libogg sources into the ogg package
+// for the vendored build tag. They are compiled against the headers of the
+// ogg package, so the structures match the bindings.
+//
+// The sources in the upstream directory and the wrapper files compiling them
+// are committed, so the vendored build needs no network access. go generate
+// rewrites them from the release archive when updating the version.
+package libogg
+
+//go:generate go run ../cmd/vendorc -lib ogg -version 1.3.5
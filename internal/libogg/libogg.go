@@ -0,0 +1,10 @@
+//go:build vendored
+
+package libogg
+
+// #cgo CFLAGS: -I${SRCDIR}/../../ogg/include
+import "C"
+
+// Version is declared by generated.go, written by go generate along with the
+// sources
+var _ = Version
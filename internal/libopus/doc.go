@@ -0,0 +1,10 @@
+// Package libopus compiles the vendored libopus sources into the opus
+// package for the vendored build tag. The opus_fixed build tag selects the
+// fixed-point build instead of floating point.
+//
+// The sources in the upstream directory and the wrapper files compiling them
+// are committed, so the vendored build needs no network access. go generate
+// rewrites them from the release archive when updating the version.
+package libopus
+
+//go:generate go run ../cmd/vendorc -lib opus -version 1.5.2
@@ -0,0 +1,12 @@
+//go:build vendored
+
+package libopus
+
+// #cgo CFLAGS: -I${SRCDIR}/upstream/include -I${SRCDIR}/upstream/celt -I${SRCDIR}/upstream/silk
+// #cgo CFLAGS: -I${SRCDIR}/upstream -DHAVE_CONFIG_H -DOPUS_BUILD -DVAR_ARRAYS -DHAVE_LRINT -DHAVE_LRINTF
+// #cgo linux LDFLAGS: -lm
+import "C"
+
+// Version is declared by generated.go, written by go generate along with the
+// sources
+var _ = Version
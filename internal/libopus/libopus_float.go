@@ -0,0 +1,6 @@
+//go:build vendored && !opus_fixed
+
+package libopus
+
+// #cgo CFLAGS: -I${SRCDIR}/upstream/silk/float
+import "C"
@@ -0,0 +1,207 @@
+// Package netsim simulates an impaired network path between an encoder and
+// a decoder: packet loss, delay jitter, reordering and duplication, all
+// drawn from a seeded generator so that a simulation can be reproduced
+package netsim
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// LossModel decides which packets a Channel loses
+type LossModel interface {
+	// Lost reports whether the next packet is lost, drawing from rng
+	Lost(rng *rand.Rand) bool
+}
+
+// Bernoulli loses each packet independently with probability P
+type Bernoulli struct {
+	P float64
+}
+
+// Lost implements LossModel
+func (b Bernoulli) Lost(rng *rand.Rand) bool {
+	return rng.Float64() < b.P
+}
+
+// GilbertElliott is a two-state Markov model of bursty loss. Before each
+// packet the state changes from good to bad with probability P and from bad
+// to good with probability R; the packet is then lost with probability
+// LossGood or LossBad depending on the state.
+type GilbertElliott struct {
+	P        float64 // probability of going from the good to the bad state
+	R        float64 // probability of going from the bad to the good state
+	LossGood float64 // loss probability in the good state, usually 0
+	LossBad  float64 // loss probability in the bad state, usually 1
+
+	bad bool
+}
+
+// NewGilbertElliott creates the Gilbert model losing all packets in the bad
+// state and none in the good state, for a mean loss rate and a mean burst
+// length in packets. The loss rate is clamped to [0, 1] and the burst length
+// to at least 1. A loss rate too high for bursts that short, above
+// burstLength / (burstLength+1), lengthens the bursts instead, as the good
+// state lasts at least a packet.
+func NewGilbertElliott(lossRate float64, burstLength float64) *GilbertElliott {
+	lossRate = min(max(lossRate, 0), 1)
+	if lossRate == 1 {
+		return &GilbertElliott{P: 1, LossBad: 1}
+	}
+	r := min(1/max(burstLength, 1), (1-lossRate)/lossRate)
+	return &GilbertElliott{P: min(lossRate*r/(1-lossRate), 1), R: r, LossBad: 1}
+}
+
+// Lost implements LossModel
+func (g *GilbertElliott) Lost(rng *rand.Rand) bool {
+	if g.bad {
+		g.bad = rng.Float64() >= g.R
+	} else {
+		g.bad = rng.Float64() < g.P
+	}
+	if g.bad {
+		return rng.Float64() < g.LossBad
+	}
+	return rng.Float64() < g.LossGood
+}
+
+// LossRate returns the mean loss rate of the model in its steady state
+func (g *GilbertElliott) LossRate() float64 {
+	if g.P+g.R == 0 {
+		return g.LossGood
+	}
+	return (g.R*g.LossGood + g.P*g.LossBad) / (g.P + g.R)
+}
+
+// Config describes the impairments of a Channel
+type Config struct {
+	Loss LossModel // nil for no loss
+
+	// Delay is the fixed one-way delay. Each packet is further delayed by
+	// the absolute value of a normal variable of deviation Jitter, but does
+	// not overtake the packets sent before it unless reordered.
+	Delay  time.Duration
+	Jitter time.Duration
+
+	Reorder      float64       // probability a packet is held back by ReorderDelay, letting later ones overtake it
+	ReorderDelay time.Duration // extra delay of reordered packets
+	Duplicate    float64       // probability a packet also arrives a second time, with its own jitter
+
+	Seed uint64 // seed of the random generator
+}
+
+// Packet is a packet delivered by a Channel
+type Packet struct {
+	Data      []byte
+	Index     int           // position in the order sent, from 0
+	Sent      time.Duration // time sent since the start of the simulation
+	Arrival   time.Duration // time received since the start of the simulation
+	Duplicate bool          // second copy of the packet
+}
+
+// Stats counts what a Channel did to the packets sent through it
+type Stats struct {
+	Sent       int // packets sent
+	Lost       int // packets lost
+	Reordered  int // packets held back for reordering
+	Duplicated int // packets delivered twice
+	Delivered  int // packets received, duplicates included
+}
+
+// Channel is a simulated one-way network path. The random draws for a
+// packet do not depend on its contents, so the same seed gives the same
+// impairments to streams of the same length, for instance with in-band FEC
+// on and off.
+type Channel struct {
+	config      Config
+	rng         *rand.Rand
+	pending     []Packet // in flight, by arrival time
+	sent        int
+	lastArrival time.Duration // arrival of the last packet not reordered
+	stats       Stats
+}
+
+// NewChannel creates a channel impairing packets as config describes
+func NewChannel(config Config) *Channel {
+	return &Channel{
+		config: config,
+		rng:    rand.New(rand.NewPCG(config.Seed, config.Seed^0x9e3779b97f4a7c15)),
+	}
+}
+
+// Send sends a packet at time at since the start of the simulation, which
+// must not be earlier than that of the previous packet. data is copied.
+func (c *Channel) Send(data []byte, at time.Duration) {
+	index := c.sent
+	c.sent++
+	c.stats.Sent++
+
+	// The same draws for every packet, whether lost or not
+	lost := c.config.Loss != nil && c.config.Loss.Lost(c.rng)
+	jitter := c.jitter()
+	reorder := c.rng.Float64() < c.config.Reorder
+	duplicate := c.rng.Float64() < c.config.Duplicate
+	duplicateJitter := c.jitter()
+	if lost {
+		c.stats.Lost++
+		return
+	}
+
+	arrival := at + c.config.Delay + jitter
+	if reorder {
+		arrival += c.config.ReorderDelay
+		c.stats.Reordered++
+	} else {
+		arrival = max(arrival, c.lastArrival)
+		c.lastArrival = arrival
+	}
+	packet := Packet{Data: append([]byte(nil), data...), Index: index, Sent: at, Arrival: arrival}
+	c.deliver(packet)
+	if duplicate {
+		packet.Arrival = max(at+c.config.Delay+duplicateJitter, arrival)
+		packet.Duplicate = true
+		c.deliver(packet)
+		c.stats.Duplicated++
+	}
+}
+
+// jitter draws the extra delay of a packet
+func (c *Channel) jitter() time.Duration {
+	return time.Duration(math.Abs(c.rng.NormFloat64()) * float64(c.config.Jitter))
+}
+
+// deliver queues packet for its arrival, after those arriving at the same
+// time
+func (c *Channel) deliver(packet Packet) {
+	i := len(c.pending)
+	for i > 0 && c.pending[i-1].Arrival > packet.Arrival {
+		i--
+	}
+	c.pending = append(c.pending, Packet{})
+	copy(c.pending[i+1:], c.pending[i:])
+	c.pending[i] = packet
+}
+
+// Receive returns the packets arriving up to time at since the start of the
+// simulation, in arrival order
+func (c *Channel) Receive(at time.Duration) []Packet {
+	n := 0
+	for n < len(c.pending) && c.pending[n].Arrival <= at {
+		n++
+	}
+	packets := append([]Packet(nil), c.pending[:n]...)
+	c.pending = c.pending[:copy(c.pending, c.pending[n:])]
+	c.stats.Delivered += n
+	return packets
+}
+
+// Flush returns all packets still in flight, in arrival order
+func (c *Channel) Flush() []Packet {
+	return c.Receive(math.MaxInt64)
+}
+
+// Stats returns the counters
+func (c *Channel) Stats() Stats {
+	return c.stats
+}
@@ -0,0 +1,353 @@
+package netsim_test
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/justa-cai/go-libopus/netsim"
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+const (
+	sampleRate = 16000
+	frameSize  = 320 // 20ms at 16kHz
+	frameTime  = 20 * time.Millisecond
+)
+
+// transmit sends n packets holding their index every 20ms and returns all
+// deliveries
+func transmit(channel *netsim.Channel, n int) []netsim.Packet {
+	for i := 0; i < n; i++ {
+		channel.Send([]byte{byte(i >> 8), byte(i)}, time.Duration(i)*frameTime)
+	}
+	return channel.Flush()
+}
+
+func TestDeterministic(t *testing.T) {
+	config := netsim.Config{
+		Loss:         netsim.Bernoulli{P: 0.1},
+		Delay:        50 * time.Millisecond,
+		Jitter:       10 * time.Millisecond,
+		Reorder:      0.05,
+		ReorderDelay: 50 * time.Millisecond,
+		Duplicate:    0.05,
+		Seed:         1,
+	}
+	first := transmit(netsim.NewChannel(config), 1000)
+	second := transmit(netsim.NewChannel(config), 1000)
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Expected the same deliveries for the same seed")
+	}
+	config.Seed = 2
+	if reflect.DeepEqual(first, transmit(netsim.NewChannel(config), 1000)) {
+		t.Error("Expected different deliveries for another seed")
+	}
+}
+
+func TestBernoulli(t *testing.T) {
+	channel := netsim.NewChannel(netsim.Config{Loss: netsim.Bernoulli{P: 0.1}, Seed: 1})
+	received := transmit(channel, 20000)
+	stats := channel.Stats()
+	if rate := float64(stats.Lost) / float64(stats.Sent); rate < 0.09 || rate > 0.11 {
+		t.Errorf("Expected loss rate near 0.1, got %.3f", rate)
+	}
+	if len(received) != stats.Sent-stats.Lost || stats.Delivered != len(received) {
+		t.Errorf("Unexpected stats %+v for %d packets received", stats, len(received))
+	}
+}
+
+func TestGilbertElliott(t *testing.T) {
+	model := netsim.NewGilbertElliott(0.1, 3)
+	if rate := model.LossRate(); math.Abs(rate-0.1) > 1e-9 {
+		t.Errorf("Expected model loss rate 0.1, got %v", rate)
+	}
+	received := transmit(netsim.NewChannel(netsim.Config{Loss: model, Seed: 1}), 50000)
+
+	// Measure the loss rate and the mean length of the runs of losses
+	lost, bursts := 0, 0
+	next := 0
+	for _, packet := range append(received, netsim.Packet{Index: 50000}) {
+		if gap := packet.Index - next; gap > 0 {
+			lost += gap
+			bursts++
+		}
+		next = packet.Index + 1
+	}
+	if rate := float64(lost) / 50000; rate < 0.09 || rate > 0.11 {
+		t.Errorf("Expected loss rate near 0.1, got %.3f", rate)
+	}
+	if burst := float64(lost) / float64(bursts); burst < 2.7 || burst > 3.3 {
+		t.Errorf("Expected mean burst length near 3, got %.2f", burst)
+	}
+
+	// Out of range arguments are clamped to valid probabilities
+	for _, test := range []struct {
+		lossRate, burstLength, expected float64
+	}{
+		{1, 3, 1},
+		{2, 0, 1},
+		{-0.5, 3, 0},
+		{0, 0.5, 0},
+		{0.9, 1, 0.9}, // the bursts lengthen to 9 packets
+	} {
+		model := netsim.NewGilbertElliott(test.lossRate, test.burstLength)
+		for _, p := range []float64{model.P, model.R} {
+			if p < 0 || p > 1 || math.IsNaN(p) {
+				t.Errorf("NewGilbertElliott(%v, %v): invalid probabilities %+v", test.lossRate, test.burstLength, model)
+			}
+		}
+		if rate := model.LossRate(); math.Abs(rate-test.expected) > 1e-9 {
+			t.Errorf("NewGilbertElliott(%v, %v): expected loss rate %v, got %v", test.lossRate, test.burstLength, test.expected, rate)
+		}
+	}
+	if received := transmit(netsim.NewChannel(netsim.Config{Loss: netsim.NewGilbertElliott(1, 3), Seed: 1}), 100); len(received) != 0 {
+		t.Errorf("Expected every packet lost at loss rate 1, got %d", len(received))
+	}
+}
+
+func TestDelay(t *testing.T) {
+	// Jitter alone keeps the order
+	channel := netsim.NewChannel(netsim.Config{Delay: 40 * time.Millisecond, Jitter: 30 * time.Millisecond, Seed: 1})
+	var jittered bool
+	for i, packet := range transmit(channel, 1000) {
+		if packet.Index != i {
+			t.Fatalf("Expected packet %d, got %d", i, packet.Index)
+		}
+		if packet.Arrival < packet.Sent+40*time.Millisecond {
+			t.Fatalf("Packet %d arrived before the fixed delay", i)
+		}
+		jittered = jittered || packet.Arrival > packet.Sent+60*time.Millisecond
+	}
+	if !jittered {
+		t.Error("Expected some packets delayed by jitter")
+	}
+
+	// Packets arrive by time
+	channel = netsim.NewChannel(netsim.Config{Delay: 40 * time.Millisecond})
+	channel.Send([]byte{0}, 0)
+	channel.Send([]byte{1}, frameTime)
+	if packets := channel.Receive(50 * time.Millisecond); len(packets) != 1 || packets[0].Data[0] != 0 {
+		t.Errorf("Expected the first packet only, got %v", packets)
+	}
+	if packets := channel.Receive(60 * time.Millisecond); len(packets) != 1 || packets[0].Arrival != 60*time.Millisecond {
+		t.Errorf("Expected the second packet at 60ms, got %v", packets)
+	}
+
+	// Reordered and duplicated packets
+	channel = netsim.NewChannel(netsim.Config{Reorder: 0.1, ReorderDelay: 50 * time.Millisecond, Duplicate: 0.1, Seed: 1})
+	received := transmit(channel, 1000)
+	inversions, duplicates := 0, 0
+	for i, packet := range received {
+		if packet.Duplicate {
+			duplicates++
+		}
+		if i > 0 && packet.Index < received[i-1].Index {
+			inversions++
+		}
+	}
+	stats := channel.Stats()
+	if inversions == 0 || inversions > stats.Reordered+stats.Duplicated {
+		t.Errorf("Unexpected %d inversions for stats %+v", inversions, stats)
+	}
+	if duplicates != stats.Duplicated || len(received) != 1000+duplicates || stats.Duplicated < 70 || stats.Duplicated > 130 {
+		t.Errorf("Unexpected %d duplicates for stats %+v", duplicates, stats)
+	}
+}
+
+// speechFrame generates frame i of a speech-like signal: a harmonic tone
+// with a gliding pitch and formant-like spectrum, in syllables of 250ms
+func speechFrame(i int) []byte {
+	data := make([]byte, frameSize*2)
+	for j := 0; j < frameSize; j++ {
+		n := float64(i*frameSize + j)
+		seconds := n / sampleRate
+		f0 := 150 + 50*math.Sin(2*math.Pi*0.7*seconds)
+		envelope := math.Pow(math.Sin(math.Pi*math.Mod(seconds*4, 1)), 2)
+		sample := 0.0
+		for h := 1; h*180 < 3500; h++ {
+			// Phase of the harmonic, integrating the pitch glide
+			phase := 2 * math.Pi * float64(h) * (150*seconds - 50/(2*math.Pi*0.7)*math.Cos(2*math.Pi*0.7*seconds))
+			weight := 1/(1+math.Pow((float64(h)*f0-500)/300, 2)) + 0.5/(1+math.Pow((float64(h)*f0-1500)/400, 2))
+			sample += weight * math.Sin(phase)
+		}
+		binary.LittleEndian.PutUint16(data[j*2:], uint16(int16(4000*envelope*sample)))
+	}
+	return data
+}
+
+// quality encodes 10s of speech-like audio, sends it through a channel with
+// loss and a fixed 100ms playout delay and decodes what arrives in time,
+// recovering missing frames by FEC, DRED or PLC. It returns the segmental
+// SNR in dB and the fraction of frames missing.
+func quality(t *testing.T, loss netsim.LossModel, fec, dred bool) (float64, float64) {
+	const frames = 500
+	encoder, err := opus.NewEncoder(sampleRate, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	if err := encoder.SetBitrate(24000); err != nil {
+		t.Fatalf("Failed to set bitrate: %v", err)
+	}
+	if err := encoder.CtlInt(opus.OPUS_SET_INBAND_FEC_REQUEST, boolInt(fec)); err != nil {
+		t.Fatalf("Failed to set FEC: %v", err)
+	}
+	if err := encoder.CtlInt(opus.OPUS_SET_PACKET_LOSS_PERC_REQUEST, 20); err != nil {
+		t.Fatalf("Failed to set expected loss: %v", err)
+	}
+	var dredDecoder *opus.OpusDREDDecoder
+	if dred {
+		if err := encoder.SetDREDDuration(20); err != nil {
+			t.Skipf("DRED unavailable: %v", err)
+		}
+		if dredDecoder, err = opus.NewDREDDecoder(); err != nil {
+			t.Skipf("DRED unavailable: %v", err)
+		}
+		defer dredDecoder.Close()
+	}
+	lookahead, err := encoder.CtlGetInt(opus.OPUS_GET_LOOKAHEAD_REQUEST)
+	if err != nil {
+		t.Fatalf("Failed to get lookahead: %v", err)
+	}
+
+	channel := netsim.NewChannel(netsim.Config{Loss: loss, Delay: 30 * time.Millisecond, Jitter: 20 * time.Millisecond, Seed: 1})
+	var original []byte
+	buf := make([]byte, 1500)
+	for i := 0; i < frames; i++ {
+		frame := speechFrame(i)
+		original = append(original, frame...)
+		n, err := encoder.Encode(frame, buf)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		channel.Send(buf[:n], time.Duration(i)*frameTime)
+	}
+	received := make([][]byte, frames)
+	for _, packet := range channel.Flush() {
+		if packet.Arrival <= packet.Sent+100*time.Millisecond && received[packet.Index] == nil {
+			received[packet.Index] = packet.Data
+		}
+	}
+
+	decoder, err := opus.NewDecoder(sampleRate, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+	var decoded []byte
+	missing := 0
+	out := make([]byte, frameSize*2)
+	for i, packet := range received {
+		if packet != nil {
+			if _, err := decoder.Decode(packet, out); err != nil {
+				t.Fatalf("Failed to decode frame %d: %v", i, err)
+			}
+			decoded = append(decoded, out...)
+			continue
+		}
+		missing++
+		// The next packet received, which may carry FEC or DRED data
+		next := i + 1
+		for next < frames && received[next] == nil {
+			next++
+		}
+		lbrr := false
+		if fec && next == i+1 {
+			lbrr, _ = opus.PacketHasLBRR(received[next])
+		}
+		switch {
+		case lbrr:
+			_, err = decoder.DecodeFEC(received[next], out, frameSize)
+		case dred && next < frames && recoverable(t, dredDecoder, received[next], (next-i)*frameSize):
+			_, err = decoder.DecodeDRED(dredDecoder, (next-i)*frameSize, out, frameSize)
+		default:
+			_, err = decoder.DecodePLC(out, frameSize)
+		}
+		if err != nil {
+			t.Fatalf("Failed to conceal frame %d: %v", i, err)
+		}
+		decoded = append(decoded, out...)
+	}
+	return segmentalSNR(original, decoded[lookahead*2:]), float64(missing) / frames
+}
+
+// recoverable parses the DRED data of packet and reports whether it reaches
+// offset samples back
+func recoverable(t *testing.T, decoder *opus.OpusDREDDecoder, packet []byte, offset int) bool {
+	available, err := decoder.Parse(packet, offset, sampleRate)
+	if err != nil {
+		t.Fatalf("Failed to parse DRED: %v", err)
+	}
+	return available >= offset
+}
+
+// boolInt returns 1 for true and 0 for false
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// segmentalSNR returns the mean SNR over 20ms segments of decoded against
+// original, each clamped to [-10, 35] dB, skipping silent segments
+func segmentalSNR(original, decoded []byte) float64 {
+	total, segments := 0.0, 0
+	for start := 0; start+frameSize*2 <= min(len(original), len(decoded)); start += frameSize * 2 {
+		signal, noise := 0.0, 0.0
+		for j := start; j < start+frameSize*2; j += 2 {
+			s := float64(int16(binary.LittleEndian.Uint16(original[j:])))
+			d := float64(int16(binary.LittleEndian.Uint16(decoded[j:])))
+			signal += s * s
+			noise += (s - d) * (s - d)
+		}
+		if signal < frameSize*100*100 {
+			continue
+		}
+		total += min(max(10*math.Log10(signal/max(noise, 1)), -10), 35)
+		segments++
+	}
+	return total / float64(segments)
+}
+
+func TestLossResilience(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping loss resilience report in short mode")
+	}
+	models := []struct {
+		name string
+		loss func() netsim.LossModel
+	}{
+		{"none", func() netsim.LossModel { return nil }},
+		{"bernoulli 5%", func() netsim.LossModel { return netsim.Bernoulli{P: 0.05} }},
+		{"bernoulli 10%", func() netsim.LossModel { return netsim.Bernoulli{P: 0.1} }},
+		{"bernoulli 20%", func() netsim.LossModel { return netsim.Bernoulli{P: 0.2} }},
+		{"gilbert 10% burst 3", func() netsim.LossModel { return netsim.NewGilbertElliott(0.1, 3) }},
+	}
+
+	for _, dred := range []bool{false, true} {
+		t.Run(map[bool]string{false: "DRED off", true: "DRED on"}[dred], func(t *testing.T) {
+			previous := math.Inf(1)
+			for _, model := range models {
+				withoutFEC, missing := quality(t, model.loss(), false, dred)
+				withFEC, _ := quality(t, model.loss(), true, dred)
+				t.Logf("%-20s missing %4.1f%%  SNR %5.2f dB without FEC, %5.2f dB with FEC",
+					model.name, 100*missing, withoutFEC, withFEC)
+
+				// Quality drops with loss, and FEC recovers some of it
+				if model.name != "gilbert 10% burst 3" {
+					if withoutFEC > previous+0.5 {
+						t.Errorf("%s: expected lower SNR than with less loss, got %.2f dB after %.2f dB", model.name, withoutFEC, previous)
+					}
+					previous = withoutFEC
+				}
+				if missing > 0.05 && withFEC < withoutFEC-0.5 {
+					t.Errorf("%s: expected FEC not to lower the SNR, got %.2f dB against %.2f dB", model.name, withFEC, withoutFEC)
+				}
+			}
+		})
+	}
+}
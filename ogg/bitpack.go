@@ -0,0 +1,17 @@
+package ogg
+
+import "errors"
+
+// BitOrder selects how BitWriter and BitReader pack bits into bytes
+type BitOrder int
+
+const (
+	// LSBFirst fills each byte from its least significant bit, as used by
+	// Vorbis (oggpack_*)
+	LSBFirst BitOrder = iota
+	// MSBFirst fills each byte from its most significant bit, as used by
+	// Theora (oggpackB_*)
+	MSBFirst
+)
+
+var errBitCount = errors.New("invalid bit count")
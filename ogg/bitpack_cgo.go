@@ -0,0 +1,218 @@
+//go:build cgo && !purego
+
+package ogg
+
+// #include <ogg/ogg.h>
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+import (
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// BitWriter packs values of up to 32 bits into a byte buffer owned by
+// libogg, for building codec headers
+type BitWriter struct {
+	buf   C.oggpack_buffer
+	order BitOrder
+}
+
+// NewBitWriter creates a bit writer using the given bit order
+func NewBitWriter(order BitOrder) (*BitWriter, error) {
+	if err := load(); err != nil {
+		return nil, err
+	}
+	w := &BitWriter{order: order}
+	if order == MSBFirst {
+		C.oggpackB_writeinit(&w.buf)
+	} else {
+		C.oggpack_writeinit(&w.buf)
+	}
+	if err := w.check(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// check returns an error if the writer was closed or failed an allocation
+func (w *BitWriter) check() error {
+	if C.oggpack_writecheck(&w.buf) != 0 {
+		return errors.New("bit writer is not ready")
+	}
+	return nil
+}
+
+// Write packs the low bits bits of value; bits must be between 0 and 32
+func (w *BitWriter) Write(value uint32, bits int) error {
+	if bits < 0 || bits > 32 {
+		return errBitCount
+	}
+	if w.order == MSBFirst {
+		C.oggpackB_write(&w.buf, C.ulong(value), C.int(bits))
+	} else {
+		C.oggpack_write(&w.buf, C.ulong(value), C.int(bits))
+	}
+	return w.check()
+}
+
+// WriteBytes packs data, each byte as an 8 bit value
+func (w *BitWriter) WriteBytes(data []byte) error {
+	if len(data) == 0 {
+		return w.check()
+	}
+	if w.order == MSBFirst {
+		C.oggpackB_writecopy(&w.buf, unsafe.Pointer(&data[0]), C.long(len(data)*8))
+	} else {
+		C.oggpack_writecopy(&w.buf, unsafe.Pointer(&data[0]), C.long(len(data)*8))
+	}
+	return w.check()
+}
+
+// Align pads with zero bits up to the next byte boundary
+func (w *BitWriter) Align() error {
+	if w.order == MSBFirst {
+		C.oggpackB_writealign(&w.buf)
+	} else {
+		C.oggpack_writealign(&w.buf)
+	}
+	return w.check()
+}
+
+// Truncate discards everything after the first bits bits
+func (w *BitWriter) Truncate(bits int) error {
+	if bits < 0 || bits > w.Bits() {
+		return errBitCount
+	}
+	if w.order == MSBFirst {
+		C.oggpackB_writetrunc(&w.buf, C.long(bits))
+	} else {
+		C.oggpack_writetrunc(&w.buf, C.long(bits))
+	}
+	return w.check()
+}
+
+// Reset discards all written bits
+func (w *BitWriter) Reset() {
+	C.oggpack_reset(&w.buf)
+}
+
+// Bits returns the number of bits written
+func (w *BitWriter) Bits() int {
+	return int(C.oggpack_bits(&w.buf))
+}
+
+// Bytes returns a copy of the packed data, padded with zero bits to a whole byte
+func (w *BitWriter) Bytes() []byte {
+	buffer := C.oggpack_get_buffer(&w.buf)
+	if buffer == nil {
+		return nil
+	}
+	return C.GoBytes(unsafe.Pointer(buffer), C.int(C.oggpack_bytes(&w.buf)))
+}
+
+// Close releases the buffer
+func (w *BitWriter) Close() {
+	C.oggpack_writeclear(&w.buf)
+}
+
+// BitReader unpacks values of up to 32 bits from a copy of a byte buffer
+type BitReader struct {
+	buf   C.oggpack_buffer
+	data  unsafe.Pointer // C copy of the input
+	order BitOrder
+}
+
+// NewBitReader creates a bit reader over data using the given bit order.
+// The data is copied, so the caller may reuse it.
+func NewBitReader(data []byte, order BitOrder) (*BitReader, error) {
+	if err := load(); err != nil {
+		return nil, err
+	}
+	// At least one byte so the buffer pointer is never nil
+	cData := C.malloc(C.size_t(len(data) + 1))
+	if cData == nil {
+		return nil, errors.New("failed to allocate memory for bit reader")
+	}
+	if len(data) > 0 {
+		C.memcpy(cData, unsafe.Pointer(&data[0]), C.size_t(len(data)))
+	}
+	r := &BitReader{data: cData, order: order}
+	if order == MSBFirst {
+		C.oggpackB_readinit(&r.buf, (*C.uchar)(cData), C.int(len(data)))
+	} else {
+		C.oggpack_readinit(&r.buf, (*C.uchar)(cData), C.int(len(data)))
+	}
+	return r, nil
+}
+
+// read reads up to 31 bits, returning -1 past the end
+func (r *BitReader) read(bits int) int64 {
+	if r.order == MSBFirst {
+		return int64(C.oggpackB_read(&r.buf, C.int(bits)))
+	}
+	return int64(C.oggpack_read(&r.buf, C.int(bits)))
+}
+
+// Read consumes and returns the next bits bits. Reading past the end returns
+// io.ErrUnexpectedEOF for this and all later reads.
+func (r *BitReader) Read(bits int) (uint32, error) {
+	if bits < 0 || bits > 32 {
+		return 0, errBitCount
+	}
+	if bits < 32 {
+		ret := r.read(bits)
+		if ret < 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return uint32(ret), nil
+	}
+
+	// A C long may not hold 32 bits without ambiguity, read two halves
+	saved := r.buf
+	first, second := r.read(16), r.read(16)
+	if first < 0 || second < 0 {
+		r.buf = saved
+		r.read(32)
+		return 0, io.ErrUnexpectedEOF
+	}
+	if r.order == MSBFirst {
+		return uint32(first)<<16 | uint32(second), nil
+	}
+	return uint32(first) | uint32(second)<<16, nil
+}
+
+// Look returns the next bits bits without consuming them
+func (r *BitReader) Look(bits int) (uint32, error) {
+	saved := r.buf
+	value, err := r.Read(bits)
+	r.buf = saved
+	return value, err
+}
+
+// Skip consumes bits bits. Skipping past the end fails all later reads.
+func (r *BitReader) Skip(bits int) error {
+	if bits < 0 {
+		return errBitCount
+	}
+	C.oggpack_adv(&r.buf, C.int(bits))
+	if r.buf.ptr == nil {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// Bits returns the number of bits consumed
+func (r *BitReader) Bits() int {
+	return int(C.oggpack_bits(&r.buf))
+}
+
+// Close releases the copy of the input
+func (r *BitReader) Close() {
+	if r.data != nil {
+		C.free(r.data)
+		r.data = nil
+		r.buf = C.oggpack_buffer{}
+	}
+}
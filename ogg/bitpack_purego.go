@@ -0,0 +1,157 @@
+//go:build !cgo || purego
+
+package ogg
+
+import (
+	"errors"
+	"io"
+
+	"github.com/justa-cai/go-libopus/ogg/internal/bitwise"
+)
+
+// BitWriter packs values of up to 32 bits into a byte buffer, for building
+// codec headers
+type BitWriter struct {
+	w *bitwise.Writer
+}
+
+// NewBitWriter creates a bit writer using the given bit order
+func NewBitWriter(order BitOrder) (*BitWriter, error) {
+	return &BitWriter{w: bitwise.NewWriter(order == MSBFirst)}, nil
+}
+
+// check returns an error if the writer was closed
+func (w *BitWriter) check() error {
+	if w.w == nil {
+		return errors.New("bit writer is not ready")
+	}
+	return nil
+}
+
+// Write packs the low bits bits of value; bits must be between 0 and 32
+func (w *BitWriter) Write(value uint32, bits int) error {
+	if bits < 0 || bits > 32 {
+		return errBitCount
+	}
+	if err := w.check(); err != nil {
+		return err
+	}
+	w.w.Write(value, bits)
+	return nil
+}
+
+// WriteBytes packs data, each byte as an 8 bit value
+func (w *BitWriter) WriteBytes(data []byte) error {
+	if err := w.check(); err != nil {
+		return err
+	}
+	w.w.WriteBytes(data)
+	return nil
+}
+
+// Align pads with zero bits up to the next byte boundary
+func (w *BitWriter) Align() error {
+	if err := w.check(); err != nil {
+		return err
+	}
+	w.w.Align()
+	return nil
+}
+
+// Truncate discards everything after the first bits bits
+func (w *BitWriter) Truncate(bits int) error {
+	if bits < 0 || bits > w.Bits() {
+		return errBitCount
+	}
+	if err := w.check(); err != nil {
+		return err
+	}
+	w.w.Truncate(bits)
+	return nil
+}
+
+// Reset discards all written bits
+func (w *BitWriter) Reset() {
+	if w.w != nil {
+		w.w.Reset()
+	}
+}
+
+// Bits returns the number of bits written
+func (w *BitWriter) Bits() int {
+	if w.w == nil {
+		return 0
+	}
+	return w.w.Bits()
+}
+
+// Bytes returns a copy of the packed data, padded with zero bits to a whole byte
+func (w *BitWriter) Bytes() []byte {
+	if w.w == nil {
+		return nil
+	}
+	return append([]byte(nil), w.w.Bytes()...)
+}
+
+// Close releases the buffer
+func (w *BitWriter) Close() {
+	w.w = nil
+}
+
+// BitReader unpacks values of up to 32 bits from a copy of a byte buffer
+type BitReader struct {
+	r *bitwise.Reader
+}
+
+// NewBitReader creates a bit reader over data using the given bit order.
+// The data is copied, so the caller may reuse it.
+func NewBitReader(data []byte, order BitOrder) (*BitReader, error) {
+	data = append([]byte(nil), data...)
+	return &BitReader{r: bitwise.NewReader(data, order == MSBFirst)}, nil
+}
+
+// Read consumes and returns the next bits bits. Reading past the end returns
+// io.ErrUnexpectedEOF for this and all later reads.
+func (r *BitReader) Read(bits int) (uint32, error) {
+	if bits < 0 || bits > 32 {
+		return 0, errBitCount
+	}
+	value, ok := r.r.Read(bits)
+	if !ok {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return value, nil
+}
+
+// Look returns the next bits bits without consuming them
+func (r *BitReader) Look(bits int) (uint32, error) {
+	if bits < 0 || bits > 32 {
+		return 0, errBitCount
+	}
+	value, ok := r.r.Look(bits)
+	if !ok {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return value, nil
+}
+
+// Skip consumes bits bits. Skipping past the end fails all later reads.
+func (r *BitReader) Skip(bits int) error {
+	if bits < 0 {
+		return errBitCount
+	}
+	if !r.r.Skip(bits) {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// Bits returns the number of bits consumed
+func (r *BitReader) Bits() int {
+	return r.r.Bits()
+}
+
+// Close releases the copy of the input
+func (r *BitReader) Close() {
+	r.r = bitwise.NewReader(nil, false)
+}
@@ -0,0 +1,84 @@
+//go:build cgo && !purego
+
+package ogg_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/ogg"
+	"github.com/justa-cai/go-libopus/ogg/internal/bitwise"
+)
+
+func TestBitwiseMatchesLibogg(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for run := 0; run < 200; run++ {
+		order := ogg.BitOrder(run % 2)
+		writer, err := ogg.NewBitWriter(order)
+		if err != nil {
+			t.Fatalf("Failed to create bit writer: %v", err)
+		}
+		pure := bitwise.NewWriter(order == ogg.MSBFirst)
+
+		for i := 0; i < rng.Intn(400); i++ {
+			switch op := rng.Intn(20); {
+			case op == 0:
+				writer.Align()
+				pure.Align()
+			case op == 1:
+				data := make([]byte, rng.Intn(300))
+				rng.Read(data)
+				writer.WriteBytes(data)
+				pure.WriteBytes(data)
+			case op == 2 && writer.Bits() > 0:
+				bits := rng.Intn(writer.Bits() + 1)
+				writer.Truncate(bits)
+				pure.Truncate(bits)
+			default:
+				bits := rng.Intn(33)
+				value := rng.Uint32()
+				writer.Write(value, bits)
+				pure.Write(value, bits)
+			}
+			if writer.Bits() != pure.Bits() {
+				t.Fatalf("Run %d op %d: libogg wrote %d bits, bitwise %d", run, i, writer.Bits(), pure.Bits())
+			}
+		}
+		data := writer.Bytes()
+		if !bytes.Equal(data, pure.Bytes()) {
+			t.Fatalf("Run %d: packed data differs\nlibogg  %x\nbitwise %x", run, data, pure.Bytes())
+		}
+		writer.Close()
+
+		// Read back with random field sizes until both run past the end
+		reader, err := ogg.NewBitReader(data, order)
+		if err != nil {
+			t.Fatalf("Failed to create bit reader: %v", err)
+		}
+		pureReader := bitwise.NewReader(data, order == ogg.MSBFirst)
+		for i := 0; ; i++ {
+			bits := rng.Intn(33)
+			var value, pureValue uint32
+			var ok bool
+			if rng.Intn(8) == 0 {
+				value, err = reader.Look(bits)
+				pureValue, ok = pureReader.Look(bits)
+			} else if rng.Intn(8) == 0 {
+				err = reader.Skip(bits)
+				ok = pureReader.Skip(bits)
+			} else {
+				value, err = reader.Read(bits)
+				pureValue, ok = pureReader.Read(bits)
+			}
+			if (err == nil) != ok || value != pureValue || reader.Bits() != pureReader.Bits() {
+				t.Fatalf("Run %d read %d of %d bits: libogg %x %v at %d, bitwise %x %v at %d",
+					run, i, bits, value, err, reader.Bits(), pureValue, ok, pureReader.Bits())
+			}
+			if err != nil {
+				break
+			}
+		}
+		reader.Close()
+	}
+}
@@ -0,0 +1,171 @@
+package ogg
+
+import (
+	"io"
+	"slices"
+)
+
+// DemuxEventType identifies the kind of a DemuxEvent
+type DemuxEventType int
+
+const (
+	// StreamBegin reports a beginning of stream page for a new logical stream
+	StreamBegin DemuxEventType = iota
+	// StreamPacket carries a packet of a subscribed logical stream
+	StreamPacket
+	// StreamEnd reports the end of stream page of a logical stream
+	StreamEnd
+	// StreamGap reports packets of a subscribed logical stream lost before
+	// the next StreamPacket
+	StreamGap
+)
+
+// DemuxEvent is returned by Demuxer.Next
+type DemuxEvent struct {
+	Type   DemuxEventType
+	Serial int        // Serial number of the logical stream
+	Packet *OggPacket // The packet for StreamPacket, the first packet for StreamBegin, nil otherwise
+}
+
+// Demuxer splits a physical Ogg bitstream into its logical streams. It
+// handles chained streams, where a new stream begins after the previous one
+// ended, as well as multiplexed streams whose pages are interleaved.
+type Demuxer struct {
+	pages      *PageReader
+	streams    map[int]*OggStreamState
+	order      []int // serial numbers of the streams, in the order they began
+	subscribed map[int]bool
+	pending    []*DemuxEvent
+}
+
+// NewDemuxer creates a demuxer reading pages from r
+func NewDemuxer(r io.Reader) (*Demuxer, error) {
+	pages, err := NewPageReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Demuxer{
+		pages:      pages,
+		streams:    make(map[int]*OggStreamState),
+		subscribed: make(map[int]bool),
+	}, nil
+}
+
+// Subscribe selects a logical stream whose packets are returned by Next.
+// When no stream is subscribed, packets of all streams are returned.
+// StreamBegin and StreamEnd events are always returned.
+func (d *Demuxer) Subscribe(serial int) {
+	d.subscribed[serial] = true
+}
+
+// Unsubscribe stops returning packets of a logical stream
+func (d *Demuxer) Unsubscribe(serial int) {
+	delete(d.subscribed, serial)
+}
+
+// SetSyncHandler sets a function called with the bytes skipped while
+// looking for the next page
+func (d *Demuxer) SetSyncHandler(handler func(err *SyncError)) {
+	d.pages.SetSyncHandler(handler)
+}
+
+// Streams returns the serial numbers of the logical streams that have begun
+// but not yet ended, in the order of their beginning of stream pages
+func (d *Demuxer) Streams() []int {
+	return append([]int(nil), d.order...)
+}
+
+// removeStream forgets the order of the stream with serial
+func (d *Demuxer) removeStream(serial int) {
+	if i := slices.Index(d.order, serial); i >= 0 {
+		d.order = slices.Delete(d.order, i, i+1)
+	}
+}
+
+// Next returns the next event, or io.EOF when the input is exhausted
+func (d *Demuxer) Next() (*DemuxEvent, error) {
+	for {
+		for len(d.pending) > 0 {
+			ev := d.pending[0]
+			d.pending = d.pending[1:]
+			if (ev.Type == StreamPacket || ev.Type == StreamGap) && len(d.subscribed) > 0 && !d.subscribed[ev.Serial] {
+				continue
+			}
+			return ev, nil
+		}
+		if err := d.readPage(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readPage reads the next page and queues the events it produces
+func (d *Demuxer) readPage() error {
+	page, err := d.pages.Next()
+	if err != nil {
+		return err
+	}
+
+	serial := page.Serialno()
+	stream := d.streams[serial]
+	var begin *DemuxEvent
+	if page.BOS() {
+		// A serial number may be reused by a later link of a chained stream
+		if stream != nil {
+			stream.Clear()
+			d.removeStream(serial)
+		}
+		stream, err = NewOggStreamState(serial)
+		if err != nil {
+			return err
+		}
+		d.streams[serial] = stream
+		d.order = append(d.order, serial)
+		begin = &DemuxEvent{Type: StreamBegin, Serial: serial}
+		d.pending = append(d.pending, begin)
+	}
+	if stream == nil {
+		// The beginning of this stream was not seen
+		return nil
+	}
+
+	if err := stream.PageIn(page); err != nil {
+		return err
+	}
+	for {
+		packet := &OggPacket{}
+		ret, err := stream.PacketOut(packet)
+		if err == ErrGap {
+			d.pending = append(d.pending, &DemuxEvent{Type: StreamGap, Serial: serial})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if ret == 0 {
+			break
+		}
+		if begin != nil && begin.Packet == nil {
+			begin.Packet = packet
+		}
+		d.pending = append(d.pending, &DemuxEvent{Type: StreamPacket, Serial: serial, Packet: packet})
+	}
+
+	if page.EOS() {
+		stream.Clear()
+		delete(d.streams, serial)
+		d.removeStream(serial)
+		d.pending = append(d.pending, &DemuxEvent{Type: StreamEnd, Serial: serial})
+	}
+	return nil
+}
+
+// Close releases the sync state and all stream states
+func (d *Demuxer) Close() error {
+	for serial, stream := range d.streams {
+		stream.Clear()
+		delete(d.streams, serial)
+	}
+	d.order = nil
+	return d.pages.Close()
+}
@@ -0,0 +1,107 @@
+//go:build cgo && !purego && dlopen
+
+// Stubs for the libogg functions used by the package, calling through
+// pointers resolved by go_ogg_dlopen
+
+#include <ogg/ogg.h>
+#include "dl.h"
+
+// OGG_FUNCS lists the functions returning a value as
+// F(return type, name, parameters, arguments)
+#define OGG_FUNCS(F) \
+    F(int, oggpack_writecheck, (oggpack_buffer *b), (b)) \
+    F(long, oggpack_read, (oggpack_buffer *b, int bits), (b, bits)) \
+    F(long, oggpack_bytes, (oggpack_buffer *b), (b)) \
+    F(long, oggpack_bits, (oggpack_buffer *b), (b)) \
+    F(unsigned char *, oggpack_get_buffer, (oggpack_buffer *b), (b)) \
+    F(long, oggpackB_read, (oggpack_buffer *b, int bits), (b, bits)) \
+    F(int, ogg_stream_packetin, (ogg_stream_state *os, ogg_packet *op), (os, op)) \
+    F(int, ogg_stream_pageout, (ogg_stream_state *os, ogg_page *og), (os, og)) \
+    F(int, ogg_stream_pageout_fill, (ogg_stream_state *os, ogg_page *og, int nfill), (os, og, nfill)) \
+    F(int, ogg_stream_flush, (ogg_stream_state *os, ogg_page *og), (os, og)) \
+    F(int, ogg_stream_flush_fill, (ogg_stream_state *os, ogg_page *og, int nfill), (os, og, nfill)) \
+    F(int, ogg_sync_init, (ogg_sync_state *oy), (oy)) \
+    F(int, ogg_sync_clear, (ogg_sync_state *oy), (oy)) \
+    F(int, ogg_sync_reset, (ogg_sync_state *oy), (oy)) \
+    F(char *, ogg_sync_buffer, (ogg_sync_state *oy, long size), (oy, size)) \
+    F(int, ogg_sync_wrote, (ogg_sync_state *oy, long bytes), (oy, bytes)) \
+    F(long, ogg_sync_pageseek, (ogg_sync_state *oy, ogg_page *og), (oy, og)) \
+    F(int, ogg_stream_pagein, (ogg_stream_state *os, ogg_page *og), (os, og)) \
+    F(int, ogg_stream_packetout, (ogg_stream_state *os, ogg_packet *op), (os, op)) \
+    F(int, ogg_stream_packetpeek, (ogg_stream_state *os, ogg_packet *op), (os, op)) \
+    F(int, ogg_stream_init, (ogg_stream_state *os, int serialno), (os, serialno)) \
+    F(int, ogg_stream_clear, (ogg_stream_state *os), (os)) \
+    F(int, ogg_stream_reset, (ogg_stream_state *os), (os)) \
+    F(int, ogg_stream_reset_serialno, (ogg_stream_state *os, int serialno), (os, serialno)) \
+    F(int, ogg_stream_check, (ogg_stream_state *os), (os)) \
+    F(int, ogg_stream_eos, (ogg_stream_state *os), (os)) \
+    F(int, ogg_page_version, (const ogg_page *og), (og)) \
+    F(int, ogg_page_continued, (const ogg_page *og), (og)) \
+    F(int, ogg_page_bos, (const ogg_page *og), (og)) \
+    F(int, ogg_page_eos, (const ogg_page *og), (og)) \
+    F(ogg_int64_t, ogg_page_granulepos, (const ogg_page *og), (og)) \
+    F(int, ogg_page_serialno, (const ogg_page *og), (og)) \
+    F(long, ogg_page_pageno, (const ogg_page *og), (og)) \
+    F(int, ogg_page_packets, (const ogg_page *og), (og))
+
+// OGG_VOID_FUNCS lists the functions returning nothing
+#define OGG_VOID_FUNCS(F) \
+    F(void, oggpack_writeinit, (oggpack_buffer *b), (b)) \
+    F(void, oggpack_writetrunc, (oggpack_buffer *b, long bits), (b, bits)) \
+    F(void, oggpack_writealign, (oggpack_buffer *b), (b)) \
+    F(void, oggpack_writecopy, (oggpack_buffer *b, void *source, long bits), (b, source, bits)) \
+    F(void, oggpack_reset, (oggpack_buffer *b), (b)) \
+    F(void, oggpack_writeclear, (oggpack_buffer *b), (b)) \
+    F(void, oggpack_readinit, (oggpack_buffer *b, unsigned char *buf, int bytes), (b, buf, bytes)) \
+    F(void, oggpack_write, (oggpack_buffer *b, unsigned long value, int bits), (b, value, bits)) \
+    F(void, oggpack_adv, (oggpack_buffer *b, int bits), (b, bits)) \
+    F(void, oggpackB_writeinit, (oggpack_buffer *b), (b)) \
+    F(void, oggpackB_writetrunc, (oggpack_buffer *b, long bits), (b, bits)) \
+    F(void, oggpackB_writealign, (oggpack_buffer *b), (b)) \
+    F(void, oggpackB_writecopy, (oggpack_buffer *b, void *source, long bits), (b, source, bits)) \
+    F(void, oggpackB_readinit, (oggpack_buffer *b, unsigned char *buf, int bytes), (b, buf, bytes)) \
+    F(void, oggpackB_write, (oggpack_buffer *b, unsigned long value, int bits), (b, value, bits)) \
+    F(void, ogg_page_checksum_set, (ogg_page *og), (og))
+
+#define DECLARE(ret, name, params, args) static ret (*p_##name) params;
+OGG_FUNCS(DECLARE)
+OGG_VOID_FUNCS(DECLARE)
+#undef DECLARE
+
+#define DEFINE(ret, name, params, args) ret name params { return p_##name args; }
+OGG_FUNCS(DEFINE)
+#undef DEFINE
+
+#define DEFINE_VOID(ret, name, params, args) ret name params { p_##name args; }
+OGG_VOID_FUNCS(DEFINE_VOID)
+#undef DEFINE_VOID
+
+// clear_funcs resets the function pointers, so that none is left pointing
+// into a library closed after a failed load
+static void clear_funcs(void) {
+#define CLEAR(ret, name, params, args) p_##name = NULL;
+    OGG_FUNCS(CLEAR)
+    OGG_VOID_FUNCS(CLEAR)
+#undef CLEAR
+}
+
+// go_ogg_dlopen loads libogg from path and resolves the functions, returning
+// NULL on success or else a description of the failure
+const char *go_ogg_dlopen(const char *path) {
+    void *handle = dl_open(path);
+    if (!handle) {
+        return dl_error();
+    }
+    void *sym;
+#define RESOLVE(ret, name, params, args) \
+    if (!(sym = dl_sym(handle, #name))) { \
+        dl_close(handle); \
+        clear_funcs(); \
+        return dl_missing(#name); \
+    } \
+    *(void **)&p_##name = sym;
+    OGG_FUNCS(RESOLVE)
+    OGG_VOID_FUNCS(RESOLVE)
+#undef RESOLVE
+    return NULL;
+}
@@ -0,0 +1,13 @@
+// Package ogg provides Go bindings for libogg
+//
+// Pages and packets returned by PageOut, Flush and PacketOut are copied into
+// Go memory by default and stay valid for as long as they are referenced.
+// After SetZeroCopy(true) they alias buffers owned by libogg instead, which
+// are only valid until the next call on the same state; use Clone to keep
+// such data beyond that point.
+//
+// When cgo is unavailable, or with the purego build tag, a pure Go
+// implementation of the same API is used instead of libogg. It produces
+// identical pages and packets. With the dlopen build tag, libogg is loaded at
+// run time instead of being linked, see Load.
+package ogg
@@ -0,0 +1,237 @@
+//go:build cgo && !purego
+
+package ogg_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/ogg"
+	"github.com/justa-cai/go-libopus/ogg/internal/framing"
+)
+
+// randomPackets returns packets with sizes around the lacing boundaries
+func randomPackets(rng *rand.Rand, n int) [][]byte {
+	sizes := []int{0, 1, 254, 255, 256, 510, 765, 4000, 65025, 70000}
+	packets := make([][]byte, n)
+	for i := range packets {
+		size := rng.Intn(600)
+		if rng.Intn(4) == 0 {
+			size = sizes[rng.Intn(len(sizes))]
+		}
+		packets[i] = make([]byte, size)
+		rng.Read(packets[i])
+	}
+	return packets
+}
+
+// encodeBoth pages packets with libogg and the pure Go framing, failing the
+// test on the first difference, and returns the physical stream
+func encodeBoth(t *testing.T, rng *rand.Rand, packets [][]byte) []byte {
+	t.Helper()
+	stream, err := ogg.NewOggStreamState(0x1234abcd)
+	if err != nil {
+		t.Fatalf("Failed to create OggStreamState: %v", err)
+	}
+	defer stream.Clear()
+	pure := framing.NewStreamState(0x1234abcd)
+
+	var out bytes.Buffer
+	var granule int64
+	for i, data := range packets {
+		eos := i == len(packets)-1
+		granule += int64(rng.Intn(2000))
+		packet := &ogg.OggPacket{Packet: data, Bytes: len(data), Granulepos: granule, Packetno: int64(i)}
+		if eos {
+			packet.EOS = 1
+		}
+		if err := stream.PacketIn(packet); err != nil {
+			t.Fatalf("Failed to add packet: %v", err)
+		}
+		if err := pure.PacketIn(data, eos, granule); err != nil {
+			t.Fatalf("Failed to add packet to framing: %v", err)
+		}
+
+		mode := rng.Intn(4)
+		nfill := 1 + rng.Intn(8192)
+		for {
+			page := &ogg.OggPage{}
+			var fPage framing.Page
+			var ret, fret int
+			switch mode {
+			case 0:
+				ret, err = stream.PageOut(page)
+				fret = pure.PageOut(&fPage, 4096)
+			case 1:
+				ret, err = stream.PageOutFill(page, nfill)
+				fret = pure.PageOut(&fPage, nfill)
+			case 2:
+				ret, err = stream.Flush(page)
+				fret = pure.Flush(&fPage, 4096)
+			default:
+				ret, err = stream.FlushFill(page, nfill)
+				fret = pure.Flush(&fPage, nfill)
+			}
+			if err != nil {
+				t.Fatalf("Failed to get page: %v", err)
+			}
+			if ret != fret {
+				t.Fatalf("Packet %d mode %d: libogg returned %d, framing %d", i, mode, ret, fret)
+			}
+			if ret == 0 {
+				break
+			}
+			if !bytes.Equal(page.Header, fPage.Header) || !bytes.Equal(page.Body, fPage.Body) {
+				t.Fatalf("Packet %d mode %d: pages differ\nlibogg  %x\nframing %x", i, mode, page.Header, fPage.Header)
+			}
+			out.Write(page.Header)
+			out.Write(page.Body)
+		}
+	}
+	if !pure.EOS() || !stream.EOS() {
+		t.Fatal("Expected both streams at EOS")
+	}
+	return out.Bytes()
+}
+
+func TestFramingEncodeMatchesLibogg(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for run := 0; run < 20; run++ {
+		encodeBoth(t, rng, randomPackets(rng, 1+rng.Intn(300)))
+	}
+}
+
+// corrupt damages the stream by inserting garbage, dropping bytes and
+// flipping bits
+func corrupt(rng *rand.Rand, data []byte) []byte {
+	data = append([]byte(nil), data...)
+	for i := 0; i < 4; i++ {
+		pos := rng.Intn(len(data))
+		switch rng.Intn(3) {
+		case 0:
+			garbage := []byte("OggSOgg garbage")
+			data = append(data[:pos], append(garbage, data[pos:]...)...)
+		case 1:
+			end := pos + rng.Intn(3000)
+			if end > len(data) {
+				end = len(data)
+			}
+			data = append(data[:pos], data[end:]...)
+		default:
+			data[pos] ^= 0x40
+		}
+	}
+	return data
+}
+
+func TestFramingDecodeMatchesLibogg(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for run := 0; run < 20; run++ {
+		data := encodeBoth(t, rng, randomPackets(rng, 1+rng.Intn(300)))
+		if run%2 == 1 {
+			data = corrupt(rng, data)
+		}
+
+		sync, err := ogg.NewOggSyncState()
+		if err != nil {
+			t.Fatalf("Failed to create OggSyncState: %v", err)
+		}
+		stream, err := ogg.NewOggStreamState(0x1234abcd)
+		if err != nil {
+			t.Fatalf("Failed to create OggStreamState: %v", err)
+		}
+		var pureSync framing.SyncState
+		pureStream := framing.NewStreamState(0x1234abcd)
+
+		packets := 0
+		for len(data) > 0 {
+			n := 1 + rng.Intn(5000)
+			if n > len(data) {
+				n = len(data)
+			}
+			buffer, err := sync.Buffer(n)
+			if err != nil {
+				t.Fatalf("Failed to get buffer: %v", err)
+			}
+			copy(buffer, data[:n])
+			copy(pureSync.Buffer(n), data[:n])
+			if err := sync.Wrote(n); err != nil {
+				t.Fatalf("Failed to mark written bytes: %v", err)
+			}
+			if err := pureSync.Wrote(n); err != nil {
+				t.Fatalf("Failed to mark written bytes in framing: %v", err)
+			}
+			data = data[n:]
+
+			for {
+				page := &ogg.OggPage{}
+				var fPage framing.Page
+				ret := sync.PageSeek(page)
+				if fret := pureSync.PageSeek(&fPage); ret != fret {
+					t.Fatalf("Run %d: libogg pageseek returned %d, framing %d", run, ret, fret)
+				}
+				if ret == 0 {
+					break
+				}
+				if ret < 0 {
+					continue
+				}
+				if !bytes.Equal(page.Header, fPage.Header) || !bytes.Equal(page.Body, fPage.Body) {
+					t.Fatalf("Run %d: synced pages differ", run)
+				}
+
+				perr := stream.PageIn(page)
+				fperr := pureStream.PageIn(&fPage)
+				if (perr == nil) != (fperr == nil) {
+					t.Fatalf("Run %d: libogg pagein error %v, framing %v", run, perr, fperr)
+				}
+				for {
+					// Peek at random; a peek consumes a hole in both implementations
+					if rng.Intn(2) == 0 {
+						peeked := &ogg.OggPacket{}
+						var fPeek framing.Packet
+						peek, err := stream.PacketPeek(peeked)
+						if err == ogg.ErrGap {
+							peek = -1
+						}
+						if fpeek := pureStream.PacketPeek(&fPeek); peek != fpeek {
+							t.Fatalf("Run %d packet %d: libogg peek returned %d, framing %d", run, packets, peek, fpeek)
+						}
+						if peek == 1 && !bytes.Equal(peeked.Packet, fPeek.Data) {
+							t.Fatalf("Run %d packet %d: peeked packets differ", run, packets)
+						}
+					}
+
+					packet := &ogg.OggPacket{}
+					var fPacket framing.Packet
+					ret, err := stream.PacketOut(packet)
+					if err == ogg.ErrGap {
+						ret = -1
+					}
+					fret := pureStream.PacketOut(&fPacket)
+					if ret != fret {
+						t.Fatalf("Run %d packet %d: libogg returned %d, framing %d", run, packets, ret, fret)
+					}
+					if ret == 0 {
+						break
+					}
+					packets++
+					if ret < 0 {
+						continue
+					}
+					if !bytes.Equal(packet.Packet, fPacket.Data) || (packet.BOS == 1) != fPacket.BOS ||
+						(packet.EOS == 1) != fPacket.EOS || packet.Granulepos != fPacket.Granulepos ||
+						packet.Packetno != fPacket.Packetno {
+						t.Fatalf("Run %d packet %d: packets differ", run, packets)
+					}
+				}
+			}
+		}
+		if stream.EOS() != pureStream.EOS() {
+			t.Fatalf("Run %d: libogg EOS %v, framing %v", run, stream.EOS(), pureStream.EOS())
+		}
+		stream.Clear()
+		sync.Clear()
+	}
+}
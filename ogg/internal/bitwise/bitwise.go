@@ -0,0 +1,156 @@
+// Package bitwise implements bit packing in pure Go, producing the same
+// bitstreams as libogg's oggpack_* (LSb first) and oggpackB_* (MSb first)
+// functions.
+package bitwise
+
+// Writer packs values into a growing buffer
+type Writer struct {
+	msb     bool
+	buf     []byte // always holds the byte at endByte
+	endByte int
+	endBit  int
+}
+
+// NewWriter creates a writer packing LSb first, or MSb first if msb is set
+func NewWriter(msb bool) *Writer {
+	return &Writer{msb: msb, buf: make([]byte, 1, 256)}
+}
+
+// Write packs the low bits of value; bits must be between 0 and 32
+func (w *Writer) Write(value uint32, bits int) {
+	if bits < 32 {
+		value &= 1<<bits - 1
+	}
+	for bits > 0 {
+		n := min(8-w.endBit, bits)
+		if w.msb {
+			chunk := byte(value >> (bits - n) & (1<<n - 1))
+			w.buf[w.endByte] |= chunk << (8 - w.endBit - n)
+		} else {
+			chunk := byte(value & (1<<n - 1))
+			w.buf[w.endByte] |= chunk << w.endBit
+			value >>= n
+		}
+		bits -= n
+		w.endBit += n
+		if w.endBit == 8 {
+			w.endBit = 0
+			w.endByte++
+			w.buf = append(w.buf, 0)
+		}
+	}
+}
+
+// WriteBytes packs whole bytes, each one as an 8 bit value
+func (w *Writer) WriteBytes(data []byte) {
+	if w.endBit == 0 {
+		w.buf = append(w.buf[:w.endByte], data...)
+		w.endByte += len(data)
+		w.buf = append(w.buf, 0)
+		return
+	}
+	for _, b := range data {
+		w.Write(uint32(b), 8)
+	}
+}
+
+// Align pads with zero bits up to the next byte boundary
+func (w *Writer) Align() {
+	if w.endBit > 0 {
+		w.Write(0, 8-w.endBit)
+	}
+}
+
+// Truncate discards everything after the first bits bits, which must not
+// exceed Bits
+func (w *Writer) Truncate(bits int) {
+	w.endByte = bits >> 3
+	w.endBit = bits & 7
+	w.buf = w.buf[:w.endByte+1]
+	if w.msb {
+		w.buf[w.endByte] &= ^byte(0xff >> w.endBit)
+	} else {
+		w.buf[w.endByte] &= byte(1<<w.endBit - 1)
+	}
+}
+
+// Reset discards all written bits
+func (w *Writer) Reset() {
+	w.Truncate(0)
+}
+
+// Bits returns the number of bits written
+func (w *Writer) Bits() int {
+	return w.endByte*8 + w.endBit
+}
+
+// Bytes returns the packed data, padded with zero bits to a whole byte. It
+// aliases the writer buffer until the next call on the writer.
+func (w *Writer) Bytes() []byte {
+	return w.buf[:w.endByte+(w.endBit+7)/8]
+}
+
+// Reader unpacks values from a buffer
+type Reader struct {
+	msb  bool
+	data []byte
+	pos  int // read position in bits
+	bad  bool
+}
+
+// NewReader creates a reader unpacking LSb first, or MSb first if msb is set
+func NewReader(data []byte, msb bool) *Reader {
+	return &Reader{msb: msb, data: data}
+}
+
+// Look returns the next bits bits without consuming them. It reports false
+// when fewer bits remain or an earlier read ran past the end.
+func (r *Reader) Look(bits int) (uint32, bool) {
+	if r.bad || bits < 0 || bits > 32 || r.pos+bits > len(r.data)*8 {
+		return 0, false
+	}
+	var value uint32
+	for i := 0; i < bits; i++ {
+		p := r.pos + i
+		if r.msb {
+			value = value<<1 | uint32(r.data[p>>3]>>(7-p&7)&1)
+		} else {
+			value |= uint32(r.data[p>>3]>>(p&7)&1) << i
+		}
+	}
+	return value, true
+}
+
+// Read consumes and returns the next bits bits. Reading past the end fails
+// this and all later reads.
+func (r *Reader) Read(bits int) (uint32, bool) {
+	value, ok := r.Look(bits)
+	if !ok {
+		r.fail()
+		return 0, false
+	}
+	r.pos += bits
+	return value, true
+}
+
+// Skip consumes bits bits. Skipping past the end fails all later reads.
+func (r *Reader) Skip(bits int) bool {
+	if r.bad || bits < 0 || r.pos+bits > len(r.data)*8 {
+		r.fail()
+		return false
+	}
+	r.pos += bits
+	return true
+}
+
+// fail marks the reader as having run past the end, which libogg reports as
+// one bit past the end of the data
+func (r *Reader) fail() {
+	r.bad = true
+	r.pos = len(r.data)*8 + 1
+}
+
+// Bits returns the number of bits consumed
+func (r *Reader) Bits() int {
+	return r.pos
+}
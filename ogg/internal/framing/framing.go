@@ -0,0 +1,623 @@
+// Package framing implements Ogg page framing (RFC 3533) in pure Go. It
+// follows libogg's ogg_sync_* and ogg_stream_* functions step by step, so
+// pages produced and packets recovered are identical to libogg's.
+package framing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var crcTable = func() (table [256]uint32) {
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// Page is an Ogg page; Header and Body may alias buffers of the state that
+// returned them
+type Page struct {
+	Header []byte
+	Body   []byte
+}
+
+// Version returns the stream structure version
+func (p *Page) Version() int { return int(p.Header[4]) }
+
+// Continued reports whether the page starts with a continued packet
+func (p *Page) Continued() bool { return p.Header[5]&0x01 != 0 }
+
+// BOS reports whether the page is the first page of a logical stream
+func (p *Page) BOS() bool { return p.Header[5]&0x02 != 0 }
+
+// EOS reports whether the page is the last page of a logical stream
+func (p *Page) EOS() bool { return p.Header[5]&0x04 != 0 }
+
+// Granulepos returns the granule position of the page
+func (p *Page) Granulepos() int64 { return int64(binary.LittleEndian.Uint64(p.Header[6:14])) }
+
+// Serialno returns the serial number of the page
+func (p *Page) Serialno() int { return int(int32(binary.LittleEndian.Uint32(p.Header[14:18]))) }
+
+// Pageno returns the page sequence number
+func (p *Page) Pageno() int64 { return int64(int32(binary.LittleEndian.Uint32(p.Header[18:22]))) }
+
+// Packets returns the number of packets that end on the page
+func (p *Page) Packets() int {
+	count := 0
+	for _, val := range p.Header[27 : 27+int(p.Header[26])] {
+		if val < 255 {
+			count++
+		}
+	}
+	return count
+}
+
+// ChecksumSet computes the page CRC and stores it in the header
+func (p *Page) ChecksumSet() {
+	binary.LittleEndian.PutUint32(p.Header[22:26], 0)
+	var crc uint32
+	for _, b := range p.Header {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^b]
+	}
+	for _, b := range p.Body {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^b]
+	}
+	binary.LittleEndian.PutUint32(p.Header[22:26], crc)
+}
+
+// Packet is an Ogg packet; Data aliases the buffer of the stream state
+type Packet struct {
+	Data       []byte
+	BOS        bool
+	EOS        bool
+	Granulepos int64
+	Packetno   int64
+}
+
+// SyncState finds pages in a stream of bytes, like ogg_sync_state
+type SyncState struct {
+	data        []byte // len(data) is the storage size
+	fill        int
+	returned    int
+	headerBytes int
+	bodyBytes   int
+}
+
+// Clear releases the buffer; the state stays usable
+func (s *SyncState) Clear() {
+	*s = SyncState{}
+}
+
+// Reset discards buffered data
+func (s *SyncState) Reset() {
+	s.fill = 0
+	s.returned = 0
+	s.headerBytes = 0
+	s.bodyBytes = 0
+}
+
+// Buffer returns a buffer of at least size bytes to be filled by the caller
+func (s *SyncState) Buffer(size int) []byte {
+	// Clear out space that has been previously returned
+	if s.returned > 0 {
+		s.fill -= s.returned
+		if s.fill > 0 {
+			copy(s.data, s.data[s.returned:s.returned+s.fill])
+		}
+		s.returned = 0
+	}
+	if size > len(s.data)-s.fill {
+		// An extra page to be nice
+		data := make([]byte, size+s.fill+4096)
+		copy(data, s.data[:s.fill])
+		s.data = data
+	}
+	return s.data[s.fill : s.fill+size]
+}
+
+// Buffered returns the data buffered but not yet returned as pages
+func (s *SyncState) Buffered() []byte {
+	return s.data[s.returned:s.fill]
+}
+
+// Wrote marks bytes of the buffer as filled
+func (s *SyncState) Wrote(n int) error {
+	if n < 0 || s.fill+n > len(s.data) {
+		return errors.New("write past end of buffer")
+	}
+	s.fill += n
+	return nil
+}
+
+// PageSeek returns the page size if a page was found, 0 if more data is
+// needed, and the negated number of bytes skipped otherwise
+func (s *SyncState) PageSeek(p *Page) int {
+	page := s.data[s.returned:s.fill]
+	n := len(page)
+
+	if s.headerBytes == 0 {
+		if n < 27 {
+			return 0
+		}
+		if !bytes.Equal(page[:4], []byte("OggS")) {
+			return s.syncFail(page)
+		}
+		headerBytes := int(page[26]) + 27
+		if n < headerBytes {
+			return 0
+		}
+		for _, val := range page[27:headerBytes] {
+			s.bodyBytes += int(val)
+		}
+		s.headerBytes = headerBytes
+	}
+	if s.headerBytes+s.bodyBytes > n {
+		return 0
+	}
+
+	// The whole page is buffered, verify the checksum
+	candidate := Page{Header: page[:s.headerBytes], Body: page[s.headerBytes : s.headerBytes+s.bodyBytes]}
+	var checksum [4]byte
+	copy(checksum[:], page[22:26])
+	candidate.ChecksumSet()
+	if !bytes.Equal(checksum[:], page[22:26]) {
+		copy(page[22:26], checksum[:])
+		return s.syncFail(page)
+	}
+
+	if p != nil {
+		*p = candidate
+	}
+	size := s.headerBytes + s.bodyBytes
+	s.returned += size
+	s.headerBytes = 0
+	s.bodyBytes = 0
+	return size
+}
+
+// syncFail skips to the next possible capture pattern
+func (s *SyncState) syncFail(page []byte) int {
+	s.headerBytes = 0
+	s.bodyBytes = 0
+	skip := bytes.IndexByte(page[1:], 'O')
+	if skip < 0 {
+		skip = len(page)
+	} else {
+		skip++
+	}
+	s.returned += skip
+	return -skip
+}
+
+// Lacing value flags stored above the segment size
+const (
+	lacingBOS  = 0x100
+	lacingEOS  = 0x200
+	lacingHole = 0x400
+)
+
+// StreamState assembles packets into pages and pages into packets for one
+// logical stream, like ogg_stream_state
+type StreamState struct {
+	body         []byte
+	bodyFill     int
+	bodyReturned int
+
+	lacing         []int
+	granule        []int64
+	lacingFill     int
+	lacingPacket   int
+	lacingReturned int
+
+	header [282]byte
+
+	eos        bool
+	bos        bool
+	serialno   int
+	pageno     int64
+	packetno   int64
+	granulepos int64
+	ready      bool
+}
+
+// NewStreamState creates a stream state for the given serial number
+func NewStreamState(serialno int) *StreamState {
+	return &StreamState{
+		body:     make([]byte, 16*1024),
+		lacing:   make([]int, 1024),
+		granule:  make([]int64, 1024),
+		serialno: serialno,
+		ready:    true,
+	}
+}
+
+// Clear releases the buffers; the state must be initialized again before use
+func (s *StreamState) Clear() {
+	*s = StreamState{}
+}
+
+// Check returns an error if the state is not initialized
+func (s *StreamState) Check() error {
+	if !s.ready {
+		return errors.New("stream state is not initialized")
+	}
+	return nil
+}
+
+// Reset discards all buffered data
+func (s *StreamState) Reset() error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+	s.bodyFill = 0
+	s.bodyReturned = 0
+	s.lacingFill = 0
+	s.lacingPacket = 0
+	s.lacingReturned = 0
+	s.eos = false
+	s.bos = false
+	s.pageno = -1
+	s.packetno = 0
+	s.granulepos = 0
+	return nil
+}
+
+// ResetSerialno resets the state and assigns a new serial number
+func (s *StreamState) ResetSerialno(serialno int) error {
+	if err := s.Reset(); err != nil {
+		return err
+	}
+	s.serialno = serialno
+	return nil
+}
+
+// EOS reports whether the end of stream was reached
+func (s *StreamState) EOS() bool {
+	return s.Check() != nil || s.eos
+}
+
+func (s *StreamState) bodyExpand(needed int) {
+	if len(s.body)-needed <= s.bodyFill {
+		body := make([]byte, len(s.body)+needed+1024)
+		copy(body, s.body[:s.bodyFill])
+		s.body = body
+	}
+}
+
+func (s *StreamState) lacingExpand(needed int) {
+	if len(s.lacing)-needed <= s.lacingFill {
+		size := len(s.lacing) + needed + 32
+		lacing := make([]int, size)
+		copy(lacing, s.lacing[:s.lacingFill])
+		s.lacing = lacing
+		granule := make([]int64, size)
+		copy(granule, s.granule[:s.lacingFill])
+		s.granule = granule
+	}
+}
+
+// PacketIn submits a packet for paging
+func (s *StreamState) PacketIn(data []byte, eos bool, granulepos int64) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+	lacingVals := len(data)/255 + 1
+
+	if s.bodyReturned > 0 {
+		// Advance packet data past what was returned in the last page
+		s.bodyFill -= s.bodyReturned
+		if s.bodyFill > 0 {
+			copy(s.body, s.body[s.bodyReturned:s.bodyReturned+s.bodyFill])
+		}
+		s.bodyReturned = 0
+	}
+
+	s.bodyExpand(len(data))
+	s.lacingExpand(lacingVals)
+
+	copy(s.body[s.bodyFill:], data)
+	s.bodyFill += len(data)
+
+	// Store lacing values for this packet
+	i := 0
+	for ; i < lacingVals-1; i++ {
+		s.lacing[s.lacingFill+i] = 255
+		s.granule[s.lacingFill+i] = s.granulepos
+	}
+	s.lacing[s.lacingFill+i] = len(data) % 255
+	s.granulepos = granulepos
+	s.granule[s.lacingFill+i] = granulepos
+
+	// Flag the first segment as the beginning of the packet
+	s.lacing[s.lacingFill] |= lacingBOS
+	s.lacingFill += lacingVals
+	s.packetno++
+	if eos {
+		s.eos = true
+	}
+	return nil
+}
+
+// flush builds a page from buffered packets. Unless force is set, a page is
+// only produced once it holds more than nfill bytes in at least four packets
+// or the segment table is full.
+func (s *StreamState) flush(p *Page, force bool, nfill int) int {
+	if s.Check() != nil {
+		return 0
+	}
+	maxVals := s.lacingFill
+	if maxVals > 255 {
+		maxVals = 255
+	}
+	if maxVals == 0 {
+		return 0
+	}
+
+	vals := 0
+	granulepos := int64(-1)
+	if !s.bos {
+		// The initial header page only holds the first packet
+		granulepos = 0
+		for vals = 0; vals < maxVals; vals++ {
+			if s.lacing[vals]&0xff < 255 {
+				vals++
+				break
+			}
+		}
+	} else {
+		acc := 0
+		packetsDone := 0
+		packetJustDone := 0
+		for vals = 0; vals < maxVals; vals++ {
+			if acc > nfill && packetJustDone >= 4 {
+				force = true
+				break
+			}
+			acc += s.lacing[vals] & 0xff
+			if s.lacing[vals]&0xff < 255 {
+				granulepos = s.granule[vals]
+				packetsDone++
+				packetJustDone = packetsDone
+			} else {
+				packetJustDone = 0
+			}
+		}
+		if vals == 255 {
+			force = true
+		}
+	}
+	if !force {
+		return 0
+	}
+
+	header := s.header[:27+vals]
+	copy(header, "OggS")
+	header[4] = 0
+	header[5] = 0
+	if s.lacing[0]&lacingBOS == 0 {
+		header[5] |= 0x01
+	}
+	if !s.bos {
+		header[5] |= 0x02
+	}
+	if s.eos && s.lacingFill == vals {
+		header[5] |= 0x04
+	}
+	s.bos = true
+
+	binary.LittleEndian.PutUint64(header[6:14], uint64(granulepos))
+	binary.LittleEndian.PutUint32(header[14:18], uint32(s.serialno))
+	if s.pageno == -1 {
+		s.pageno = 0
+	}
+	binary.LittleEndian.PutUint32(header[18:22], uint32(s.pageno))
+	s.pageno++
+	header[26] = byte(vals)
+	bodyLen := 0
+	for i := 0; i < vals; i++ {
+		header[27+i] = byte(s.lacing[i])
+		bodyLen += s.lacing[i] & 0xff
+	}
+
+	p.Header = header
+	p.Body = s.body[s.bodyReturned : s.bodyReturned+bodyLen]
+
+	// Advance the lacing data and the returned body
+	s.lacingFill -= vals
+	copy(s.lacing, s.lacing[vals:vals+s.lacingFill])
+	copy(s.granule, s.granule[vals:vals+s.lacingFill])
+	s.bodyReturned += bodyLen
+
+	p.ChecksumSet()
+	return 1
+}
+
+// PageOut returns a page once enough data is buffered, like ogg_stream_pageout_fill
+func (s *StreamState) PageOut(p *Page, nfill int) int {
+	if s.Check() != nil {
+		return 0
+	}
+	force := (s.eos && s.lacingFill > 0) || (s.lacingFill > 0 && !s.bos)
+	return s.flush(p, force, nfill)
+}
+
+// Flush returns a page holding the buffered data, like ogg_stream_flush_fill
+func (s *StreamState) Flush(p *Page, nfill int) int {
+	return s.flush(p, true, nfill)
+}
+
+// PageIn adds a page to the stream. It fails if the page belongs to
+// another stream or has an unknown version.
+func (s *StreamState) PageIn(p *Page) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+	header := p.Header
+	body := p.Body
+	continued := p.Continued()
+	bos := p.BOS()
+	segments := int(header[26])
+	segptr := 0
+
+	// Clean up returned data
+	if s.bodyReturned > 0 {
+		s.bodyFill -= s.bodyReturned
+		if s.bodyFill > 0 {
+			copy(s.body, s.body[s.bodyReturned:s.bodyReturned+s.bodyFill])
+		}
+		s.bodyReturned = 0
+	}
+	if lr := s.lacingReturned; lr > 0 {
+		if s.lacingFill-lr > 0 {
+			copy(s.lacing, s.lacing[lr:s.lacingFill])
+			copy(s.granule, s.granule[lr:s.lacingFill])
+		}
+		s.lacingFill -= lr
+		s.lacingPacket -= lr
+		s.lacingReturned = 0
+	}
+
+	if p.Serialno() != s.serialno {
+		return errors.New("page belongs to another stream")
+	}
+	if p.Version() > 0 {
+		return errors.New("unsupported page version")
+	}
+
+	s.lacingExpand(segments + 1)
+
+	// Are we in sequence?
+	if pageno := p.Pageno(); pageno != s.pageno {
+		// Unroll the previous partial packet
+		for i := s.lacingPacket; i < s.lacingFill; i++ {
+			s.bodyFill -= s.lacing[i] & 0xff
+		}
+		s.lacingFill = s.lacingPacket
+
+		// Make a note of dropped data in the segment table
+		if s.pageno != -1 {
+			s.lacing[s.lacingFill] = lacingHole
+			s.lacingFill++
+			s.lacingPacket++
+		}
+	}
+
+	// Skip the segments of a continued packet whose start was not seen
+	if continued {
+		if s.lacingFill < 1 || s.lacing[s.lacingFill-1]&0xff < 255 || s.lacing[s.lacingFill-1] == lacingHole {
+			bos = false
+			for ; segptr < segments; segptr++ {
+				val := int(header[27+segptr])
+				body = body[val:]
+				if val < 255 {
+					segptr++
+					break
+				}
+			}
+		}
+	}
+
+	if len(body) > 0 {
+		s.bodyExpand(len(body))
+		copy(s.body[s.bodyFill:], body)
+		s.bodyFill += len(body)
+	}
+
+	saved := -1
+	for ; segptr < segments; segptr++ {
+		val := int(header[27+segptr])
+		s.lacing[s.lacingFill] = val
+		s.granule[s.lacingFill] = -1
+		if bos {
+			s.lacing[s.lacingFill] |= lacingBOS
+			bos = false
+		}
+		if val < 255 {
+			saved = s.lacingFill
+		}
+		s.lacingFill++
+		if val < 255 {
+			s.lacingPacket = s.lacingFill
+		}
+	}
+	// Set the granule position on the last segment of the last full packet
+	if saved != -1 {
+		s.granule[saved] = p.Granulepos()
+	}
+
+	if p.EOS() {
+		s.eos = true
+		if s.lacingFill > 0 {
+			s.lacing[s.lacingFill-1] |= lacingEOS
+		}
+	}
+	s.pageno = p.Pageno() + 1
+	return nil
+}
+
+// packetOut returns 1 with the next packet, 0 if none is complete, and -1
+// for a gap in the data. The packet is only consumed when adv is set.
+func (s *StreamState) packetOut(op *Packet, adv bool) int {
+	if s.Check() != nil {
+		return 0
+	}
+	ptr := s.lacingReturned
+	if s.lacingPacket <= ptr {
+		return 0
+	}
+	if s.lacing[ptr]&lacingHole != 0 {
+		// Tell the codec there is a gap
+		s.lacingReturned++
+		s.packetno++
+		return -1
+	}
+
+	size := s.lacing[ptr] & 0xff
+	n := size
+	eos := s.lacing[ptr]&lacingEOS != 0
+	bos := s.lacing[ptr]&lacingBOS != 0
+	for size == 255 {
+		ptr++
+		val := s.lacing[ptr]
+		size = val & 0xff
+		if val&lacingEOS != 0 {
+			eos = true
+		}
+		n += size
+	}
+
+	if op != nil {
+		op.EOS = eos
+		op.BOS = bos
+		op.Data = s.body[s.bodyReturned : s.bodyReturned+n]
+		op.Packetno = s.packetno
+		op.Granulepos = s.granule[ptr]
+	}
+	if adv {
+		s.bodyReturned += n
+		s.lacingReturned = ptr + 1
+		s.packetno++
+	}
+	return 1
+}
+
+// PacketOut removes the next packet from the stream
+func (s *StreamState) PacketOut(op *Packet) int {
+	return s.packetOut(op, true)
+}
+
+// PacketPeek returns the next packet without removing it
+func (s *StreamState) PacketPeek(op *Packet) int {
+	return s.packetOut(op, false)
+}
@@ -0,0 +1,243 @@
+package ogg
+
+import (
+	"errors"
+	"io"
+)
+
+// PageReader reads Ogg pages from an io.Reader, skipping bytes that do not
+// belong to a valid page
+type PageReader struct {
+	r      io.Reader
+	sync   *OggSyncState
+	chunk  []byte
+	eof    bool
+	onSync func(err *SyncError)
+}
+
+// NewPageReader creates a page reader reading from r
+func NewPageReader(r io.Reader) (*PageReader, error) {
+	sync, err := NewOggSyncState()
+	if err != nil {
+		return nil, err
+	}
+	return &PageReader{r: r, sync: sync, chunk: make([]byte, 4096)}, nil
+}
+
+// SetSyncHandler sets a function called with the bytes skipped while
+// looking for the next page, e.g. to count corrupt pages
+func (pr *PageReader) SetSyncHandler(handler func(err *SyncError)) {
+	pr.onSync = handler
+}
+
+// Next returns the next page, or io.EOF when the input is exhausted.
+// The page is owned by the caller.
+func (pr *PageReader) Next() (*OggPage, error) {
+	page := &OggPage{}
+	for {
+		ret, err := pr.sync.PageOut(page)
+		if err != nil {
+			// Skipped bytes while regaining sync
+			if syncErr, ok := err.(*SyncError); ok && pr.onSync != nil {
+				pr.onSync(syncErr)
+			}
+			continue
+		}
+		if ret == 1 {
+			return page, nil
+		}
+		if pr.eof {
+			return nil, io.EOF
+		}
+		if err := pr.fill(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fill reads the next chunk of input into the sync state
+func (pr *PageReader) fill() error {
+	n, err := pr.r.Read(pr.chunk)
+	if n > 0 {
+		buffer, berr := pr.sync.Buffer(n)
+		if berr != nil {
+			return berr
+		}
+		copy(buffer, pr.chunk[:n])
+		if werr := pr.sync.Wrote(n); werr != nil {
+			return werr
+		}
+	}
+	if err == io.EOF {
+		pr.eof = true
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close releases the sync state
+func (pr *PageReader) Close() error {
+	return pr.sync.Clear()
+}
+
+// PageWriter writes Ogg pages to an io.Writer
+type PageWriter struct {
+	w io.Writer
+}
+
+// NewPageWriter creates a page writer writing to w
+func NewPageWriter(w io.Writer) *PageWriter {
+	return &PageWriter{w: w}
+}
+
+// WritePage writes the header and body of a page
+func (pw *PageWriter) WritePage(page *OggPage) error {
+	if _, err := pw.w.Write(page.Header); err != nil {
+		return err
+	}
+	_, err := pw.w.Write(page.Body)
+	return err
+}
+
+// PacketReader reads the packets of a logical stream from an io.Reader. It
+// follows the first logical stream and, in a chained file, the streams that
+// begin after it ended; pages of other streams are ignored.
+type PacketReader struct {
+	pages  *PageReader
+	stream *OggStreamState
+	serial int
+	ended  bool
+}
+
+// NewPacketReader creates a packet reader reading from r
+func NewPacketReader(r io.Reader) (*PacketReader, error) {
+	pages, err := NewPageReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &PacketReader{pages: pages}, nil
+}
+
+// Serialno returns the serial number of the current logical stream, valid
+// once Next returned a packet
+func (pr *PacketReader) Serialno() int {
+	return pr.serial
+}
+
+// SetSyncHandler sets a function called with the bytes skipped while
+// looking for the next page
+func (pr *PacketReader) SetSyncHandler(handler func(err *SyncError)) {
+	pr.pages.SetSyncHandler(handler)
+}
+
+// Next returns the next packet, or io.EOF when the input is exhausted.
+// The packet is owned by the caller. ErrGap reports lost packets before the
+// next one, e.g. to run packet loss concealment; reading may continue.
+func (pr *PacketReader) Next() (*OggPacket, error) {
+	for {
+		if pr.stream != nil {
+			packet := &OggPacket{}
+			ret, err := pr.stream.PacketOut(packet)
+			if err != nil {
+				return nil, err
+			}
+			if ret == 1 {
+				return packet, nil
+			}
+		}
+
+		page, err := pr.pages.Next()
+		if err != nil {
+			return nil, err
+		}
+		if page.BOS() && (pr.stream == nil || pr.ended) {
+			if pr.stream != nil {
+				pr.stream.Clear()
+			}
+			pr.serial = page.Serialno()
+			pr.ended = false
+			if pr.stream, err = NewOggStreamState(pr.serial); err != nil {
+				return nil, err
+			}
+		}
+		if pr.stream == nil || pr.ended || page.Serialno() != pr.serial {
+			continue
+		}
+		if err := pr.stream.PageIn(page); err != nil {
+			return nil, err
+		}
+		pr.ended = page.EOS()
+	}
+}
+
+// Close releases the sync and stream states
+func (pr *PacketReader) Close() error {
+	if pr.stream != nil {
+		pr.stream.Clear()
+	}
+	return pr.pages.Close()
+}
+
+// PacketWriter writes the packets of a logical stream to an io.Writer,
+// writing out each page as libogg completes it
+type PacketWriter struct {
+	pages  *PageWriter
+	stream *OggStreamState
+}
+
+// NewPacketWriter creates a packet writer for a logical stream with the given
+// serial number
+func NewPacketWriter(w io.Writer, serialno int) (*PacketWriter, error) {
+	stream, err := NewOggStreamState(serialno)
+	if err != nil {
+		return nil, err
+	}
+	// Pages are written out before the next call on the stream
+	stream.SetZeroCopy(true)
+	return &PacketWriter{pages: NewPageWriter(w), stream: stream}, nil
+}
+
+// WritePacket adds a packet to the stream and writes out the pages it
+// completes. Setting EOS on the packet ends the stream.
+func (pw *PacketWriter) WritePacket(packet *OggPacket) error {
+	if pw.stream.EOS() {
+		return errors.New("packet written after end of stream")
+	}
+	if err := pw.stream.PacketIn(packet); err != nil {
+		return err
+	}
+	return pw.drain(pw.stream.PageOut)
+}
+
+// Flush writes all pending packets out, ending the current page. Header
+// packets that must end a page, like OpusTags, are followed by Flush.
+func (pw *PacketWriter) Flush() error {
+	return pw.drain(pw.stream.Flush)
+}
+
+// drain writes out the pages returned by next until it returns 0
+func (pw *PacketWriter) drain(next func(page *OggPage) (int, error)) error {
+	for {
+		page := &OggPage{}
+		ret, err := next(page)
+		if err != nil {
+			return err
+		}
+		if ret == 0 {
+			return nil
+		}
+		if err := pw.pages.WritePage(page); err != nil {
+			return err
+		}
+	}
+}
+
+// Close flushes pending packets and releases the stream state
+func (pw *PacketWriter) Close() error {
+	err := pw.Flush()
+	if cerr := pw.stream.Clear(); err == nil {
+		err = cerr
+	}
+	return err
+}
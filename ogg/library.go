@@ -0,0 +1,18 @@
+//go:build cgo && !purego && !dlopen && !vendored
+
+package ogg
+
+// #cgo LDFLAGS: -L${SRCDIR}/ -logg
+import "C"
+
+// Load does nothing in the default build, which links libogg at build time.
+// With the dlopen build tag it loads libogg from path at run time, see
+// library_dlopen.go.
+func Load(path string) error {
+	return nil
+}
+
+// load makes sure libogg is available before its first use
+func load() error {
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build cgo && !purego && dlopen
+
+package ogg
+
+// #cgo CFLAGS: -I${SRCDIR}/../internal/dl
+// #cgo linux LDFLAGS: -ldl
+// #include <stdlib.h>
+// const char *go_ogg_dlopen(const char *path);
+import "C"
+import (
+	"unsafe"
+
+	"github.com/justa-cai/go-libopus/internal/dl"
+)
+
+var libogg = dl.Library{Name: "libogg", Env: "LIBOGG_PATH", Names: dl.Names("ogg", "0")}
+
+// Load loads libogg from path, or if path is empty from the path in the
+// LIBOGG_PATH environment variable or else under its standard names, such as
+// libogg.so.0. It does nothing once libogg is loaded.
+//
+// Sync states, stream states and bit packers load libogg on first use, so
+// Load is only needed to choose the library or to check for it at startup.
+func Load(path string) error {
+	return libogg.Load(path, dlopen)
+}
+
+// load makes sure libogg is available before its first use
+func load() error {
+	return libogg.Implicit(dlopen)
+}
+
+// dlopen loads libogg from path, returning a description of the failure
+func dlopen(path string) string {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+	if msg := C.go_ogg_dlopen(cPath); msg != nil {
+		return C.GoString(msg)
+	}
+	return ""
+}
@@ -0,0 +1,16 @@
+//go:build cgo && !purego && vendored && !dlopen
+
+package ogg
+
+import _ "github.com/justa-cai/go-libopus/internal/libogg"
+
+// Load does nothing in the vendored build, which compiles libogg into the
+// package
+func Load(path string) error {
+	return nil
+}
+
+// load makes sure libogg is available before its first use
+func load() error {
+	return nil
+}
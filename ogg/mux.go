@@ -0,0 +1,132 @@
+package ogg
+
+import (
+	"io"
+	"time"
+)
+
+// MuxerPolicy controls when a Muxer completes pages. Zero values leave the
+// decision to libogg, which completes pages of about 4 KB.
+type MuxerPolicy struct {
+	// GranuleRate is the number of granule position units per second, e.g.
+	// 48000 for Opus. It is required for MaxPageDuration.
+	GranuleRate int
+	// MaxPageDuration completes a page once the packets on it span this long
+	MaxPageDuration time.Duration
+	// MaxPackets completes a page once it holds this many packets
+	MaxPackets int
+	// HeaderPackets is the number of leading header packets that are each
+	// flushed onto their own page, as Ogg Opus and Ogg Vorbis require
+	HeaderPackets int
+	// FillBytes is the page size in bytes at which libogg completes a page
+	FillBytes int
+}
+
+// Muxer writes packets of one logical stream to w, completing pages
+// according to a MuxerPolicy. To end a page on a specific packet, call Flush
+// right after WritePacket.
+type Muxer struct {
+	pages   *PageWriter
+	stream  *OggStreamState
+	policy  MuxerPolicy
+	packets int     // packets written so far
+	pending []int64 // granule positions of the packets not yet on a page
+	start   int64   // granule position at the end of the previous page
+}
+
+// NewMuxer creates a muxer for a logical stream with the given serial number
+func NewMuxer(w io.Writer, serialno int, policy MuxerPolicy) (*Muxer, error) {
+	stream, err := NewOggStreamState(serialno)
+	if err != nil {
+		return nil, err
+	}
+	// Pages are written out before the next call on the stream
+	stream.SetZeroCopy(true)
+	if policy.FillBytes <= 0 {
+		policy.FillBytes = 4096
+	}
+	return &Muxer{pages: NewPageWriter(w), stream: stream, policy: policy}, nil
+}
+
+// WritePacket adds a packet to the stream and writes out the pages it completes
+func (m *Muxer) WritePacket(packet *OggPacket) error {
+	if err := m.stream.PacketIn(packet); err != nil {
+		return err
+	}
+	m.packets++
+	m.pending = append(m.pending, packet.Granulepos)
+
+	if m.packets <= m.policy.HeaderPackets || m.pageFull(packet.Granulepos) {
+		return m.Flush()
+	}
+	for {
+		page := &OggPage{}
+		ret, err := m.stream.PageOutFill(page, m.policy.FillBytes)
+		if err != nil {
+			return err
+		}
+		if ret == 0 {
+			return nil
+		}
+		if err := m.writePage(page); err != nil {
+			return err
+		}
+	}
+}
+
+// pageFull reports whether the pending packets reached the page limits
+func (m *Muxer) pageFull(granulepos int64) bool {
+	if m.policy.MaxPackets > 0 && len(m.pending) >= m.policy.MaxPackets {
+		return true
+	}
+	if m.policy.MaxPageDuration > 0 && m.policy.GranuleRate > 0 && granulepos >= 0 {
+		limit := int64(m.policy.MaxPageDuration) * int64(m.policy.GranuleRate) / int64(time.Second)
+		if granulepos-m.start >= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush writes all pending packets out, ending the current page
+func (m *Muxer) Flush() error {
+	for {
+		page := &OggPage{}
+		ret, err := m.stream.FlushFill(page, m.policy.FillBytes)
+		if err != nil {
+			return err
+		}
+		if ret == 0 {
+			return nil
+		}
+		if err := m.writePage(page); err != nil {
+			return err
+		}
+	}
+}
+
+// writePage writes a page and drops the packets completed on it from pending
+func (m *Muxer) writePage(page *OggPage) error {
+	if err := m.pages.WritePage(page); err != nil {
+		return err
+	}
+	if n := page.Packets(); n > 0 {
+		if n > len(m.pending) {
+			n = len(m.pending)
+		}
+		m.pending = m.pending[n:]
+		if granule := page.Granulepos(); granule >= 0 {
+			m.start = granule
+		}
+	}
+	return nil
+}
+
+// Close flushes pending packets and releases the stream state
+func (m *Muxer) Close() error {
+	err := m.Flush()
+	if cerr := m.stream.Clear(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -1,8 +1,8 @@
-// Package ogg provides Go bindings for libogg
+//go:build cgo && !purego
+
 package ogg
 
 // #cgo CFLAGS: -I${SRCDIR}/include
-// #cgo LDFLAGS: -L${SRCDIR}/ -logg
 // #include <ogg/ogg.h>
 // #include <stdlib.h>
 // #include <string.h>
@@ -11,43 +11,136 @@ package ogg
 import "C"
 import (
 	"errors"
+	"fmt"
+	"runtime"
 	"unsafe"
 )
 
 // OggSyncState represents the ogg_sync_state structure from libogg
 // It is used for synchronizing Ogg bitstreams
 type OggSyncState struct {
-	state C.ogg_sync_state
+	state    C.ogg_sync_state
+	zeroCopy bool
+	pending  *OggPage // page found after skipped bytes, returned by the next PageOut
 }
 
 // OggStreamState represents the ogg_stream_state structure from libogg
 // It is used for managing Ogg streams
 type OggStreamState struct {
-	state C.ogg_stream_state
+	state    C.ogg_stream_state
+	zeroCopy bool
+}
+
+// setPage fills page from an ogg_page returned by libogg, copying the data
+// into Go memory unless zeroCopy is set
+func setPage(page *OggPage, cPage *C.ogg_page, zeroCopy bool) {
+	page.Header = unsafe.Slice((*byte)(unsafe.Pointer(cPage.header)), cPage.header_len)
+	page.HeaderLen = int(cPage.header_len)
+	page.Body = unsafe.Slice((*byte)(unsafe.Pointer(cPage.body)), cPage.body_len)
+	page.BodyLen = int(cPage.body_len)
+	if !zeroCopy {
+		*page = *page.Clone()
+	}
+}
+
+// c returns an ogg_page referencing the page data. Page data in Go memory
+// is pinned with pinner so the ogg_page can be passed to libogg. The page
+// must have a complete header.
+func (p *OggPage) c(pinner *runtime.Pinner) C.ogg_page {
+	var cPage C.ogg_page
+	pinner.Pin(&p.Header[0])
+	cPage.header = (*C.uchar)(unsafe.Pointer(&p.Header[0]))
+	cPage.header_len = C.long(p.HeaderLen)
+	if p.BodyLen > 0 {
+		pinner.Pin(&p.Body[0])
+		cPage.body = (*C.uchar)(unsafe.Pointer(&p.Body[0]))
+	}
+	cPage.body_len = C.long(p.BodyLen)
+	return cPage
+}
+
+// withC calls f with an ogg_page referencing the page data. It does nothing
+// if the page has no complete header or libogg is unavailable.
+func (p *OggPage) withC(f func(cPage *C.ogg_page)) {
+	if !p.headerComplete() || load() != nil {
+		return
+	}
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	cPage := p.c(&pinner)
+	f(&cPage)
+}
+
+// Version returns the stream structure version of the page
+func (p *OggPage) Version() int {
+	ret := C.int(-1)
+	p.withC(func(cPage *C.ogg_page) { ret = C.ogg_page_version(cPage) })
+	return int(ret)
+}
+
+// Continued reports whether the page starts with a packet continued from the previous page
+func (p *OggPage) Continued() bool {
+	var ret C.int
+	p.withC(func(cPage *C.ogg_page) { ret = C.ogg_page_continued(cPage) })
+	return ret != 0
+}
+
+// BOS reports whether the page is the first page of a logical stream
+func (p *OggPage) BOS() bool {
+	var ret C.int
+	p.withC(func(cPage *C.ogg_page) { ret = C.ogg_page_bos(cPage) })
+	return ret != 0
+}
+
+// EOS reports whether the page is the last page of a logical stream
+func (p *OggPage) EOS() bool {
+	var ret C.int
+	p.withC(func(cPage *C.ogg_page) { ret = C.ogg_page_eos(cPage) })
+	return ret != 0
+}
+
+// Granulepos returns the granule position of the page, -1 if no packet ends on it
+func (p *OggPage) Granulepos() int64 {
+	ret := C.ogg_int64_t(-1)
+	p.withC(func(cPage *C.ogg_page) { ret = C.ogg_page_granulepos(cPage) })
+	return int64(ret)
+}
+
+// Serialno returns the serial number of the logical stream the page belongs to
+func (p *OggPage) Serialno() int {
+	ret := C.int(-1)
+	p.withC(func(cPage *C.ogg_page) { ret = C.ogg_page_serialno(cPage) })
+	return int(ret)
+}
+
+// Pageno returns the sequence number of the page within its logical stream
+func (p *OggPage) Pageno() int64 {
+	ret := C.long(-1)
+	p.withC(func(cPage *C.ogg_page) { ret = C.ogg_page_pageno(cPage) })
+	return int64(ret)
 }
 
-// OggPacket represents the ogg_packet structure from libogg
-// It contains a single Ogg packet with its metadata
-type OggPacket struct {
-	Packet     []byte // The packet data
-	Bytes      int    // Number of bytes in the packet
-	BOS        int    // Beginning of stream flag
-	EOS        int    // End of stream flag
-	Granulepos int64  // Position in the stream
-	Packetno   int64  // Packet sequence number
+// Packets returns the number of packets that end on the page
+func (p *OggPage) Packets() int {
+	ret := C.int(-1)
+	p.withC(func(cPage *C.ogg_page) {
+		if p.HeaderLen >= 27+int(p.Header[26]) {
+			ret = C.ogg_page_packets(cPage)
+		}
+	})
+	return int(ret)
 }
 
-// OggPage represents the ogg_page structure from libogg
-// It contains a single Ogg page with its header and body
-type OggPage struct {
-	Header    []byte // Page header
-	HeaderLen int    // Length of the header
-	Body      []byte // Page body
-	BodyLen   int    // Length of the body
+// ChecksumSet recomputes the CRC of the page after its header or body was modified
+func (p *OggPage) ChecksumSet() {
+	p.withC(func(cPage *C.ogg_page) { C.ogg_page_checksum_set(cPage) })
 }
 
 // NewOggSyncState 初始化Ogg同步状态
 func NewOggSyncState() (*OggSyncState, error) {
+	if err := load(); err != nil {
+		return nil, err
+	}
 	state := &OggSyncState{}
 	ret := C.ogg_sync_init(&state.state)
 	if ret != 0 {
@@ -58,6 +151,7 @@ func NewOggSyncState() (*OggSyncState, error) {
 
 // Clear 清理Ogg同步状态
 func (s *OggSyncState) Clear() error {
+	s.pending = nil
 	if ret := C.ogg_sync_clear(&s.state); ret != 0 {
 		return errors.New("failed to clear ogg sync state")
 	}
@@ -81,25 +175,46 @@ func (s *OggSyncState) Wrote(bytes int) error {
 	return nil
 }
 
-// PageOut 从同步状态中提取页面
-func (s *OggSyncState) PageOut(page *OggPage) (int, error) {
-	var cPage C.ogg_page
-	ret := C.ogg_sync_pageout(&s.state, &cPage)
-	if ret < 0 {
-		return 0, errors.New("failed to get page from sync state")
+// SetZeroCopy controls whether PageOut returns pages aliasing the internal
+// buffer, which stays valid only until the next call to Buffer or PageOut
+func (s *OggSyncState) SetZeroCopy(enabled bool) {
+	s.zeroCopy = enabled
+}
+
+// buffered returns the data buffered but not yet returned as pages
+func (s *OggSyncState) buffered() []byte {
+	if s.state.data == nil || s.state.fill <= s.state.returned {
+		return nil
 	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(s.state.data)), s.state.fill)[s.state.returned:]
+}
 
-	// 转换C结构体到Go结构体
-	page.Header = unsafe.Slice((*byte)(unsafe.Pointer(cPage.header)), cPage.header_len)
-	page.HeaderLen = int(cPage.header_len)
-	page.Body = unsafe.Slice((*byte)(unsafe.Pointer(cPage.body)), cPage.body_len)
-	page.BodyLen = int(cPage.body_len)
+// Reset discards buffered data, used after seeking in the input
+func (s *OggSyncState) Reset() error {
+	s.pending = nil
+	if ret := C.ogg_sync_reset(&s.state); ret != 0 {
+		return errors.New("failed to reset ogg sync state")
+	}
+	return nil
+}
 
-	return int(ret), nil
+// PageSeek looks for the next page in the buffered data. It returns the page
+// size when a page was found, 0 when more data is needed, and the negated
+// number of skipped bytes when the data did not start with a valid page.
+func (s *OggSyncState) PageSeek(page *OggPage) int {
+	var cPage C.ogg_page
+	ret := C.ogg_sync_pageseek(&s.state, &cPage)
+	if ret > 0 {
+		setPage(page, &cPage, s.zeroCopy)
+	}
+	return int(ret)
 }
 
 // NewOggStreamState 初始化Ogg流状态
 func NewOggStreamState(serialno int) (*OggStreamState, error) {
+	if err := load(); err != nil {
+		return nil, err
+	}
 	state := &OggStreamState{}
 	ret := C.ogg_stream_init(&state.state, C.int(serialno))
 	if ret != 0 {
@@ -116,20 +231,29 @@ func (s *OggStreamState) Clear() error {
 	return nil
 }
 
+// SetZeroCopy controls whether PageOut, Flush and PacketOut return data
+// aliasing the internal buffers, which stays valid only until the next call
+// on the stream state
+func (s *OggStreamState) SetZeroCopy(enabled bool) {
+	s.zeroCopy = enabled
+}
+
 // PacketIn adds a packet to the stream
-// The packet data must remain valid until the packet is no longer needed by libogg
+// The packet data is copied, so the caller may reuse it once PacketIn returns
 func (s *OggStreamState) PacketIn(packet *OggPacket) error {
 	var cPacket C.ogg_packet
 
-	// Allocate memory for packet data
-	cData := C.malloc(C.size_t(len(packet.Packet)))
+	// Allocate memory for packet data, at least one byte for empty packets
+	cData := C.malloc(C.size_t(len(packet.Packet) + 1))
 	if cData == nil {
 		return errors.New("failed to allocate memory for packet data")
 	}
 	defer C.free(cData) // Free memory when function returns
 
 	// Copy data to C memory
-	C.memcpy(cData, unsafe.Pointer(&packet.Packet[0]), C.size_t(len(packet.Packet)))
+	if len(packet.Packet) > 0 {
+		C.memcpy(cData, unsafe.Pointer(&packet.Packet[0]), C.size_t(len(packet.Packet)))
+	}
 
 	// Set packet data
 	cPacket.packet = (*C.uchar)(cData)
@@ -145,7 +269,6 @@ func (s *OggStreamState) PacketIn(packet *OggPacket) error {
 		return errors.New("failed to add packet to stream")
 	}
 
-	// Note: The packet data is now owned by libogg and will be freed by it
 	return nil
 }
 
@@ -153,33 +276,36 @@ func (s *OggStreamState) PacketIn(packet *OggPacket) error {
 func (s *OggStreamState) PageOut(page *OggPage) (int, error) {
 	var cPage C.ogg_page
 	ret := C.ogg_stream_pageout(&s.state, &cPage)
-	if ret < 0 {
-		return 0, errors.New("failed to get page from stream")
-	}
-	if ret == 0 {
-		return 0, nil
-	}
-
-	// Check for nil pointers
-	if cPage.header == nil || cPage.body == nil {
-		return 0, errors.New("invalid page data")
-	}
-
-	// Convert C struct to Go struct
-	page.Header = unsafe.Slice((*byte)(unsafe.Pointer(cPage.header)), cPage.header_len)
-	page.HeaderLen = int(cPage.header_len)
-	page.Body = unsafe.Slice((*byte)(unsafe.Pointer(cPage.body)), cPage.body_len)
-	page.BodyLen = int(cPage.body_len)
+	return s.pageResult(int(ret), &cPage, page, "failed to get page from stream")
+}
 
-	return int(ret), nil
+// PageOutFill extracts a page from the stream, completing the page once it
+// holds at least nfill bytes instead of the libogg default of about 4 KB
+func (s *OggStreamState) PageOutFill(page *OggPage, nfill int) (int, error) {
+	var cPage C.ogg_page
+	ret := C.ogg_stream_pageout_fill(&s.state, &cPage, C.int(nfill))
+	return s.pageResult(int(ret), &cPage, page, "failed to get page from stream")
 }
 
 // Flush forces pages to be written to the stream
 func (s *OggStreamState) Flush(page *OggPage) (int, error) {
 	var cPage C.ogg_page
 	ret := C.ogg_stream_flush(&s.state, &cPage)
+	return s.pageResult(int(ret), &cPage, page, "failed to flush stream")
+}
+
+// FlushFill forces pages to be written to the stream, limiting each page to
+// about nfill bytes of packet data
+func (s *OggStreamState) FlushFill(page *OggPage, nfill int) (int, error) {
+	var cPage C.ogg_page
+	ret := C.ogg_stream_flush_fill(&s.state, &cPage, C.int(nfill))
+	return s.pageResult(int(ret), &cPage, page, "failed to flush stream")
+}
+
+// pageResult converts the result of a libogg page output function
+func (s *OggStreamState) pageResult(ret int, cPage *C.ogg_page, page *OggPage, msg string) (int, error) {
 	if ret < 0 {
-		return 0, errors.New("failed to flush stream")
+		return 0, errors.New(msg)
 	}
 	if ret == 0 {
 		return 0, nil
@@ -191,21 +317,18 @@ func (s *OggStreamState) Flush(page *OggPage) (int, error) {
 	}
 
 	// Convert C struct to Go struct
-	page.Header = unsafe.Slice((*byte)(unsafe.Pointer(cPage.header)), cPage.header_len)
-	page.HeaderLen = int(cPage.header_len)
-	page.Body = unsafe.Slice((*byte)(unsafe.Pointer(cPage.body)), cPage.body_len)
-	page.BodyLen = int(cPage.body_len)
-
-	return int(ret), nil
+	setPage(page, cPage, s.zeroCopy)
+	return ret, nil
 }
 
 // PageIn 将页面添加到流中
 func (s *OggStreamState) PageIn(page *OggPage) error {
-	var cPage C.ogg_page
-	cPage.header = (*C.uchar)(unsafe.Pointer(&page.Header[0]))
-	cPage.header_len = C.long(page.HeaderLen)
-	cPage.body = (*C.uchar)(unsafe.Pointer(&page.Body[0]))
-	cPage.body_len = C.long(page.BodyLen)
+	if err := page.check(); err != nil {
+		return fmt.Errorf("failed to add page to stream: %w", err)
+	}
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	cPage := page.c(&pinner)
 	ret := C.ogg_stream_pagein(&s.state, &cPage)
 	if ret != 0 {
 		return errors.New("failed to add page to stream")
@@ -218,16 +341,74 @@ func (s *OggStreamState) PacketOut(packet *OggPacket) (int, error) {
 	var cPacket C.ogg_packet
 	ret := C.ogg_stream_packetout(&s.state, &cPacket)
 	if ret < 0 {
-		return 0, errors.New("failed to get packet from stream")
+		return 0, ErrGap
 	}
 	if ret == 0 {
 		return 0, nil
 	}
+	s.setPacket(packet, &cPacket)
+	return int(ret), nil
+}
+
+// PacketPeek returns the next packet without removing it from the stream
+func (s *OggStreamState) PacketPeek(packet *OggPacket) (int, error) {
+	var cPacket C.ogg_packet
+	ret := C.ogg_stream_packetpeek(&s.state, &cPacket)
+	if ret < 0 {
+		return 0, ErrGap
+	}
+	if ret == 0 {
+		return 0, nil
+	}
+	s.setPacket(packet, &cPacket)
+	return int(ret), nil
+}
+
+// setPacket fills packet from an ogg_packet returned by libogg
+func (s *OggStreamState) setPacket(packet *OggPacket, cPacket *C.ogg_packet) {
 	packet.Packet = unsafe.Slice((*byte)(unsafe.Pointer(cPacket.packet)), cPacket.bytes)
+	if !s.zeroCopy {
+		packet.Packet = append([]byte(nil), packet.Packet...)
+	}
 	packet.Bytes = int(cPacket.bytes)
-	packet.BOS = int(cPacket.b_o_s)
-	packet.EOS = int(cPacket.e_o_s)
+	// libogg reports the flags as raw lacing bits, normalize them to 0 or 1
+	packet.BOS, packet.EOS = 0, 0
+	if cPacket.b_o_s != 0 {
+		packet.BOS = 1
+	}
+	if cPacket.e_o_s != 0 {
+		packet.EOS = 1
+	}
 	packet.Granulepos = int64(cPacket.granulepos)
 	packet.Packetno = int64(cPacket.packetno)
-	return int(ret), nil
+}
+
+// Reset discards all buffered pages and packets, used after seeking
+func (s *OggStreamState) Reset() error {
+	if ret := C.ogg_stream_reset(&s.state); ret != 0 {
+		return errors.New("failed to reset ogg stream state")
+	}
+	return nil
+}
+
+// ResetSerialno resets the stream and assigns a new serial number
+func (s *OggStreamState) ResetSerialno(serialno int) error {
+	if ret := C.ogg_stream_reset_serialno(&s.state, C.int(serialno)); ret != 0 {
+		return errors.New("failed to reset ogg stream state")
+	}
+	return nil
+}
+
+// EOS reports whether an end of stream packet was added with PacketIn or an
+// end of stream page with PageIn
+func (s *OggStreamState) EOS() bool {
+	return C.ogg_stream_eos(&s.state) != 0
+}
+
+// Check returns an error if the stream state is uninitialized or failed an allocation
+func (s *OggStreamState) Check() error {
+	if ret := C.ogg_stream_check(&s.state); ret != 0 {
+		return errors.New("ogg stream state is not ready")
+	}
+	return nil
 }
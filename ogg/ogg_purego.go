@@ -0,0 +1,325 @@
+//go:build !cgo || purego
+
+package ogg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/justa-cai/go-libopus/ogg/internal/framing"
+)
+
+// OggSyncState synchronizes Ogg bitstreams, implemented in pure Go
+type OggSyncState struct {
+	state    framing.SyncState
+	zeroCopy bool
+	pending  *OggPage // page found after skipped bytes, returned by the next PageOut
+}
+
+// OggStreamState manages a logical Ogg stream, implemented in pure Go
+type OggStreamState struct {
+	state    framing.StreamState
+	zeroCopy bool
+}
+
+// setPage fills page from a page returned by the framing state, copying the
+// data unless zeroCopy is set
+func setPage(page *OggPage, fPage *framing.Page, zeroCopy bool) {
+	page.Header = fPage.Header
+	page.HeaderLen = len(fPage.Header)
+	page.Body = fPage.Body
+	page.BodyLen = len(fPage.Body)
+	if !zeroCopy {
+		*page = *page.Clone()
+	}
+}
+
+// withFraming calls f with a framing page referencing the page data. It does
+// nothing if the page has no complete header.
+func (p *OggPage) withFraming(f func(fPage *framing.Page)) {
+	if !p.headerComplete() {
+		return
+	}
+	f(&framing.Page{Header: p.Header[:p.HeaderLen], Body: p.Body[:p.BodyLen]})
+}
+
+// Version returns the stream structure version of the page
+func (p *OggPage) Version() int {
+	ret := -1
+	p.withFraming(func(fPage *framing.Page) { ret = fPage.Version() })
+	return ret
+}
+
+// Continued reports whether the page starts with a packet continued from the previous page
+func (p *OggPage) Continued() bool {
+	var ret bool
+	p.withFraming(func(fPage *framing.Page) { ret = fPage.Continued() })
+	return ret
+}
+
+// BOS reports whether the page is the first page of a logical stream
+func (p *OggPage) BOS() bool {
+	var ret bool
+	p.withFraming(func(fPage *framing.Page) { ret = fPage.BOS() })
+	return ret
+}
+
+// EOS reports whether the page is the last page of a logical stream
+func (p *OggPage) EOS() bool {
+	var ret bool
+	p.withFraming(func(fPage *framing.Page) { ret = fPage.EOS() })
+	return ret
+}
+
+// Granulepos returns the granule position of the page, -1 if no packet ends on it
+func (p *OggPage) Granulepos() int64 {
+	ret := int64(-1)
+	p.withFraming(func(fPage *framing.Page) { ret = fPage.Granulepos() })
+	return ret
+}
+
+// Serialno returns the serial number of the logical stream the page belongs to
+func (p *OggPage) Serialno() int {
+	ret := -1
+	p.withFraming(func(fPage *framing.Page) { ret = fPage.Serialno() })
+	return ret
+}
+
+// Pageno returns the sequence number of the page within its logical stream
+func (p *OggPage) Pageno() int64 {
+	ret := int64(-1)
+	p.withFraming(func(fPage *framing.Page) { ret = fPage.Pageno() })
+	return ret
+}
+
+// Packets returns the number of packets that end on the page
+func (p *OggPage) Packets() int {
+	ret := -1
+	p.withFraming(func(fPage *framing.Page) {
+		if len(fPage.Header) >= 27+int(fPage.Header[26]) {
+			ret = fPage.Packets()
+		}
+	})
+	return ret
+}
+
+// ChecksumSet recomputes the CRC of the page after its header or body was modified
+func (p *OggPage) ChecksumSet() {
+	p.withFraming(func(fPage *framing.Page) { fPage.ChecksumSet() })
+}
+
+// Load does nothing, the pure Go implementation needs no library
+func Load(path string) error {
+	return nil
+}
+
+// NewOggSyncState initializes an Ogg sync state
+func NewOggSyncState() (*OggSyncState, error) {
+	return &OggSyncState{}, nil
+}
+
+// Clear releases the buffer of the sync state
+func (s *OggSyncState) Clear() error {
+	s.pending = nil
+	s.state.Clear()
+	return nil
+}
+
+// Buffer returns a buffer of size bytes to be filled with input data
+func (s *OggSyncState) Buffer(size int) ([]byte, error) {
+	if size < 0 {
+		return nil, errors.New("failed to get buffer")
+	}
+	return s.state.Buffer(size), nil
+}
+
+// Wrote marks the number of bytes written into the buffer
+func (s *OggSyncState) Wrote(bytes int) error {
+	if err := s.state.Wrote(bytes); err != nil {
+		return errors.New("failed to mark written bytes")
+	}
+	return nil
+}
+
+// SetZeroCopy controls whether PageOut returns pages aliasing the internal
+// buffer, which stays valid only until the next call to Buffer or PageOut
+func (s *OggSyncState) SetZeroCopy(enabled bool) {
+	s.zeroCopy = enabled
+}
+
+// buffered returns the data buffered but not yet returned as pages
+func (s *OggSyncState) buffered() []byte {
+	return s.state.Buffered()
+}
+
+// Reset discards buffered data, used after seeking in the input
+func (s *OggSyncState) Reset() error {
+	s.pending = nil
+	s.state.Reset()
+	return nil
+}
+
+// PageSeek looks for the next page in the buffered data. It returns the page
+// size when a page was found, 0 when more data is needed, and the negated
+// number of skipped bytes when the data did not start with a valid page.
+func (s *OggSyncState) PageSeek(page *OggPage) int {
+	var fPage framing.Page
+	ret := s.state.PageSeek(&fPage)
+	if ret > 0 {
+		setPage(page, &fPage, s.zeroCopy)
+	}
+	return ret
+}
+
+// NewOggStreamState initializes an Ogg stream state
+func NewOggStreamState(serialno int) (*OggStreamState, error) {
+	return &OggStreamState{state: *framing.NewStreamState(serialno)}, nil
+}
+
+// Clear releases the buffers of the stream state
+func (s *OggStreamState) Clear() error {
+	s.state.Clear()
+	return nil
+}
+
+// SetZeroCopy controls whether PageOut, Flush and PacketOut return data
+// aliasing the internal buffers, which stays valid only until the next call
+// on the stream state
+func (s *OggStreamState) SetZeroCopy(enabled bool) {
+	s.zeroCopy = enabled
+}
+
+// PacketIn adds a packet to the stream
+// The packet data is copied, so the caller may reuse it once PacketIn returns
+func (s *OggStreamState) PacketIn(packet *OggPacket) error {
+	if packet.Bytes < 0 || packet.Bytes > len(packet.Packet) {
+		return errors.New("failed to add packet to stream")
+	}
+	if err := s.state.PacketIn(packet.Packet[:packet.Bytes], packet.EOS != 0, packet.Granulepos); err != nil {
+		return errors.New("failed to add packet to stream")
+	}
+	return nil
+}
+
+// PageOut extracts a page from the stream
+func (s *OggStreamState) PageOut(page *OggPage) (int, error) {
+	return s.PageOutFill(page, 4096)
+}
+
+// PageOutFill extracts a page from the stream, completing the page once it
+// holds at least nfill bytes instead of the libogg default of about 4 KB
+func (s *OggStreamState) PageOutFill(page *OggPage, nfill int) (int, error) {
+	var fPage framing.Page
+	ret := s.state.PageOut(&fPage, nfill)
+	return s.pageResult(ret, &fPage, page)
+}
+
+// Flush forces pages to be written to the stream
+func (s *OggStreamState) Flush(page *OggPage) (int, error) {
+	return s.FlushFill(page, 4096)
+}
+
+// FlushFill forces pages to be written to the stream, limiting each page to
+// about nfill bytes of packet data
+func (s *OggStreamState) FlushFill(page *OggPage, nfill int) (int, error) {
+	var fPage framing.Page
+	ret := s.state.Flush(&fPage, nfill)
+	return s.pageResult(ret, &fPage, page)
+}
+
+// pageResult converts the result of a framing page output function
+func (s *OggStreamState) pageResult(ret int, fPage *framing.Page, page *OggPage) (int, error) {
+	if ret == 0 {
+		return 0, nil
+	}
+	setPage(page, fPage, s.zeroCopy)
+	return ret, nil
+}
+
+// PageIn adds a page to the stream
+func (s *OggStreamState) PageIn(page *OggPage) error {
+	if err := page.check(); err != nil {
+		return fmt.Errorf("failed to add page to stream: %w", err)
+	}
+	fPage := framing.Page{Header: page.Header[:page.HeaderLen], Body: page.Body[:page.BodyLen]}
+	if err := s.state.PageIn(&fPage); err != nil {
+		return errors.New("failed to add page to stream")
+	}
+	return nil
+}
+
+// PacketOut extracts the next packet from the stream
+func (s *OggStreamState) PacketOut(packet *OggPacket) (int, error) {
+	var fPacket framing.Packet
+	ret := s.state.PacketOut(&fPacket)
+	if ret < 0 {
+		return 0, ErrGap
+	}
+	if ret == 0 {
+		return 0, nil
+	}
+	s.setPacket(packet, &fPacket)
+	return ret, nil
+}
+
+// PacketPeek returns the next packet without removing it from the stream
+func (s *OggStreamState) PacketPeek(packet *OggPacket) (int, error) {
+	var fPacket framing.Packet
+	ret := s.state.PacketPeek(&fPacket)
+	if ret < 0 {
+		return 0, ErrGap
+	}
+	if ret == 0 {
+		return 0, nil
+	}
+	s.setPacket(packet, &fPacket)
+	return ret, nil
+}
+
+// setPacket fills packet from a packet returned by the framing state
+func (s *OggStreamState) setPacket(packet *OggPacket, fPacket *framing.Packet) {
+	packet.Packet = fPacket.Data
+	if !s.zeroCopy {
+		packet.Packet = append([]byte(nil), packet.Packet...)
+	}
+	packet.Bytes = len(fPacket.Data)
+	packet.BOS, packet.EOS = 0, 0
+	if fPacket.BOS {
+		packet.BOS = 1
+	}
+	if fPacket.EOS {
+		packet.EOS = 1
+	}
+	packet.Granulepos = fPacket.Granulepos
+	packet.Packetno = fPacket.Packetno
+}
+
+// Reset discards all buffered pages and packets, used after seeking
+func (s *OggStreamState) Reset() error {
+	if err := s.state.Reset(); err != nil {
+		return errors.New("failed to reset ogg stream state")
+	}
+	return nil
+}
+
+// ResetSerialno resets the stream and assigns a new serial number
+func (s *OggStreamState) ResetSerialno(serialno int) error {
+	if err := s.state.ResetSerialno(serialno); err != nil {
+		return errors.New("failed to reset ogg stream state")
+	}
+	return nil
+}
+
+// EOS reports whether an end of stream packet was added with PacketIn or an
+// end of stream page with PageIn
+func (s *OggStreamState) EOS() bool {
+	return s.state.EOS()
+}
+
+// Check returns an error if the stream state is uninitialized or failed an allocation
+func (s *OggStreamState) Check() error {
+	if err := s.state.Check(); err != nil {
+		return errors.New("ogg stream state is not ready")
+	}
+	return nil
+}
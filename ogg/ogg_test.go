@@ -1,7 +1,14 @@
 package ogg_test
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/justa-cai/go-libopus/ogg"
 )
@@ -168,3 +175,721 @@ func TestStreamClear(t *testing.T) {
 		t.Fatalf("Failed to clear stream state: %v", err)
 	}
 }
+
+// flushPackets adds packets to the stream and writes them out as one page
+func flushPackets(t *testing.T, state *ogg.OggStreamState, w *bytes.Buffer, packets ...*ogg.OggPacket) {
+	for _, packet := range packets {
+		if err := state.PacketIn(packet); err != nil {
+			t.Fatalf("Failed to add packet to stream: %v", err)
+		}
+	}
+	page := &ogg.OggPage{}
+	if _, err := state.Flush(page); err != nil {
+		t.Fatalf("Failed to flush stream: %v", err)
+	}
+	w.Write(page.Header)
+	w.Write(page.Body)
+}
+
+func TestDemuxer(t *testing.T) {
+	a, _ := ogg.NewOggStreamState(1)
+	defer a.Clear()
+	b, _ := ogg.NewOggStreamState(2)
+	defer b.Clear()
+	c, _ := ogg.NewOggStreamState(3)
+	defer c.Clear()
+
+	// Two multiplexed streams followed by a chained one
+	var data bytes.Buffer
+	flushPackets(t, a, &data, &ogg.OggPacket{Packet: []byte("a0"), Bytes: 2, BOS: 1})
+	flushPackets(t, b, &data, &ogg.OggPacket{Packet: []byte("b0"), Bytes: 2, BOS: 1})
+	flushPackets(t, a, &data, &ogg.OggPacket{Packet: []byte("a1"), Bytes: 2, Packetno: 1})
+	flushPackets(t, b, &data, &ogg.OggPacket{Packet: []byte("b1"), Bytes: 2, Packetno: 1, EOS: 1})
+	flushPackets(t, a, &data, &ogg.OggPacket{Packet: []byte("a2"), Bytes: 2, Packetno: 2, EOS: 1})
+	flushPackets(t, c, &data, &ogg.OggPacket{Packet: []byte("c0"), Bytes: 2, BOS: 1})
+	flushPackets(t, c, &data, &ogg.OggPacket{Packet: []byte("c1"), Bytes: 2, Packetno: 1, EOS: 1})
+
+	demuxer, err := ogg.NewDemuxer(bytes.NewReader(data.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create demuxer: %v", err)
+	}
+	defer demuxer.Close()
+
+	var events []string
+	for {
+		ev, err := demuxer.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to demux: %v", err)
+		}
+		switch ev.Type {
+		case ogg.StreamBegin:
+			events = append(events, fmt.Sprintf("begin %d %s", ev.Serial, ev.Packet.Packet))
+			// Only follow the first and the chained stream
+			if ev.Serial != 2 {
+				demuxer.Subscribe(ev.Serial)
+			}
+		case ogg.StreamPacket:
+			events = append(events, string(ev.Packet.Packet))
+		case ogg.StreamEnd:
+			events = append(events, fmt.Sprintf("end %d", ev.Serial))
+		}
+	}
+
+	want := "begin 1 a0,a0,begin 2 b0,a1,end 2,a2,end 1,begin 3 c0,c0,c1,end 3"
+	if got := strings.Join(events, ","); got != want {
+		t.Errorf("Unexpected events:\n got %s\nwant %s", got, want)
+	}
+	if len(demuxer.Streams()) != 0 {
+		t.Errorf("Expected no open streams, got %v", demuxer.Streams())
+	}
+}
+
+func TestDemuxerStreams(t *testing.T) {
+	// Multiplexed streams beginning in an order unrelated to their serials,
+	// one of which ends before the others
+	serials := []int{9, 2, 7, 100, 5}
+	states := make(map[int]*ogg.OggStreamState)
+	var data bytes.Buffer
+	for _, serial := range serials {
+		states[serial], _ = ogg.NewOggStreamState(serial)
+		defer states[serial].Clear()
+		flushPackets(t, states[serial], &data, &ogg.OggPacket{Packet: []byte("head"), Bytes: 4, BOS: 1})
+	}
+	flushPackets(t, states[7], &data, &ogg.OggPacket{Packet: []byte("tail"), Bytes: 4, Packetno: 1, EOS: 1})
+
+	demuxer, err := ogg.NewDemuxer(bytes.NewReader(data.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create demuxer: %v", err)
+	}
+	defer demuxer.Close()
+
+	expected := serials
+	for {
+		ev, err := demuxer.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to demux: %v", err)
+		}
+		if ev.Type == ogg.StreamEnd {
+			expected = []int{9, 2, 100, 5}
+		}
+		if ev.Type != ogg.StreamPacket && len(demuxer.Streams()) == len(expected) {
+			// Map iteration would vary between calls
+			for i := 0; i < 10; i++ {
+				if streams := demuxer.Streams(); !slices.Equal(streams, expected) {
+					t.Fatalf("Expected streams %v in order of beginning, got %v", expected, streams)
+				}
+			}
+		}
+	}
+	if !slices.Equal(expected, []int{9, 2, 100, 5}) {
+		t.Error("Expected the end of stream 7")
+	}
+}
+
+func TestPageAccessors(t *testing.T) {
+	state, err := ogg.NewOggStreamState(4321)
+	if err != nil {
+		t.Fatalf("Failed to create OggStreamState: %v", err)
+	}
+	defer state.Clear()
+
+	var data bytes.Buffer
+	flushPackets(t, state, &bytes.Buffer{}, &ogg.OggPacket{Packet: []byte("head"), Bytes: 4, BOS: 1})
+	flushPackets(t, state, &data,
+		&ogg.OggPacket{Packet: []byte("first"), Bytes: 5, Packetno: 1, Granulepos: 480},
+		&ogg.OggPacket{Packet: []byte("second"), Bytes: 6, Packetno: 2, Granulepos: 960})
+
+	// Work on a copy in Go memory
+	raw := data.Bytes()
+	headerLen := 27 + int(raw[26])
+	page := &ogg.OggPage{
+		Header:    raw[:headerLen],
+		HeaderLen: headerLen,
+		Body:      raw[headerLen:],
+		BodyLen:   len(raw) - headerLen,
+	}
+	if page.Version() != 0 || page.Continued() || page.BOS() || page.EOS() {
+		t.Errorf("Unexpected page flags")
+	}
+	if page.Granulepos() != 960 || page.Serialno() != 4321 || page.Pageno() != 1 || page.Packets() != 2 {
+		t.Errorf("Unexpected page fields: granule %d serial %d pageno %d packets %d",
+			page.Granulepos(), page.Serialno(), page.Pageno(), page.Packets())
+	}
+
+	page.SetGranulepos(1920)
+	page.SetSerialno(99)
+	page.SetPageno(7)
+	page.SetEOS(true)
+	page.SetContinued(true)
+
+	// The sync layer only returns pages whose CRC matches
+	sync, err := ogg.NewOggSyncState()
+	if err != nil {
+		t.Fatalf("Failed to create OggSyncState: %v", err)
+	}
+	defer sync.Clear()
+	buffer, err := sync.Buffer(len(raw))
+	if err != nil {
+		t.Fatalf("Failed to get buffer: %v", err)
+	}
+	copy(buffer, raw)
+	sync.Wrote(len(raw))
+
+	out := &ogg.OggPage{}
+	ret, err := sync.PageOut(out)
+	if err != nil || ret != 1 {
+		t.Fatalf("Rewritten page was rejected: ret %d err %v", ret, err)
+	}
+	if out.Granulepos() != 1920 || out.Serialno() != 99 || out.Pageno() != 7 || !out.EOS() || !out.Continued() {
+		t.Errorf("Unexpected rewritten fields: granule %d serial %d pageno %d eos %v",
+			out.Granulepos(), out.Serialno(), out.Pageno(), out.EOS())
+	}
+}
+
+func TestPageInvalid(t *testing.T) {
+	state, err := ogg.NewOggStreamState(1)
+	if err != nil {
+		t.Fatalf("Failed to create OggStreamState: %v", err)
+	}
+	defer state.Clear()
+
+	// A valid header with one 5-byte segment, cut or mislabeled in ways that
+	// must be rejected rather than crash
+	header := append([]byte("OggS"), make([]byte, 23)...)
+	header[26] = 1
+	header = append(header, 5)
+	for _, page := range []*ogg.OggPage{
+		{},
+		{Header: header[:10], HeaderLen: 10},
+		{Header: header[:27], HeaderLen: 28},
+		{Header: header[:27], HeaderLen: 27},
+		{Header: header, HeaderLen: 28, Body: []byte("abc"), BodyLen: 5},
+		{Header: header, HeaderLen: 28, Body: []byte("abc"), BodyLen: 3},
+	} {
+		if err := state.PageIn(page); err == nil {
+			t.Errorf("Expected error for page with %d/%d header and %d/%d body bytes",
+				page.HeaderLen, len(page.Header), page.BodyLen, len(page.Body))
+		}
+	}
+
+	// The accessors give -1 for a header too short to hold the fields
+	short := &ogg.OggPage{Header: header[:20], HeaderLen: 20}
+	if short.Version() != -1 || short.Granulepos() != -1 || short.Serialno() != -1 || short.Pageno() != -1 || short.Packets() != -1 {
+		t.Errorf("Expected -1 fields for a short header: version %d granule %d serial %d pageno %d packets %d",
+			short.Version(), short.Granulepos(), short.Serialno(), short.Pageno(), short.Packets())
+	}
+	if short.BOS() || short.EOS() || short.Continued() {
+		t.Error("Expected no flags for a short header")
+	}
+}
+
+func TestPacketOwnership(t *testing.T) {
+	writer, err := ogg.NewOggStreamState(7)
+	if err != nil {
+		t.Fatalf("Failed to create OggStreamState: %v", err)
+	}
+	defer writer.Clear()
+
+	var data bytes.Buffer
+	for i := 0; i < 3; i++ {
+		payload := bytes.Repeat([]byte{byte('a' + i)}, 100)
+		flushPackets(t, writer, &data, &ogg.OggPacket{Packet: payload, Bytes: len(payload), BOS: boolToInt(i == 0), Packetno: int64(i)})
+	}
+
+	sync, err := ogg.NewOggSyncState()
+	if err != nil {
+		t.Fatalf("Failed to create OggSyncState: %v", err)
+	}
+	defer sync.Clear()
+	buffer, _ := sync.Buffer(data.Len())
+	copy(buffer, data.Bytes())
+	sync.Wrote(data.Len())
+
+	reader, err := ogg.NewOggStreamState(7)
+	if err != nil {
+		t.Fatalf("Failed to create OggStreamState: %v", err)
+	}
+	defer reader.Clear()
+
+	// Packets kept across iterations must not be overwritten by later pages
+	var stored []*ogg.OggPacket
+	var pages []*ogg.OggPage
+	for {
+		page := &ogg.OggPage{}
+		ret, err := sync.PageOut(page)
+		if err != nil {
+			t.Fatalf("Failed to get page: %v", err)
+		}
+		if ret == 0 {
+			break
+		}
+		pages = append(pages, page)
+		if err := reader.PageIn(page); err != nil {
+			t.Fatalf("Failed to add page to stream: %v", err)
+		}
+		packet := &ogg.OggPacket{}
+		if ret, err := reader.PacketOut(packet); err != nil || ret != 1 {
+			t.Fatalf("Failed to get packet: ret %d err %v", ret, err)
+		}
+		stored = append(stored, packet)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("Expected 3 packets, got %d", len(stored))
+	}
+	for i, packet := range stored {
+		if !bytes.Equal(packet.Packet, bytes.Repeat([]byte{byte('a' + i)}, 100)) {
+			t.Errorf("Packet %d was overwritten", i)
+		}
+		if pages[i].Serialno() != 7 || pages[i].Pageno() != int64(i) {
+			t.Errorf("Page %d was overwritten", i)
+		}
+	}
+
+	// Clones are independent of the original
+	clone := stored[0].Clone()
+	stored[0].Packet[0] = 'z'
+	if clone.Packet[0] != 'a' {
+		t.Error("Packet clone shares memory with the original")
+	}
+	pageClone := pages[0].Clone()
+	pages[0].SetPageno(5)
+	if pageClone.Pageno() != 0 {
+		t.Error("Page clone shares memory with the original")
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestSyncPageSeekAndReset(t *testing.T) {
+	writer, _ := ogg.NewOggStreamState(11)
+	defer writer.Clear()
+	var page bytes.Buffer
+	flushPackets(t, writer, &page, &ogg.OggPacket{Packet: []byte("data"), Bytes: 4, BOS: 1})
+
+	sync, err := ogg.NewOggSyncState()
+	if err != nil {
+		t.Fatalf("Failed to create OggSyncState: %v", err)
+	}
+	defer sync.Clear()
+
+	input := append([]byte("garbage"), page.Bytes()...)
+	buffer, _ := sync.Buffer(len(input))
+	copy(buffer, input)
+	sync.Wrote(len(input))
+
+	out := &ogg.OggPage{}
+	if n := sync.PageSeek(out); n != -len("garbage") {
+		t.Errorf("Expected %d skipped bytes, got %d", -len("garbage"), n)
+	}
+	if n := sync.PageSeek(out); n != page.Len() {
+		t.Errorf("Expected page of %d bytes, got %d", page.Len(), n)
+	}
+	if out.Serialno() != 11 {
+		t.Errorf("Unexpected serial %d", out.Serialno())
+	}
+
+	// After a reset buffered data is gone
+	buffer, _ = sync.Buffer(10)
+	copy(buffer, page.Bytes()[:10])
+	sync.Wrote(10)
+	if err := sync.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	buffer, _ = sync.Buffer(page.Len())
+	copy(buffer, page.Bytes())
+	sync.Wrote(page.Len())
+	if ret, err := sync.PageOut(out); err != nil || ret != 1 {
+		t.Errorf("Expected a page after reset: ret %d err %v", ret, err)
+	}
+}
+
+func TestStreamControls(t *testing.T) {
+	writer, err := ogg.NewOggStreamState(21)
+	if err != nil {
+		t.Fatalf("Failed to create OggStreamState: %v", err)
+	}
+	defer writer.Clear()
+	if err := writer.Check(); err != nil {
+		t.Fatalf("Stream state not ready: %v", err)
+	}
+
+	// Small fill sizes split the packets over more pages
+	payload := make([]byte, 200)
+	for i := 0; i < 10; i++ {
+		packet := &ogg.OggPacket{Packet: payload, Bytes: len(payload), BOS: boolToInt(i == 0), EOS: boolToInt(i == 9), Packetno: int64(i), Granulepos: int64(i)}
+		if err := writer.PacketIn(packet); err != nil {
+			t.Fatalf("Failed to add packet: %v", err)
+		}
+	}
+	if !writer.EOS() {
+		t.Error("Expected EOS after adding the last packet")
+	}
+	var data bytes.Buffer
+	pages := 0
+	for {
+		page := &ogg.OggPage{}
+		ret, err := writer.FlushFill(page, 500)
+		if err != nil {
+			t.Fatalf("Failed to flush: %v", err)
+		}
+		if ret == 0 {
+			break
+		}
+		pages++
+		data.Write(page.Header)
+		data.Write(page.Body)
+	}
+	if pages < 4 {
+		t.Errorf("Expected at least 4 pages with a 500 byte fill, got %d", pages)
+	}
+
+	sync, _ := ogg.NewOggSyncState()
+	defer sync.Clear()
+	buffer, _ := sync.Buffer(data.Len())
+	copy(buffer, data.Bytes())
+	sync.Wrote(data.Len())
+
+	reader, _ := ogg.NewOggStreamState(21)
+	defer reader.Clear()
+	page := &ogg.OggPage{}
+	if ret, _ := sync.PageOut(page); ret != 1 {
+		t.Fatal("Expected a page")
+	}
+	reader.PageIn(page)
+
+	// Peeking does not consume the packet
+	peeked := &ogg.OggPacket{}
+	if ret, err := reader.PacketPeek(peeked); err != nil || ret != 1 {
+		t.Fatalf("Failed to peek packet: ret %d err %v", ret, err)
+	}
+	packet := &ogg.OggPacket{}
+	if ret, err := reader.PacketOut(packet); err != nil || ret != 1 {
+		t.Fatalf("Failed to get packet: ret %d err %v", ret, err)
+	}
+	if packet.Packetno != peeked.Packetno || packet.BOS != 1 {
+		t.Errorf("Peeked packet %d differs from packet %d", peeked.Packetno, packet.Packetno)
+	}
+
+	if err := reader.ResetSerialno(22); err != nil {
+		t.Fatalf("Failed to reset serial number: %v", err)
+	}
+	if reader.EOS() {
+		t.Error("Expected no EOS after reset")
+	}
+	if err := reader.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+}
+
+// readPages splits raw data into pages using the sync layer
+func readPages(t *testing.T, data []byte) []*ogg.OggPage {
+	sync, err := ogg.NewOggSyncState()
+	if err != nil {
+		t.Fatalf("Failed to create OggSyncState: %v", err)
+	}
+	defer sync.Clear()
+	buffer, _ := sync.Buffer(len(data))
+	copy(buffer, data)
+	sync.Wrote(len(data))
+
+	var pages []*ogg.OggPage
+	for {
+		page := &ogg.OggPage{}
+		ret, err := sync.PageOut(page)
+		if err != nil {
+			t.Fatalf("Failed to get page: %v", err)
+		}
+		if ret == 0 {
+			return pages
+		}
+		pages = append(pages, page)
+	}
+}
+
+func TestMuxerPolicy(t *testing.T) {
+	var data bytes.Buffer
+	muxer, err := ogg.NewMuxer(&data, 5, ogg.MuxerPolicy{
+		GranuleRate:     48000,
+		MaxPageDuration: 100 * time.Millisecond,
+		HeaderPackets:   2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create muxer: %v", err)
+	}
+
+	payload := make([]byte, 60)
+	muxer.WritePacket(&ogg.OggPacket{Packet: []byte("head"), Bytes: 4, BOS: 1})
+	muxer.WritePacket(&ogg.OggPacket{Packet: []byte("tags"), Bytes: 4, Packetno: 1})
+	for i := 0; i < 23; i++ {
+		packet := &ogg.OggPacket{Packet: payload, Bytes: len(payload), Packetno: int64(i + 2), Granulepos: int64((i + 1) * 960)}
+		if err := muxer.WritePacket(packet); err != nil {
+			t.Fatalf("Failed to write packet: %v", err)
+		}
+		// Request a page boundary on packet 12
+		if i == 12 {
+			muxer.Flush()
+		}
+	}
+	if err := muxer.Close(); err != nil {
+		t.Fatalf("Failed to close muxer: %v", err)
+	}
+
+	pages := readPages(t, data.Bytes())
+	var counts []string
+	for _, page := range pages {
+		counts = append(counts, fmt.Sprint(page.Packets()))
+	}
+	// Header packets alone, then 100ms (5 packet) pages with a boundary after packet 12
+	want := "1,1,5,5,3,5,5"
+	if got := strings.Join(counts, ","); got != want {
+		t.Errorf("Unexpected packets per page: got %s, want %s", got, want)
+	}
+}
+
+func TestPacketReaderWriter(t *testing.T) {
+	// A chained file of two links with garbage between them
+	var data bytes.Buffer
+	for link, serial := range []int{7, 8} {
+		writer, err := ogg.NewPacketWriter(&data, serial)
+		if err != nil {
+			t.Fatalf("Failed to create packet writer: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			payload := []byte(fmt.Sprintf("link %d packet %d", link, i))
+			packet := &ogg.OggPacket{Packet: payload, Bytes: len(payload), BOS: boolToInt(i == 0), EOS: boolToInt(i == 19), Granulepos: int64(i), Packetno: int64(i)}
+			if err := writer.WritePacket(packet); err != nil {
+				t.Fatalf("Failed to write packet: %v", err)
+			}
+			if i == 1 {
+				writer.Flush()
+			}
+		}
+		if err := writer.WritePacket(&ogg.OggPacket{Packet: []byte("late"), Bytes: 4}); err == nil {
+			t.Error("Expected an error writing after the end of stream")
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Failed to close packet writer: %v", err)
+		}
+		data.WriteString("not a page")
+	}
+
+	pages, err := ogg.NewPageReader(iotest.OneByteReader(bytes.NewReader(data.Bytes())))
+	if err != nil {
+		t.Fatalf("Failed to create page reader: %v", err)
+	}
+	defer pages.Close()
+	var rewritten bytes.Buffer
+	pageWriter := ogg.NewPageWriter(&rewritten)
+	for {
+		page, err := pages.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read page: %v", err)
+		}
+		if err := pageWriter.WritePage(page); err != nil {
+			t.Fatalf("Failed to write page: %v", err)
+		}
+	}
+	// Rewriting the pages drops the garbage only
+	if got, want := rewritten.Len(), data.Len()-2*len("not a page"); got != want {
+		t.Errorf("Rewritten stream is %d bytes, want %d", got, want)
+	}
+
+	reader, err := ogg.NewPacketReader(bytes.NewReader(rewritten.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create packet reader: %v", err)
+	}
+	defer reader.Close()
+	for n := 0; ; n++ {
+		packet, err := reader.Next()
+		if err == io.EOF {
+			if n != 40 {
+				t.Errorf("Read %d packets, want 40", n)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read packet: %v", err)
+		}
+		if want := fmt.Sprintf("link %d packet %d", n/20, n%20); string(packet.Packet) != want {
+			t.Errorf("Packet %d is %q, want %q", n, packet.Packet, want)
+		}
+		if want := 7 + n/20; reader.Serialno() != want {
+			t.Errorf("Packet %d has serial %d, want %d", n, reader.Serialno(), want)
+		}
+	}
+}
+
+func TestSyncErrorsAndGaps(t *testing.T) {
+	writer, _ := ogg.NewOggStreamState(31)
+	defer writer.Clear()
+	var pages []bytes.Buffer
+	for i := 0; i < 4; i++ {
+		var page bytes.Buffer
+		payload := []byte(fmt.Sprintf("packet %d", i))
+		flushPackets(t, writer, &page, &ogg.OggPacket{Packet: payload, Bytes: len(payload), BOS: boolToInt(i == 0), Packetno: int64(i)})
+		pages = append(pages, page)
+	}
+
+	// Garbage before the second page, a bad CRC on the third
+	var data bytes.Buffer
+	data.Write(pages[0].Bytes())
+	data.WriteString("garbage")
+	data.Write(pages[1].Bytes())
+	corrupt := pages[2].Bytes()
+	corrupt[len(corrupt)-1] ^= 0xff
+	data.Write(corrupt)
+	data.Write(pages[3].Bytes())
+
+	reader, err := ogg.NewPacketReader(bytes.NewReader(data.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create packet reader: %v", err)
+	}
+	defer reader.Close()
+	var syncErrs []string
+	reader.SetSyncHandler(func(err *ogg.SyncError) {
+		syncErrs = append(syncErrs, fmt.Sprintf("%d %v", err.Skipped, err.BadChecksum))
+	})
+	var packets []string
+	for {
+		packet, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err == ogg.ErrGap {
+			packets = append(packets, "gap")
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to read packet: %v", err)
+		}
+		packets = append(packets, string(packet.Packet))
+	}
+
+	want := "packet 0,packet 1,gap,packet 3"
+	if got := strings.Join(packets, ","); got != want {
+		t.Errorf("Unexpected packets: got %s, want %s", got, want)
+	}
+	wantSync := fmt.Sprintf("7 false,%d true", len(corrupt))
+	if got := strings.Join(syncErrs, ","); got != wantSync {
+		t.Errorf("Unexpected sync errors: got %s, want %s", got, wantSync)
+	}
+
+	demuxer, err := ogg.NewDemuxer(bytes.NewReader(data.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create demuxer: %v", err)
+	}
+	defer demuxer.Close()
+	gaps := 0
+	for {
+		ev, err := demuxer.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to demux: %v", err)
+		}
+		if ev.Type == ogg.StreamGap {
+			gaps++
+		}
+	}
+	if gaps != 1 {
+		t.Errorf("Expected 1 gap event, got %d", gaps)
+	}
+}
+
+func TestBitWriterReader(t *testing.T) {
+	for _, tc := range []struct {
+		order ogg.BitOrder
+		want  []byte
+	}{
+		{ogg.LSBFirst, []byte{0xfd, 0x34, 0x12, 0x01}},
+		{ogg.MSBFirst, []byte{0xbf, 0x12, 0x34, 0x80}},
+	} {
+		writer, err := ogg.NewBitWriter(tc.order)
+		if err != nil {
+			t.Fatalf("Failed to create bit writer: %v", err)
+		}
+		writer.Write(5, 3)
+		writer.Write(0x1f, 5)
+		writer.Write(0x1234, 16)
+		writer.Write(1, 1)
+		if writer.Bits() != 25 {
+			t.Errorf("Expected 25 bits, got %d", writer.Bits())
+		}
+		got := writer.Bytes()
+		writer.Close()
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("Order %d: packed %x, want %x", tc.order, got, tc.want)
+		}
+
+		reader, err := ogg.NewBitReader(got, tc.order)
+		if err != nil {
+			t.Fatalf("Failed to create bit reader: %v", err)
+		}
+		var fields []string
+		for _, bits := range []int{3, 5, 16, 1} {
+			value, err := reader.Read(bits)
+			if err != nil {
+				t.Fatalf("Failed to read %d bits: %v", bits, err)
+			}
+			fields = append(fields, fmt.Sprintf("%x", value))
+		}
+		if got := strings.Join(fields, ","); got != "5,1f,1234,1" {
+			t.Errorf("Order %d: read %s", tc.order, got)
+		}
+		if _, err := reader.Read(8); err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected io.ErrUnexpectedEOF reading past the end, got %v", err)
+		}
+		if _, err := reader.Read(0); err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected reads to keep failing after the end, got %v", err)
+		}
+		reader.Close()
+	}
+
+	// A Vorbis identification header, packed LSb first
+	writer, _ := ogg.NewBitWriter(ogg.LSBFirst)
+	defer writer.Close()
+	writer.Write(1, 8)
+	writer.WriteBytes([]byte("vorbis"))
+	writer.Write(0, 32)
+	writer.Write(2, 8)
+	writer.Write(44100, 32)
+	writer.Write(0xffffffff, 32)
+	writer.Write(128000, 32)
+	writer.Write(0, 32)
+	writer.Write(8, 4)
+	writer.Write(11, 4)
+	writer.Write(1, 1)
+	writer.Align()
+	header := writer.Bytes()
+	if len(header) != 30 || string(header[1:7]) != "vorbis" || header[29] != 1 {
+		t.Fatalf("Unexpected Vorbis header %x", header)
+	}
+
+	reader, _ := ogg.NewBitReader(header, ogg.LSBFirst)
+	defer reader.Close()
+	reader.Skip(7*8 + 32)
+	channels, _ := reader.Read(8)
+	rate, _ := reader.Read(32)
+	maximum, _ := reader.Read(32)
+	if channels != 2 || rate != 44100 || maximum != 0xffffffff {
+		t.Errorf("Unexpected Vorbis fields: channels %d rate %d maximum %x", channels, rate, maximum)
+	}
+	if look, _ := reader.Look(32); look != 128000 || reader.Bits() != 20*8 {
+		t.Errorf("Look returned %d at bit %d", look, reader.Bits())
+	}
+}
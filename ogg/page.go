@@ -0,0 +1,127 @@
+package ogg
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// OggPacket represents the ogg_packet structure from libogg
+// It contains a single Ogg packet with its metadata
+type OggPacket struct {
+	Packet     []byte // The packet data
+	Bytes      int    // Number of bytes in the packet
+	BOS        int    // Beginning of stream flag
+	EOS        int    // End of stream flag
+	Granulepos int64  // Position in the stream
+	Packetno   int64  // Packet sequence number
+}
+
+// OggPage represents the ogg_page structure from libogg
+// It contains a single Ogg page with its header and body. The header
+// accessors return -1, or false, for a page without a complete header.
+type OggPage struct {
+	Header    []byte // Page header
+	HeaderLen int    // Length of the header
+	Body      []byte // Page body
+	BodyLen   int    // Length of the body
+}
+
+// Clone returns a copy of the packet whose data is owned by Go
+func (p *OggPacket) Clone() *OggPacket {
+	clone := *p
+	if p.Packet != nil {
+		clone.Packet = make([]byte, len(p.Packet))
+		copy(clone.Packet, p.Packet)
+	}
+	return &clone
+}
+
+// Clone returns a copy of the page whose header and body are owned by Go
+func (p *OggPage) Clone() *OggPage {
+	data := make([]byte, len(p.Header)+len(p.Body))
+	copy(data, p.Header)
+	copy(data[len(p.Header):], p.Body)
+	return &OggPage{
+		Header:    data[:len(p.Header):len(p.Header)],
+		HeaderLen: p.HeaderLen,
+		Body:      data[len(p.Header):],
+		BodyLen:   p.BodyLen,
+	}
+}
+
+// headerComplete reports whether the page has the fixed part of a header and
+// lengths within its slices, so that the header fields can be read
+func (p *OggPage) headerComplete() bool {
+	return p.HeaderLen >= 27 && p.HeaderLen <= len(p.Header) && p.BodyLen >= 0 && p.BodyLen <= len(p.Body)
+}
+
+// check returns an error unless the page has a complete header with its
+// segment table and a body of the length the table gives
+func (p *OggPage) check() error {
+	if !p.headerComplete() || p.HeaderLen < 27+int(p.Header[26]) {
+		return errors.New("incomplete page header")
+	}
+	bodyLen := 0
+	for _, val := range p.Header[27 : 27+int(p.Header[26])] {
+		bodyLen += int(val)
+	}
+	if bodyLen != p.BodyLen {
+		return errors.New("page body length does not match the segment table")
+	}
+	return nil
+}
+
+// setFlag sets or clears a header type flag and updates the CRC
+func (p *OggPage) setFlag(flag byte, set bool) {
+	if len(p.Header) < 27 {
+		return
+	}
+	if set {
+		p.Header[5] |= flag
+	} else {
+		p.Header[5] &^= flag
+	}
+	p.ChecksumSet()
+}
+
+// SetContinued sets the continued packet flag and updates the CRC
+func (p *OggPage) SetContinued(continued bool) {
+	p.setFlag(0x01, continued)
+}
+
+// SetBOS sets the beginning of stream flag and updates the CRC
+func (p *OggPage) SetBOS(bos bool) {
+	p.setFlag(0x02, bos)
+}
+
+// SetEOS sets the end of stream flag and updates the CRC
+func (p *OggPage) SetEOS(eos bool) {
+	p.setFlag(0x04, eos)
+}
+
+// SetGranulepos sets the granule position and updates the CRC
+func (p *OggPage) SetGranulepos(granulepos int64) {
+	if len(p.Header) < 27 {
+		return
+	}
+	binary.LittleEndian.PutUint64(p.Header[6:14], uint64(granulepos))
+	p.ChecksumSet()
+}
+
+// SetSerialno sets the serial number and updates the CRC
+func (p *OggPage) SetSerialno(serialno int) {
+	if len(p.Header) < 27 {
+		return
+	}
+	binary.LittleEndian.PutUint32(p.Header[14:18], uint32(serialno))
+	p.ChecksumSet()
+}
+
+// SetPageno sets the page sequence number and updates the CRC
+func (p *OggPage) SetPageno(pageno int64) {
+	if len(p.Header) < 27 {
+		return
+	}
+	binary.LittleEndian.PutUint32(p.Header[18:22], uint32(pageno))
+	p.ChecksumSet()
+}
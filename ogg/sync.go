@@ -0,0 +1,83 @@
+package ogg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrGap is returned by PacketOut and PacketPeek when packets were lost
+// between pages, e.g. after a dropped or corrupt page. Reading may continue
+// with the next packet.
+var ErrGap = errors.New("gap in packet data")
+
+// SyncError is returned by OggSyncState.PageOut when bytes were skipped to
+// find the next page. Reading may continue with the next call to PageOut.
+type SyncError struct {
+	Skipped     int  // Number of bytes skipped
+	BadChecksum bool // The skipped bytes held a complete page with a wrong CRC
+}
+
+func (e *SyncError) Error() string {
+	if e.BadChecksum {
+		return fmt.Sprintf("skipped %d bytes including a page with a bad checksum", e.Skipped)
+	}
+	return fmt.Sprintf("skipped %d bytes of garbage", e.Skipped)
+}
+
+// PageOut extracts the next page from the buffered data. It returns 1 and
+// the page, or 0 when more data is needed. Bytes skipped before the page are
+// reported first as a *SyncError, and the page follows on the next call.
+func (s *OggSyncState) PageOut(page *OggPage) (int, error) {
+	if s.pending != nil {
+		*page = *s.pending
+		s.pending = nil
+		return 1, nil
+	}
+
+	var skipped *SyncError
+	for {
+		complete := completePage(s.buffered())
+		found := &OggPage{}
+		ret := s.PageSeek(found)
+		if ret > 0 {
+			if skipped != nil {
+				s.pending = found.Clone()
+				return 0, skipped
+			}
+			*page = *found
+			return 1, nil
+		}
+		if ret == 0 {
+			if skipped != nil {
+				return 0, skipped
+			}
+			return 0, nil
+		}
+		if skipped == nil {
+			skipped = &SyncError{}
+		}
+		skipped.Skipped += -ret
+		// A whole page starting with the capture pattern only fails its CRC
+		if complete {
+			skipped.BadChecksum = true
+		}
+	}
+}
+
+// completePage reports whether data starts with a capture pattern followed by
+// a complete header and body
+func completePage(data []byte) bool {
+	if len(data) < 27 || !bytes.HasPrefix(data, []byte("OggS")) {
+		return false
+	}
+	headerLen := 27 + int(data[26])
+	if len(data) < headerLen {
+		return false
+	}
+	size := headerLen
+	for _, val := range data[27:headerLen] {
+		size += int(val)
+	}
+	return len(data) >= size
+}
@@ -0,0 +1,244 @@
+// Package oggopus reads Opus streams encapsulated in Ogg (RFC 7845)
+package oggopus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/justa-cai/go-libopus/ogg"
+)
+
+// SampleRate is the rate granule positions are expressed in
+const SampleRate = 48000
+
+const (
+	// preRoll is the amount of audio decoded before a seek target (RFC 7845 section 4.6)
+	preRoll = 3840
+	// maxPacketDuration is the longest duration of a single Opus packet (120 ms)
+	maxPacketDuration = 5760
+)
+
+// Header represents the OpusHead identification header
+type Header struct {
+	Version         int    // Version number, must be 1
+	Channels        int    // Output channel count
+	PreSkip         int    // Samples to discard from the decoder output at 48 kHz
+	InputSampleRate int    // Original input sample rate, informational only
+	OutputGain      int    // Output gain in Q7.8 dB
+	MappingFamily   int    // Channel mapping family
+	StreamCount     int    // Number of Opus streams, 1 for family 0
+	CoupledCount    int    // Number of stereo streams
+	ChannelMapping  []byte // Output channel to stream mapping, empty for family 0
+}
+
+// ParseHeader parses an OpusHead packet
+func ParseHeader(data []byte) (*Header, error) {
+	if len(data) < 19 || !bytes.Equal(data[0:8], []byte("OpusHead")) {
+		return nil, errors.New("not an OpusHead packet")
+	}
+	h := &Header{
+		Version:         int(data[8]),
+		Channels:        int(data[9]),
+		PreSkip:         int(binary.LittleEndian.Uint16(data[10:12])),
+		InputSampleRate: int(binary.LittleEndian.Uint32(data[12:16])),
+		OutputGain:      int(int16(binary.LittleEndian.Uint16(data[16:18]))),
+		MappingFamily:   int(data[18]),
+	}
+	if h.Version>>4 != 0 {
+		return nil, errors.New("unsupported OpusHead version")
+	}
+	if h.Channels == 0 {
+		return nil, errors.New("invalid channel count")
+	}
+	if h.MappingFamily == 0 {
+		if h.Channels > 2 {
+			return nil, errors.New("invalid channel count for mapping family 0")
+		}
+		h.StreamCount = 1
+		h.CoupledCount = h.Channels - 1
+		return h, nil
+	}
+	if len(data) < 21+h.Channels {
+		return nil, errors.New("truncated channel mapping table")
+	}
+	h.StreamCount = int(data[19])
+	h.CoupledCount = int(data[20])
+	if h.StreamCount == 0 || h.CoupledCount > h.StreamCount {
+		return nil, errors.New("invalid stream count")
+	}
+	h.ChannelMapping = append([]byte(nil), data[21:21+h.Channels]...)
+	return h, nil
+}
+
+// Bytes serializes the header as an OpusHead packet
+func (h *Header) Bytes() []byte {
+	size := 19
+	if h.MappingFamily != 0 {
+		size += 2 + len(h.ChannelMapping)
+	}
+	data := make([]byte, size)
+	copy(data[0:8], "OpusHead")
+	data[8] = byte(h.Version)
+	data[9] = byte(h.Channels)
+	binary.LittleEndian.PutUint16(data[10:12], uint16(h.PreSkip))
+	binary.LittleEndian.PutUint32(data[12:16], uint32(h.InputSampleRate))
+	binary.LittleEndian.PutUint16(data[16:18], uint16(int16(h.OutputGain)))
+	data[18] = byte(h.MappingFamily)
+	if h.MappingFamily != 0 {
+		data[19] = byte(h.StreamCount)
+		data[20] = byte(h.CoupledCount)
+		copy(data[21:], h.ChannelMapping)
+	}
+	return data
+}
+
+// headers describes the header packets at the start of a stream
+type headers struct {
+	head      *Header
+	serial    int
+	dataStart int64 // offset of the first page after the header packets
+	pages     int   // number of pages holding the header packets
+}
+
+// readHeaders reads the OpusHead and OpusTags packets from the start of the input
+func readHeaders(s *pageScanner) (*headers, error) {
+	if err := s.reset(0); err != nil {
+		return nil, err
+	}
+	page := &ogg.OggPage{}
+	if _, err := s.next(page); err != nil {
+		if err == io.EOF {
+			return nil, errors.New("no Ogg page found")
+		}
+		return nil, err
+	}
+	if !page.BOS() {
+		return nil, errors.New("first page is not a beginning of stream page")
+	}
+	h := &headers{serial: page.Serialno()}
+	stream, err := ogg.NewOggStreamState(h.serial)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Clear()
+	stream.SetZeroCopy(true)
+
+	packets := 0
+	for {
+		if page.Serialno() == h.serial {
+			h.pages++
+			if err := stream.PageIn(page); err != nil {
+				return nil, err
+			}
+			for packets < 2 {
+				packet := &ogg.OggPacket{}
+				ret, err := stream.PacketOut(packet)
+				if err != nil {
+					return nil, errors.New("missing data in header packets")
+				}
+				if ret == 0 {
+					break
+				}
+				if packets == 0 {
+					h.head, err = ParseHeader(packet.Packet)
+					if err != nil {
+						return nil, err
+					}
+				} else if !bytes.HasPrefix(packet.Packet, []byte("OpusTags")) {
+					return nil, errors.New("missing OpusTags packet")
+				}
+				packets++
+			}
+		}
+		if packets == 2 {
+			break
+		}
+		if _, err := s.next(page); err != nil {
+			if err == io.EOF {
+				return nil, errors.New("unexpected end of file in header packets")
+			}
+			return nil, err
+		}
+	}
+	h.dataStart = s.offset
+	return h, nil
+}
+
+// pageScanner reads pages from an io.ReadSeeker and reports the byte offset
+// at which each page starts. Pages are validated by OggSyncState, which
+// resynchronises on the next capture pattern after a seek or corrupt data.
+type pageScanner struct {
+	r      io.ReadSeeker
+	sync   *ogg.OggSyncState
+	chunk  []byte
+	offset int64 // file offset of the first byte in the sync state not yet returned or skipped
+}
+
+func newPageScanner(r io.ReadSeeker) (*pageScanner, error) {
+	sync, err := ogg.NewOggSyncState()
+	if err != nil {
+		return nil, err
+	}
+	// Pages are consumed before the next one is read
+	sync.SetZeroCopy(true)
+	return &pageScanner{r: r, sync: sync, chunk: make([]byte, 4096)}, nil
+}
+
+// reset discards buffered data and continues scanning at offset
+func (s *pageScanner) reset(offset int64) error {
+	if _, err := s.r.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.sync.Reset(); err != nil {
+		return err
+	}
+	s.offset = offset
+	return nil
+}
+
+// next returns the next page and the offset of its first byte. The page
+// data is only valid until the following call to next.
+func (s *pageScanner) next(page *ogg.OggPage) (int64, error) {
+	for {
+		// PageSeek reports the bytes of each page returned or skipped, which
+		// keeps the offset exact
+		ret := s.sync.PageSeek(page)
+		if ret < 0 {
+			s.offset -= int64(ret)
+			continue
+		}
+		if ret > 0 {
+			start := s.offset
+			s.offset += int64(ret)
+			return start, nil
+		}
+
+		n, err := s.r.Read(s.chunk)
+		if n > 0 {
+			buffer, berr := s.sync.Buffer(n)
+			if berr != nil {
+				return 0, berr
+			}
+			copy(buffer, s.chunk[:n])
+			if werr := s.sync.Wrote(n); werr != nil {
+				return 0, werr
+			}
+		}
+		if err == io.EOF && n == 0 {
+			return 0, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+}
+
+// close releases the sync state
+func (s *pageScanner) close() {
+	if s.sync != nil {
+		s.sync.Clear()
+		s.sync = nil
+	}
+}
@@ -0,0 +1,409 @@
+package oggopus_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/ogg"
+	"github.com/justa-cai/go-libopus/oggopus"
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+const (
+	testFrameSize = 960 // 20ms at 48kHz
+	testPreSkip   = 312
+)
+
+// testSignal generates a chirp so misaligned samples are easy to detect
+func testSignal(samples int) []int16 {
+	data := make([]int16, samples)
+	phase := 0.0
+	for i := range data {
+		freq := 200 + 2000*float64(i)/float64(samples)
+		phase += 2 * math.Pi * freq / oggopus.SampleRate
+		data[i] = int16(math.Sin(phase) * 16000)
+	}
+	return data
+}
+
+// writePages drains pages from the stream into w
+func writePages(t *testing.T, stream *ogg.OggStreamState, w io.Writer, flush bool) {
+	for {
+		page := &ogg.OggPage{}
+		var ret int
+		var err error
+		if flush {
+			ret, err = stream.Flush(page)
+		} else {
+			ret, err = stream.PageOut(page)
+		}
+		if err != nil {
+			t.Fatalf("Failed to get page: %v", err)
+		}
+		if ret == 0 {
+			return
+		}
+		w.Write(page.Header)
+		w.Write(page.Body)
+	}
+}
+
+// encodeOggOpus encodes mono PCM into an in-memory Ogg Opus file
+func encodeOggOpus(t *testing.T, pcm []int16) []byte {
+	return encodeOggOpusGain(t, pcm, 0)
+}
+
+// encodeOggOpusGain encodes mono PCM into an in-memory Ogg Opus file whose
+// header has outputGain
+func encodeOggOpusGain(t *testing.T, pcm []int16, outputGain int) []byte {
+	encoder, err := opus.NewEncoder(oggopus.SampleRate, 1, opus.OpusApplicationAudio)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+
+	stream, err := ogg.NewOggStreamState(1234)
+	if err != nil {
+		t.Fatalf("Failed to create ogg stream: %v", err)
+	}
+	defer stream.Clear()
+
+	var out bytes.Buffer
+	head := &oggopus.Header{Version: 1, Channels: 1, PreSkip: testPreSkip, InputSampleRate: oggopus.SampleRate, OutputGain: outputGain}
+	if err := stream.PacketIn(&ogg.OggPacket{Packet: head.Bytes(), Bytes: 19, BOS: 1}); err != nil {
+		t.Fatalf("Failed to add header packet: %v", err)
+	}
+	writePages(t, stream, &out, true)
+	tags := []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00")
+	if err := stream.PacketIn(&ogg.OggPacket{Packet: tags, Bytes: len(tags), Packetno: 1}); err != nil {
+		t.Fatalf("Failed to add tags packet: %v", err)
+	}
+	writePages(t, stream, &out, true)
+
+	// One extra frame flushes the encoder lookahead
+	frames := (len(pcm)+testPreSkip)/testFrameSize + 1
+	frame := make([]byte, testFrameSize*2)
+	encoded := make([]byte, 4000)
+	for i := 0; i < frames; i++ {
+		for j := 0; j < testFrameSize; j++ {
+			var s int16
+			if k := i*testFrameSize + j; k < len(pcm) {
+				s = pcm[k]
+			}
+			binary.LittleEndian.PutUint16(frame[j*2:], uint16(s))
+		}
+		n, err := encoder.Encode(frame, encoded)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		last := i == frames-1
+		granule := int64((i + 1) * testFrameSize)
+		if last {
+			granule = int64(len(pcm) + testPreSkip)
+		}
+		packet := &ogg.OggPacket{
+			Packet:     encoded[:n],
+			Bytes:      n,
+			Granulepos: granule,
+			Packetno:   int64(i + 2),
+		}
+		if last {
+			packet.EOS = 1
+		}
+		if err := stream.PacketIn(packet); err != nil {
+			t.Fatalf("Failed to add packet: %v", err)
+		}
+		// Small pages give the seek bisection something to work with
+		writePages(t, stream, &out, i%5 == 4 || last)
+	}
+	return out.Bytes()
+}
+
+// readAll reads up to n samples from the reader
+func readAll(t *testing.T, r *oggopus.Reader, n int) []int16 {
+	data := make([]byte, n*2)
+	got, err := io.ReadFull(r, data)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	samples := make([]int16, got/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return samples
+}
+
+func TestParseHeader(t *testing.T) {
+	head := &oggopus.Header{Version: 1, Channels: 2, PreSkip: 312, InputSampleRate: 44100, OutputGain: -256}
+	parsed, err := oggopus.ParseHeader(head.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to parse header: %v", err)
+	}
+	if parsed.Channels != 2 || parsed.PreSkip != 312 || parsed.InputSampleRate != 44100 || parsed.OutputGain != -256 {
+		t.Errorf("Header mismatch: %+v", parsed)
+	}
+	if parsed.StreamCount != 1 || parsed.CoupledCount != 1 {
+		t.Errorf("Unexpected stream counts: %d/%d", parsed.StreamCount, parsed.CoupledCount)
+	}
+
+	if _, err := oggopus.ParseHeader([]byte("OpusTags")); err == nil {
+		t.Error("Expected error for non OpusHead packet")
+	}
+}
+
+func TestReaderLength(t *testing.T) {
+	pcm := testSignal(oggopus.SampleRate*2 + 123)
+	data := encodeOggOpus(t, pcm)
+
+	r, err := oggopus.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	defer r.Close()
+
+	if r.Head.PreSkip != testPreSkip {
+		t.Errorf("Expected pre-skip %d, got %d", testPreSkip, r.Head.PreSkip)
+	}
+	decoded := readAll(t, r, len(pcm)+10000)
+	if len(decoded) != len(pcm) {
+		t.Errorf("Expected %d samples, got %d", len(pcm), len(decoded))
+	}
+}
+
+func TestReaderSeek(t *testing.T) {
+	pcm := testSignal(oggopus.SampleRate * 10)
+	data := encodeOggOpus(t, pcm)
+
+	r, err := oggopus.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	defer r.Close()
+	full := readAll(t, r, len(pcm))
+
+	for _, target := range []int{0, 1, 4000, 12345, oggopus.SampleRate*3 + 17, oggopus.SampleRate * 7, len(pcm) - 500} {
+		if err := r.SeekSample(int64(target)); err != nil {
+			t.Fatalf("Failed to seek to %d: %v", target, err)
+		}
+		want := full[target:min(target+2000, len(full))]
+		got := readAll(t, r, len(want))
+		if len(got) != len(want) {
+			t.Fatalf("Seek to %d: expected %d samples, got %d", target, len(want), len(got))
+		}
+
+		// After the pre-roll the decoder output must match sequential decoding
+		var signal, noise float64
+		for i := range want {
+			d := float64(got[i]) - float64(want[i])
+			signal += float64(want[i]) * float64(want[i])
+			noise += d * d
+		}
+		if snr := 10 * math.Log10(signal/(noise+1)); snr < 40 {
+			t.Errorf("Seek to %d: output does not match sequential decode (SNR %.1f dB)", target, snr)
+		}
+	}
+
+	// Relative seeks count from the samples read and from the end
+	if _, err := r.Seek(1000, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v", err)
+	}
+	readAll(t, r, 500)
+	if pos, err := r.Seek(-1500, io.SeekCurrent); err != nil || pos != 0 {
+		t.Errorf("Expected position 0, got %d (%v)", pos, err)
+	}
+	if got := readAll(t, r, 10); got[0] != full[0] {
+		t.Errorf("Expected sample %d after relative seek, got %d", full[0], got[0])
+	}
+	if pos, err := r.Seek(-100, io.SeekEnd); err != nil || pos != int64(len(pcm)-100) {
+		t.Errorf("Expected position %d, got %d (%v)", len(pcm)-100, pos, err)
+	}
+	if got := readAll(t, r, 1000); len(got) != 100 {
+		t.Errorf("Expected the last 100 samples, got %d", len(got))
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Error("Expected error for a negative position")
+	}
+
+	if err := r.SeekSample(int64(len(pcm) + 1000)); err != nil {
+		t.Fatalf("Failed to seek past the end: %v", err)
+	}
+	if _, err := r.Read(make([]byte, 100)); err != io.EOF {
+		t.Errorf("Expected EOF after seeking past the end, got %v", err)
+	}
+}
+
+func TestReaderOutputGain(t *testing.T) {
+	pcm := testSignal(oggopus.SampleRate)
+	level := func(outputGain int) float64 {
+		r, err := oggopus.NewReader(bytes.NewReader(encodeOggOpusGain(t, pcm, outputGain)))
+		if err != nil {
+			t.Fatalf("Failed to create reader: %v", err)
+		}
+		defer r.Close()
+		// After a seek too, as the decoder is recreated
+		if err := r.SeekSample(4800); err != nil {
+			t.Fatalf("Failed to seek: %v", err)
+		}
+		var power float64
+		for _, v := range readAll(t, r, len(pcm)) {
+			power += float64(v) * float64(v)
+		}
+		return 10 * math.Log10(power)
+	}
+
+	// Q7.8 dB: -6 dB
+	if diff := level(-6*256) - level(0); math.Abs(diff+6) > 0.2 {
+		t.Errorf("Expected output gain of -6 dB, got %.2f dB", diff)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	pcm := testSignal(oggopus.SampleRate*3 + 77)
+	data := encodeOggOpus(t, pcm)
+
+	info, err := oggopus.Probe(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to probe: %v", err)
+	}
+	if info.Samples != int64(len(pcm)) {
+		t.Errorf("Expected %d samples, got %d", len(pcm), info.Samples)
+	}
+	if info.Duration.Milliseconds() != int64(len(pcm))*1000/oggopus.SampleRate {
+		t.Errorf("Unexpected duration %v", info.Duration)
+	}
+	frames := (len(pcm)+testPreSkip)/testFrameSize + 1
+	if info.Packets != frames {
+		t.Errorf("Expected %d packets, got %d", frames, info.Packets)
+	}
+	if info.FrameSizes[testFrameSize] != frames || len(info.FrameSizes) != 1 {
+		t.Errorf("Unexpected frame size distribution: %v", info.FrameSizes)
+	}
+	if info.Pages < frames/5 {
+		t.Errorf("Expected at least %d pages, got %d", frames/5, info.Pages)
+	}
+	if info.AvgBitrate <= 0 || info.MinBitrate > info.AvgBitrate || info.MaxBitrate < info.AvgBitrate {
+		t.Errorf("Inconsistent bitrates: min %.0f avg %.0f max %.0f", info.MinBitrate, info.AvgBitrate, info.MaxBitrate)
+	}
+}
+
+func TestReaderConcealsLostPages(t *testing.T) {
+	pcm := testSignal(oggopus.SampleRate * 3)
+	data := encodeOggOpus(t, pcm)
+
+	r, err := oggopus.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	full := readAll(t, r, len(pcm))
+	r.Close()
+
+	// Drop one audio page and corrupt the CRC of another, 100 ms each
+	pages, err := ogg.NewPageReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create page reader: %v", err)
+	}
+	defer pages.Close()
+	var damaged bytes.Buffer
+	for i := 0; ; i++ {
+		page, err := pages.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read page: %v", err)
+		}
+		switch i {
+		case 7:
+			continue
+		case 15:
+			page.Body[10] ^= 0xff
+		}
+		damaged.Write(page.Header)
+		damaged.Write(page.Body)
+	}
+
+	r, err = oggopus.NewReader(bytes.NewReader(damaged.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	defer r.Close()
+	got := readAll(t, r, len(pcm)+10000)
+	if len(got) != len(full) {
+		t.Fatalf("Expected lost pages to be concealed to %d samples, got %d", len(full), len(got))
+	}
+
+	// Output after the last gap is back in step with the undamaged stream
+	tail := oggopus.SampleRate * 2
+	var signal, noise float64
+	for i := tail; i < len(full); i++ {
+		d := float64(got[i]) - float64(full[i])
+		signal += float64(full[i]) * float64(full[i])
+		noise += d * d
+	}
+	if snr := 10 * math.Log10(signal/(noise+1)); snr < 20 {
+		t.Errorf("Output after concealment is misaligned (SNR %.1f dB)", snr)
+	}
+}
+
+func TestReaderSeekSkipsStrayHeaders(t *testing.T) {
+	pcm := testSignal(oggopus.SampleRate * 5)
+	data := encodeOggOpus(t, pcm)
+
+	r, err := oggopus.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	full := readAll(t, r, len(pcm))
+	r.Close()
+
+	// Put a stray copy of every third audio page header in front of the page,
+	// so the same header bytes appear before the real page
+	pages, err := ogg.NewPageReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create page reader: %v", err)
+	}
+	defer pages.Close()
+	var stray bytes.Buffer
+	for i := 0; ; i++ {
+		page, err := pages.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read page: %v", err)
+		}
+		if i >= 2 && i%3 == 0 {
+			stray.Write(page.Header)
+		}
+		stray.Write(page.Header)
+		stray.Write(page.Body)
+	}
+
+	r, err = oggopus.NewReader(bytes.NewReader(stray.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	defer r.Close()
+	for _, target := range []int{4000, oggopus.SampleRate*2 + 17, oggopus.SampleRate * 4} {
+		if err := r.SeekSample(int64(target)); err != nil {
+			t.Fatalf("Failed to seek to %d: %v", target, err)
+		}
+		want := full[target : target+2000]
+		got := readAll(t, r, len(want))
+		if len(got) != len(want) {
+			t.Fatalf("Seek to %d: expected %d samples, got %d", target, len(want), len(got))
+		}
+		var signal, noise float64
+		for i := range want {
+			d := float64(got[i]) - float64(want[i])
+			signal += float64(want[i]) * float64(want[i])
+			noise += d * d
+		}
+		if snr := 10 * math.Log10(signal/(noise+1)); snr < 40 {
+			t.Errorf("Seek to %d: output does not match sequential decode (SNR %.1f dB)", target, snr)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package oggopus
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/justa-cai/go-libopus/ogg"
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// Info describes an Ogg Opus stream as reported by Probe
+type Info struct {
+	Head       *Header
+	Samples    int64         // Playback length in samples per channel at 48 kHz
+	Duration   time.Duration // Playback length
+	AvgBitrate float64       // Average bitrate of the audio packets in bits per second
+	MinBitrate float64       // Lowest bitrate of a single audio packet
+	MaxBitrate float64       // Highest bitrate of a single audio packet
+	Pages      int           // Number of pages in the stream, including header pages
+	Packets    int           // Number of audio packets
+	FrameSizes map[int]int   // Number of Opus frames by frame size in samples at 48 kHz
+}
+
+// Probe reads the headers of an Ogg Opus stream and collects its duration
+// and packet statistics from the page and packet headers, without decoding.
+// The duration is exact: it is derived from the granule position of the last
+// page, which is found by scanning backwards from the end of the input.
+func Probe(rs io.ReadSeeker) (*Info, error) {
+	scanner, err := newPageScanner(rs)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.close()
+
+	h, err := readHeaders(scanner)
+	if err != nil {
+		return nil, err
+	}
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	last, err := lastGranule(scanner, h.serial, h.dataStart, end)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Head: h.head, Pages: h.pages, FrameSizes: make(map[int]int)}
+	start, size, samples, err := scanPackets(scanner, h, info)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Samples = last - start - int64(h.head.PreSkip)
+	if info.Samples < 0 {
+		info.Samples = 0
+	}
+	info.Duration = time.Duration(info.Samples) * time.Second / SampleRate
+	if samples > 0 {
+		info.AvgBitrate = float64(size) * 8 * SampleRate / float64(samples)
+	}
+	return info, nil
+}
+
+// scanPackets walks the audio pages of the stream and fills in the page and
+// packet statistics of info. It returns the granule position at which the
+// first audio packet starts, the total size of the audio packets and their
+// total duration before end trimming.
+func scanPackets(s *pageScanner, h *headers, info *Info) (start, size, samples int64, err error) {
+	if err := s.reset(h.dataStart); err != nil {
+		return 0, 0, 0, err
+	}
+	stream, err := ogg.NewOggStreamState(h.serial)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer stream.Clear()
+	stream.SetZeroCopy(true)
+
+	start = -1
+	page := &ogg.OggPage{}
+	for {
+		if _, err := s.next(page); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, 0, 0, err
+		}
+		if page.Serialno() != h.serial {
+			continue
+		}
+		info.Pages++
+		if err := stream.PageIn(page); err != nil {
+			return 0, 0, 0, err
+		}
+
+		var duration int64
+		for {
+			packet := &ogg.OggPacket{}
+			ret, err := stream.PacketOut(packet)
+			if err != nil {
+				// Skip over holes in the data
+				continue
+			}
+			if ret == 0 {
+				break
+			}
+			if len(packet.Packet) == 0 {
+				continue
+			}
+			n, err := opus.PacketSamples(packet.Packet, SampleRate)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			frames, err := opus.PacketFrames(packet.Packet)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			frameSize, err := opus.PacketSamplesPerFrame(packet.Packet, SampleRate)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			info.FrameSizes[frameSize] += frames
+
+			rate := float64(len(packet.Packet)) * 8 * SampleRate / float64(n)
+			if info.Packets == 0 || rate < info.MinBitrate {
+				info.MinBitrate = rate
+			}
+			if rate > info.MaxBitrate {
+				info.MaxBitrate = rate
+			}
+			info.Packets++
+			size += int64(len(packet.Packet))
+			duration += int64(n)
+		}
+		samples += duration
+
+		if start < 0 && duration > 0 {
+			if page.EOS() {
+				// The EOS granule may trim the end, so it cannot locate the start
+				start = 0
+			} else {
+				start = page.Granulepos() - duration
+			}
+		}
+		if page.EOS() {
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	return start, size, samples, nil
+}
+
+// lastGranule returns the granule position of the last page of the stream
+// with the given serial number, reading backwards from end in growing steps
+func lastGranule(s *pageScanner, serial int, dataStart, end int64) (int64, error) {
+	page := &ogg.OggPage{}
+	for step := int64(65536); ; step *= 2 {
+		from := end - step
+		if from < dataStart {
+			from = dataStart
+		}
+		if err := s.reset(from); err != nil {
+			return 0, err
+		}
+
+		granule := int64(-1)
+		for {
+			if _, err := s.next(page); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return 0, err
+			}
+			if page.Serialno() != serial {
+				continue
+			}
+			if g := page.Granulepos(); g != -1 {
+				granule = g
+			}
+			if page.EOS() {
+				break
+			}
+		}
+		if granule != -1 {
+			return granule, nil
+		}
+		if from == dataStart {
+			return 0, errors.New("no audio pages found")
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package oggopus
+
+import (
+	"errors"
+	"io"
+
+	"github.com/justa-cai/go-libopus/ogg"
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// Reader decodes an Ogg Opus stream into interleaved 16-bit little endian PCM
+// at 48 kHz. Sample positions used by Seek are counted from the first sample
+// after the pre-skip. The output gain of the header is applied.
+type Reader struct {
+	Head *Header // Identification header of the stream
+
+	rs        io.ReadSeeker
+	scanner   *pageScanner
+	stream    *ogg.OggStreamState
+	decoder   *opus.OpusDecoder
+	serial    int
+	dataStart int64 // offset of the first page after the header packets
+	end       int64 // size of the input
+
+	packets    []*queuedPacket // packets completed on pages read so far
+	pos        int64           // granule position of the start of packets[0], -1 if unknown
+	next       int64           // granule position of the end of the queued packets, -1 if unknown
+	gap        *queuedPacket   // gap whose length awaits the next page granule position
+	gapStart   int64           // granule position of the start of gap
+	afterGap   int64           // duration of the packets queued after gap
+	skipTo     int64           // decoded samples before this granule position are dropped
+	endGranule int64           // granule position of the EOS page, -1 if not yet seen
+	fromStart  bool            // decoding started at dataStart
+	eos        bool
+
+	buf  []byte // decoder output
+	pcm  []byte // decoded samples not yet returned by Read
+	read int64  // bytes returned by Read since the start or the last seek
+	base int64  // sample position of the last seek
+}
+
+// queuedPacket is an Opus packet waiting to be decoded, or a gap of lost
+// packets to conceal when data is nil
+type queuedPacket struct {
+	data    []byte
+	samples int64 // duration in samples at 48 kHz
+}
+
+// NewReader reads the Ogg Opus headers from rs and prepares the decoder
+func NewReader(rs io.ReadSeeker) (*Reader, error) {
+	scanner, err := newPageScanner(rs)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{rs: rs, scanner: scanner}
+	h, err := readHeaders(scanner)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	r.Head, r.serial, r.dataStart = h.head, h.serial, h.dataStart
+	if r.Head.StreamCount > 1 {
+		r.Close()
+		return nil, errors.New("multistream Ogg Opus files are not supported")
+	}
+
+	r.end, err = rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	r.buf = make([]byte, maxPacketDuration*r.Head.Channels*2)
+	if err := r.restart(r.dataStart, int64(r.Head.PreSkip)); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// restart resets the demuxer and decoder to continue reading at offset,
+// discarding decoded samples before the granule position skipTo
+func (r *Reader) restart(offset int64, skipTo int64) error {
+	if err := r.scanner.reset(offset); err != nil {
+		return err
+	}
+	if r.stream == nil {
+		stream, err := ogg.NewOggStreamState(r.serial)
+		if err != nil {
+			return err
+		}
+		r.stream = stream
+	} else if err := r.stream.Reset(); err != nil {
+		return err
+	}
+
+	// Recreate the decoder so no state from before the seek leaks into the output
+	if r.decoder != nil {
+		r.decoder.Close()
+	}
+	var err error
+	r.decoder, err = opus.NewDecoder(SampleRate, r.Head.Channels)
+	if err != nil {
+		return err
+	}
+	// RFC 7845 section 5.1: players must apply the output gain
+	if r.Head.OutputGain != 0 {
+		if err := r.decoder.CtlInt(opus.OPUS_SET_GAIN_REQUEST, r.Head.OutputGain); err != nil {
+			return err
+		}
+	}
+
+	r.packets = r.packets[:0]
+	r.pcm = nil
+	r.pos = -1
+	r.next = -1
+	r.gap = nil
+	r.skipTo = skipTo
+	r.endGranule = -1
+	r.fromStart = offset == r.dataStart
+	r.eos = false
+	return nil
+}
+
+// readPage reads pages until at least one packet of the stream is complete
+// and the length of any gap in the data is known
+func (r *Reader) readPage() error {
+	page := &ogg.OggPage{}
+	for len(r.packets) == 0 || (r.gap != nil && !r.eos) {
+		if r.eos {
+			return io.EOF
+		}
+		if _, err := r.scanner.next(page); err != nil {
+			if err == io.EOF {
+				r.eos = true
+				if len(r.packets) > 0 {
+					return nil
+				}
+			}
+			return err
+		}
+		if page.Serialno() != r.serial {
+			continue
+		}
+		if err := r.stream.PageIn(page); err != nil {
+			return err
+		}
+
+		var duration int64
+		for {
+			packet := &ogg.OggPacket{}
+			ret, err := r.stream.PacketOut(packet)
+			if err == ogg.ErrGap {
+				// Packets were lost; once a granule position locates the
+				// packets after them, the gap is filled by concealment
+				if r.next >= 0 && r.gap == nil {
+					r.gap = &queuedPacket{}
+					r.gapStart = r.next + duration
+					r.afterGap = 0
+					r.packets = append(r.packets, r.gap)
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if ret == 0 {
+				break
+			}
+			if len(packet.Packet) == 0 {
+				continue
+			}
+			n, err := opus.PacketSamples(packet.Packet, SampleRate)
+			if err != nil {
+				return err
+			}
+			duration += int64(n)
+			if r.gap != nil {
+				r.afterGap += int64(n)
+			}
+			r.packets = append(r.packets, &queuedPacket{data: packet.Packet, samples: int64(n)})
+		}
+
+		granule := page.Granulepos()
+		if granule >= 0 {
+			if r.gap != nil {
+				if lost := granule - r.afterGap - r.gapStart; lost > 0 {
+					r.gap.samples = lost
+				}
+				r.gap = nil
+			}
+			r.next = granule
+		} else if r.next >= 0 {
+			r.next += duration
+		}
+		if page.EOS() {
+			r.eos = true
+			r.endGranule = granule
+		}
+		if r.pos < 0 && len(r.packets) > 0 {
+			if page.EOS() && r.fromStart {
+				// The EOS granule may trim the end, so it cannot locate the start
+				r.pos = 0
+			} else {
+				r.pos = granule - duration
+			}
+		}
+	}
+	return nil
+}
+
+// decodePacket decodes the next packet into r.pcm, trimming samples outside
+// the range selected by pre-skip, seeking and end trimming
+func (r *Reader) decodePacket() error {
+	if len(r.packets) == 0 {
+		if err := r.readPage(); err != nil {
+			return err
+		}
+	}
+	packet := r.packets[0]
+	var n int
+	if packet.data == nil {
+		// Conceal lost packets, at most one maximum packet duration at a time
+		n = int(min(packet.samples, maxPacketDuration))
+		packet.samples -= int64(n)
+		if packet.samples == 0 {
+			r.packets = r.packets[1:]
+		}
+		if n == 0 {
+			r.pcm = nil
+			return nil
+		}
+		// Concealment works in multiples of 2.5 ms
+		if _, err := r.decoder.DecodePLC(r.buf, (n+119)/120*120); err != nil {
+			return err
+		}
+	} else {
+		r.packets = r.packets[1:]
+		var err error
+		n, err = r.decoder.Decode(packet.data, r.buf)
+		if err != nil {
+			return err
+		}
+	}
+	start := r.pos
+	r.pos += int64(n)
+
+	lo, hi := start, start+int64(n)
+	if lo < r.skipTo {
+		lo = r.skipTo
+	}
+	if r.endGranule >= 0 && len(r.packets) == 0 && hi > r.endGranule {
+		hi = r.endGranule
+	}
+	frameBytes := int64(2 * r.Head.Channels)
+	if hi > lo {
+		r.pcm = r.buf[(lo-start)*frameBytes : (hi-start)*frameBytes]
+	} else {
+		r.pcm = nil
+	}
+	return nil
+}
+
+// Read reads decoded PCM into p
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.pcm) == 0 {
+		if err := r.decodePacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pcm)
+	r.pcm = r.pcm[n:]
+	r.read += int64(n)
+	return n, nil
+}
+
+// Seek positions the reader so the next Read returns the sample at offset,
+// relative to whence as for io.Seeker, and returns the new position. Unlike
+// io.Seeker, positions are counted in samples per channel at 48 kHz from the
+// end of the pre-skip, not in bytes. The page to resume from is found by
+// bisection on page granule positions, and at least 80 ms of audio before
+// the target is decoded and discarded so the decoder has converged when
+// output starts.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	sample := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		sample += r.base + r.read/int64(2*r.Head.Channels)
+	case io.SeekEnd:
+		last, err := lastGranule(r.scanner, r.serial, r.dataStart, r.end)
+		if err != nil {
+			return 0, err
+		}
+		sample += last - int64(r.Head.PreSkip)
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if sample < 0 {
+		return 0, errors.New("invalid seek position")
+	}
+	if err := r.seek(sample); err != nil {
+		return 0, err
+	}
+	r.base, r.read = sample, 0
+	return sample, nil
+}
+
+// SeekSample positions the reader at sample like Seek from io.SeekStart
+func (r *Reader) SeekSample(sample int64) error {
+	_, err := r.Seek(sample, io.SeekStart)
+	return err
+}
+
+// seek positions the reader at sample
+func (r *Reader) seek(sample int64) error {
+	target := sample + int64(r.Head.PreSkip)
+
+	// Resuming after a page ending at or before limit guarantees the pre-roll
+	// even when the first packet on the next page is a continued one
+	limit := target - preRoll - maxPacketDuration
+	offset := r.dataStart
+	if limit > 0 {
+		var err error
+		offset, err = r.bisect(limit)
+		if err != nil {
+			return err
+		}
+	}
+	if err := r.restart(offset, target); err != nil {
+		return err
+	}
+
+	for len(r.pcm) == 0 {
+		if err := r.decodePacket(); err != nil {
+			if err == io.EOF {
+				// Seeking past the end leaves the reader at EOF
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// bisect returns the end offset of the last page whose granule position is at
+// most limit, or dataStart if there is no such page
+func (r *Reader) bisect(limit int64) (int64, error) {
+	best := r.dataStart
+	lo, hi := r.dataStart, r.end
+	page := &ogg.OggPage{}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if err := r.scanner.reset(mid); err != nil {
+			return 0, err
+		}
+
+		// Find the first page of this stream with a granule position after mid
+		found := false
+		var granule int64
+		for {
+			start, err := r.scanner.next(page)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return 0, err
+			}
+			if start >= hi {
+				break
+			}
+			granule = page.Granulepos()
+			if page.Serialno() == r.serial && granule != -1 {
+				found = true
+				break
+			}
+		}
+
+		if found && granule <= limit {
+			best = r.scanner.offset
+			lo = r.scanner.offset
+		} else {
+			hi = mid
+		}
+	}
+	return best, nil
+}
+
+// Close releases the decoder and the Ogg state
+func (r *Reader) Close() {
+	if r.decoder != nil {
+		r.decoder.Close()
+		r.decoder = nil
+	}
+	if r.stream != nil {
+		r.stream.Clear()
+		r.stream = nil
+	}
+	if r.scanner != nil {
+		r.scanner.close()
+		r.scanner = nil
+	}
+}
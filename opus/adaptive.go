@@ -0,0 +1,328 @@
+package opus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Feedback is a sample of receiver feedback, such as an RTCP receiver
+// report loss fraction and a bandwidth estimate
+type Feedback struct {
+	Time         time.Time     // time of the sample, now if zero
+	Bandwidth    int           // estimated available bitrate in bits per second, 0 if unknown
+	LossFraction float64       // fraction of packets lost, 0 to 1
+	RTT          time.Duration // round-trip time, 0 if unknown
+}
+
+// EncoderSettings are the encoder settings an AdaptiveController manages
+type EncoderSettings struct {
+	Bitrate        int           // bits per second
+	PacketLossPerc int           // expected packet loss in percent, sizing the in-band FEC
+	InbandFEC      bool          // in-band FEC
+	FrameDuration  time.Duration // duration of the frames to pass to Encode
+}
+
+// Policy decides the encoder settings for smoothed feedback. It is given the
+// settings in effect, to keep them within its own hysteresis.
+type Policy interface {
+	Settings(feedback Feedback, current EncoderSettings) EncoderSettings
+}
+
+// BitratePolicy is a Policy that can also decide the other settings for a
+// given bitrate, which the controller uses when it holds the bitrate back
+// from the one proposed
+type BitratePolicy interface {
+	Policy
+	SettingsForBitrate(feedback Feedback, current EncoderSettings, bitrate int) EncoderSettings
+}
+
+// PolicyFunc adapts a function to the Policy interface
+type PolicyFunc func(feedback Feedback, current EncoderSettings) EncoderSettings
+
+// Settings implements Policy
+func (f PolicyFunc) Settings(feedback Feedback, current EncoderSettings) EncoderSettings {
+	return f(feedback, current)
+}
+
+// DefaultPolicy follows the bandwidth estimate, or without one probes up
+// while loss is low and backs off under heavy loss. In-band FEC is enabled
+// above FECOnLoss and disabled below FECOffLoss, and frames are lengthened
+// to 40 ms below LongFrameBitrate to save packet overhead.
+type DefaultPolicy struct {
+	MinBitrate       int     // lowest bitrate, default 6000
+	MaxBitrate       int     // highest bitrate, default 64000
+	Headroom         float64 // fraction of the estimated bandwidth used, default 0.9
+	FECOnLoss        float64 // loss fraction enabling FEC, default 0.02
+	FECOffLoss       float64 // loss fraction disabling FEC, default 0.01
+	LongFrameBitrate int     // bitrate below which frames last 40 ms, default 12000
+}
+
+// NewDefaultPolicy creates a DefaultPolicy with the default thresholds
+func NewDefaultPolicy() *DefaultPolicy {
+	return &DefaultPolicy{
+		MinBitrate:       6000,
+		MaxBitrate:       64000,
+		Headroom:         0.9,
+		FECOnLoss:        0.02,
+		FECOffLoss:       0.01,
+		LongFrameBitrate: 12000,
+	}
+}
+
+// Settings implements Policy
+func (p *DefaultPolicy) Settings(feedback Feedback, current EncoderSettings) EncoderSettings {
+	var s EncoderSettings
+	switch {
+	case feedback.Bandwidth > 0:
+		s.Bitrate = int(float64(feedback.Bandwidth) * p.Headroom)
+	case feedback.LossFraction > 0.1:
+		s.Bitrate = int(float64(current.Bitrate) * (1 - feedback.LossFraction/2))
+	case feedback.LossFraction < 0.02:
+		s.Bitrate = int(float64(current.Bitrate) * 1.15)
+	default:
+		s.Bitrate = current.Bitrate
+	}
+	return p.SettingsForBitrate(feedback, current, min(max(s.Bitrate, p.MinBitrate), p.MaxBitrate))
+}
+
+// SettingsForBitrate implements BitratePolicy
+func (p *DefaultPolicy) SettingsForBitrate(feedback Feedback, current EncoderSettings, bitrate int) EncoderSettings {
+	s := current
+	s.Bitrate = bitrate
+	s.PacketLossPerc = min(int(math.Round(feedback.LossFraction*100)), 100)
+	if feedback.LossFraction >= p.FECOnLoss {
+		s.InbandFEC = true
+	} else if feedback.LossFraction < p.FECOffLoss {
+		s.InbandFEC = false
+	}
+
+	// Back to 20 ms a quarter above the threshold
+	if s.Bitrate < p.LongFrameBitrate {
+		s.FrameDuration = 40 * time.Millisecond
+	} else if s.Bitrate > p.LongFrameBitrate*5/4 || s.FrameDuration == 0 {
+		s.FrameDuration = 20 * time.Millisecond
+	}
+	return s
+}
+
+// Decision records what an AdaptiveController did with a feedback sample
+type Decision struct {
+	Time     time.Time
+	Sample   Feedback        // feedback received
+	Smoothed Feedback        // moving averages the policy decided on
+	Proposed EncoderSettings // settings proposed by the policy
+	Previous EncoderSettings // settings before the sample
+	Settings EncoderSettings // settings applied
+	Reason   string          // what was changed or held back and why
+}
+
+// String formats the decision for a debug log
+func (d Decision) String() string {
+	return fmt.Sprintf("%s bandwidth=%d loss=%.3f: %s",
+		d.Time.Format("15:04:05.000"), d.Smoothed.Bandwidth, d.Smoothed.LossFraction, d.Reason)
+}
+
+// AdaptiveController adjusts an encoder to receiver feedback. Samples are
+// smoothed by exponential moving averages before the policy decides on
+// them. Bitrate changes smaller than MinChange are ignored, and bitrate
+// increases wait HoldTime after the last bitrate change, while decreases
+// apply at once.
+//
+// While the bitrate is OPUS_AUTO, the policy and the hysteresis work from the
+// bitrate the encoder reports for it, and a held bitrate stays OPUS_AUTO.
+// When the bitrate is held, a BitratePolicy decides the other settings again
+// for the held bitrate; for other policies the frame duration in effect is
+// kept, as it was chosen for that bitrate.
+//
+// The frame duration cannot be set on the encoder, which codes the frames it
+// is given: callers read it from Settings and size their frames accordingly.
+type AdaptiveController struct {
+	Policy    Policy
+	Smoothing float64       // weight of a new sample in the moving averages, default 0.3
+	MinChange float64       // smallest relative bitrate change applied, default 0.1
+	HoldTime  time.Duration // wait after a bitrate change before increasing, default 3 s
+	LogSize   int           // decisions kept by Decisions, default 100
+
+	encoder     *OpusEncoder
+	settings    EncoderSettings
+	smoothed    Feedback
+	sampled     bool      // a sample was received
+	bitrateTime time.Time // time of the last bitrate change
+	decisions   []Decision
+}
+
+// NewAdaptiveController creates a controller for encoder with the default
+// policy, applying the initial settings. A zero initial bitrate means
+// OPUS_AUTO.
+func NewAdaptiveController(encoder *OpusEncoder, initial EncoderSettings) (*AdaptiveController, error) {
+	c := &AdaptiveController{
+		Policy:    NewDefaultPolicy(),
+		Smoothing: 0.3,
+		MinChange: 0.1,
+		HoldTime:  3 * time.Second,
+		LogSize:   100,
+		encoder:   encoder,
+	}
+	if initial.FrameDuration == 0 {
+		initial.FrameDuration = 20 * time.Millisecond
+	}
+	if initial.Bitrate == 0 {
+		initial.Bitrate = OPUS_AUTO
+	}
+	if err := c.apply(initial, EncoderSettings{}, true); err != nil {
+		return nil, err
+	}
+	c.settings = initial
+	return c, nil
+}
+
+// Update takes a feedback sample, applies the settings decided on to the
+// encoder and returns them
+func (c *AdaptiveController) Update(sample Feedback) (EncoderSettings, error) {
+	if sample.Time.IsZero() {
+		sample.Time = time.Now()
+	}
+	if !c.sampled {
+		c.smoothed, c.sampled = sample, true
+	} else {
+		a := c.Smoothing
+		c.smoothed.Time = sample.Time
+		c.smoothed.LossFraction += a * (sample.LossFraction - c.smoothed.LossFraction)
+		// An unknown value does not pull the average down
+		if sample.Bandwidth > 0 {
+			if c.smoothed.Bandwidth == 0 {
+				c.smoothed.Bandwidth = sample.Bandwidth
+			}
+			c.smoothed.Bandwidth += int(a * float64(sample.Bandwidth-c.smoothed.Bandwidth))
+		}
+		if sample.RTT > 0 {
+			if c.smoothed.RTT == 0 {
+				c.smoothed.RTT = sample.RTT
+			}
+			c.smoothed.RTT += time.Duration(a * float64(sample.RTT-c.smoothed.RTT))
+		}
+	}
+
+	// The policy works from the bitrate the encoder uses for OPUS_AUTO
+	current := c.settings
+	if current.Bitrate <= 0 {
+		bitrate, err := c.encoder.CtlGetInt(OPUS_GET_BITRATE_REQUEST)
+		if err != nil {
+			return c.settings, err
+		}
+		current.Bitrate = bitrate
+	}
+	proposed := c.Policy.Settings(c.smoothed, current)
+	settings := proposed
+	var reasons []string
+	if settings.Bitrate != current.Bitrate {
+		held := true
+		switch change := float64(settings.Bitrate-current.Bitrate) / float64(current.Bitrate); {
+		case current.Bitrate <= 0:
+			held = false
+		case math.Abs(change) < c.MinChange:
+			reasons = append(reasons, fmt.Sprintf("bitrate %d held, change %+.1f%% below %.0f%%",
+				current.Bitrate, change*100, c.MinChange*100))
+		case change > 0 && sample.Time.Sub(c.bitrateTime) < c.HoldTime:
+			reasons = append(reasons, fmt.Sprintf("bitrate %d held, increase to %d within %v of last change",
+				current.Bitrate, settings.Bitrate, c.HoldTime))
+		default:
+			held = false
+		}
+		if held {
+			settings = c.settingsForBitrate(proposed, current)
+		} else {
+			reasons = append(reasons, fmt.Sprintf("bitrate %d -> %d", current.Bitrate, settings.Bitrate))
+		}
+	}
+	if settings.Bitrate == current.Bitrate {
+		// Held, or proposed unchanged, which keeps OPUS_AUTO
+		settings.Bitrate = c.settings.Bitrate
+	}
+	if settings.PacketLossPerc != c.settings.PacketLossPerc {
+		reasons = append(reasons, fmt.Sprintf("packet loss %d%% -> %d%%", c.settings.PacketLossPerc, settings.PacketLossPerc))
+	}
+	if settings.InbandFEC != c.settings.InbandFEC {
+		reasons = append(reasons, fmt.Sprintf("FEC %t -> %t", c.settings.InbandFEC, settings.InbandFEC))
+	}
+	if settings.FrameDuration != c.settings.FrameDuration {
+		reasons = append(reasons, fmt.Sprintf("frame duration %v -> %v", c.settings.FrameDuration, settings.FrameDuration))
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "unchanged")
+	}
+
+	if err := c.apply(settings, c.settings, false); err != nil {
+		return c.settings, err
+	}
+	if settings.Bitrate != c.settings.Bitrate {
+		c.bitrateTime = sample.Time
+	}
+	c.log(Decision{
+		Time:     sample.Time,
+		Sample:   sample,
+		Smoothed: c.smoothed,
+		Proposed: proposed,
+		Previous: c.settings,
+		Settings: settings,
+		Reason:   strings.Join(reasons, ", "),
+	})
+	c.settings = settings
+	return settings, nil
+}
+
+// settingsForBitrate returns the settings for the smoothed feedback at the
+// held bitrate of current, in place of those proposed for another bitrate
+func (c *AdaptiveController) settingsForBitrate(proposed, current EncoderSettings) EncoderSettings {
+	if policy, ok := c.Policy.(BitratePolicy); ok {
+		return policy.SettingsForBitrate(c.smoothed, current, current.Bitrate)
+	}
+	proposed.Bitrate = current.Bitrate
+	proposed.FrameDuration = current.FrameDuration
+	return proposed
+}
+
+// apply sets the settings differing from previous on the encoder, or all of
+// them
+func (c *AdaptiveController) apply(settings, previous EncoderSettings, all bool) error {
+	if c.encoder == nil || c.encoder.encoder == nil {
+		return errors.New("encoder not initialized")
+	}
+	if all || settings.Bitrate != previous.Bitrate {
+		if err := c.encoder.SetBitrate(settings.Bitrate); err != nil {
+			return err
+		}
+	}
+	if all || settings.PacketLossPerc != previous.PacketLossPerc {
+		if err := c.encoder.CtlInt(OPUS_SET_PACKET_LOSS_PERC_REQUEST, settings.PacketLossPerc); err != nil {
+			return err
+		}
+	}
+	if all || settings.InbandFEC != previous.InbandFEC {
+		if err := c.encoder.CtlInt(OPUS_SET_INBAND_FEC_REQUEST, boolInt(settings.InbandFEC)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// log keeps decision, dropping the oldest beyond LogSize
+func (c *AdaptiveController) log(decision Decision) {
+	c.decisions = append(c.decisions, decision)
+	if len(c.decisions) > c.LogSize {
+		c.decisions = c.decisions[:copy(c.decisions, c.decisions[len(c.decisions)-c.LogSize:])]
+	}
+}
+
+// Settings returns the settings in effect
+func (c *AdaptiveController) Settings() EncoderSettings {
+	return c.settings
+}
+
+// Decisions returns the latest decisions, oldest first
+func (c *AdaptiveController) Decisions() []Decision {
+	return append([]Decision(nil), c.decisions...)
+}
@@ -0,0 +1,283 @@
+//go:build dlopen
+
+// Stubs for the libopus functions used by the package, calling through
+// pointers resolved by go_opus_dlopen
+
+#include <stdarg.h>
+#include <opus.h>
+#include <opus_multistream.h>
+#include <opus_projection.h>
+#include "dl.h"
+
+// OPUS_FUNCS lists the functions returning a value as
+// F(return type, name, parameters, arguments)
+#define OPUS_FUNCS(F) \
+    F(const char *, opus_strerror, (int error), (error)) \
+    F(const char *, opus_get_version_string, (void), ()) \
+    F(OpusEncoder *, opus_encoder_create, (opus_int32 Fs, int channels, int application, int *error), (Fs, channels, application, error)) \
+    F(opus_int32, opus_encode, (OpusEncoder *st, const opus_int16 *pcm, int frame_size, unsigned char *data, opus_int32 max_data_bytes), (st, pcm, frame_size, data, max_data_bytes)) \
+    F(OpusDecoder *, opus_decoder_create, (opus_int32 Fs, int channels, int *error), (Fs, channels, error)) \
+    F(int, opus_decode, (OpusDecoder *st, const unsigned char *data, opus_int32 len, opus_int16 *pcm, int frame_size, int decode_fec), (st, data, len, pcm, frame_size, decode_fec)) \
+    F(int, opus_packet_get_nb_samples, (const unsigned char packet[], opus_int32 len, opus_int32 Fs), (packet, len, Fs)) \
+    F(int, opus_packet_get_nb_frames, (const unsigned char packet[], opus_int32 len), (packet, len)) \
+    F(int, opus_packet_get_samples_per_frame, (const unsigned char *data, opus_int32 Fs), (data, Fs)) \
+    F(int, opus_packet_get_nb_channels, (const unsigned char *data), (data)) \
+    F(int, opus_packet_parse, (const unsigned char *data, opus_int32 len, unsigned char *out_toc, const unsigned char *frames[48], opus_int16 size[48], int *payload_offset), (data, len, out_toc, frames, size, payload_offset)) \
+    F(OpusMSEncoder *, opus_multistream_encoder_create, (opus_int32 Fs, int channels, int streams, int coupled_streams, const unsigned char *mapping, int application, int *error), (Fs, channels, streams, coupled_streams, mapping, application, error)) \
+    F(OpusMSEncoder *, opus_multistream_surround_encoder_create, (opus_int32 Fs, int channels, int mapping_family, int *streams, int *coupled_streams, unsigned char *mapping, int application, int *error), (Fs, channels, mapping_family, streams, coupled_streams, mapping, application, error)) \
+    F(int, opus_multistream_encode, (OpusMSEncoder *st, const opus_int16 *pcm, int frame_size, unsigned char *data, opus_int32 max_data_bytes), (st, pcm, frame_size, data, max_data_bytes)) \
+    F(OpusMSDecoder *, opus_multistream_decoder_create, (opus_int32 Fs, int channels, int streams, int coupled_streams, const unsigned char *mapping, int *error), (Fs, channels, streams, coupled_streams, mapping, error)) \
+    F(int, opus_multistream_decode, (OpusMSDecoder *st, const unsigned char *data, opus_int32 len, opus_int16 *pcm, int frame_size, int decode_fec), (st, data, len, pcm, frame_size, decode_fec))
+
+// OPUS_OPTIONAL_FUNCS lists the functions of libopus 1.3 and later, and the
+// DRED functions of 1.5, left NULL if missing
+#define OPUS_OPTIONAL_FUNCS(F) \
+    F(OpusProjectionEncoder *, opus_projection_ambisonics_encoder_create, (opus_int32 Fs, int channels, int mapping_family, int *streams, int *coupled_streams, int application, int *error), (Fs, channels, mapping_family, streams, coupled_streams, application, error)) \
+    F(int, opus_projection_encode, (OpusProjectionEncoder *st, const opus_int16 *pcm, int frame_size, unsigned char *data, opus_int32 max_data_bytes), (st, pcm, frame_size, data, max_data_bytes)) \
+    F(OpusProjectionDecoder *, opus_projection_decoder_create, (opus_int32 Fs, int channels, int streams, int coupled_streams, unsigned char *demixing_matrix, opus_int32 demixing_matrix_size, int *error), (Fs, channels, streams, coupled_streams, demixing_matrix, demixing_matrix_size, error)) \
+    F(int, opus_projection_decode, (OpusProjectionDecoder *st, const unsigned char *data, opus_int32 len, opus_int16 *pcm, int frame_size, int decode_fec), (st, data, len, pcm, frame_size, decode_fec)) \
+    F(OpusDREDDecoder *, opus_dred_decoder_create, (int *error), (error)) \
+    F(OpusDRED *, opus_dred_alloc, (int *error), (error)) \
+    F(int, opus_dred_parse, (OpusDREDDecoder *dred_dec, OpusDRED *dred, const unsigned char *data, opus_int32 len, opus_int32 max_dred_samples, opus_int32 sampling_rate, int *dred_end, int defer_processing), (dred_dec, dred, data, len, max_dred_samples, sampling_rate, dred_end, defer_processing)) \
+    F(int, opus_decoder_dred_decode, (OpusDecoder *st, const OpusDRED *dred, opus_int32 dred_offset, opus_int16 *pcm, opus_int32 frame_size), (st, dred, dred_offset, pcm, frame_size))
+
+// OPUS_OPTIONAL_VOID_FUNCS lists the optional functions returning nothing
+#define OPUS_OPTIONAL_VOID_FUNCS(F) \
+    F(void, opus_projection_encoder_destroy, (OpusProjectionEncoder *st), (st)) \
+    F(void, opus_projection_decoder_destroy, (OpusProjectionDecoder *st), (st)) \
+    F(void, opus_dred_decoder_destroy, (OpusDREDDecoder *dec), (dec)) \
+    F(void, opus_dred_free, (OpusDRED *dec), (dec))
+
+// OPUS_VOID_FUNCS lists the functions returning nothing
+#define OPUS_VOID_FUNCS(F) \
+    F(void, opus_encoder_destroy, (OpusEncoder *st), (st)) \
+    F(void, opus_decoder_destroy, (OpusDecoder *st), (st)) \
+    F(void, opus_multistream_encoder_destroy, (OpusMSEncoder *st), (st)) \
+    F(void, opus_multistream_decoder_destroy, (OpusMSDecoder *st), (st)) \
+    F(void, opus_pcm_soft_clip, (float *pcm, int frame_size, int channels, float *softclip_mem), (pcm, frame_size, channels, softclip_mem))
+
+// OPUS_CTL_FUNCS lists the variadic ctl functions
+#define OPUS_CTL_FUNCS(F) \
+    F(int, opus_encoder_ctl, (OpusEncoder *st, int request, ...), ()) \
+    F(int, opus_decoder_ctl, (OpusDecoder *st, int request, ...), ()) \
+    F(int, opus_multistream_encoder_ctl, (OpusMSEncoder *st, int request, ...), ()) \
+    F(int, opus_multistream_decoder_ctl, (OpusMSDecoder *st, int request, ...), ())
+
+// OPUS_OPTIONAL_CTL_FUNCS lists the optional ctl functions
+#define OPUS_OPTIONAL_CTL_FUNCS(F) \
+    F(int, opus_projection_encoder_ctl, (OpusProjectionEncoder *st, int request, ...), ()) \
+    F(int, opus_projection_decoder_ctl, (OpusProjectionDecoder *st, int request, ...), ())
+
+#define DECLARE(ret, name, params, args) static ret (*p_##name) params;
+OPUS_FUNCS(DECLARE)
+OPUS_VOID_FUNCS(DECLARE)
+OPUS_CTL_FUNCS(DECLARE)
+OPUS_OPTIONAL_FUNCS(DECLARE)
+OPUS_OPTIONAL_VOID_FUNCS(DECLARE)
+OPUS_OPTIONAL_CTL_FUNCS(DECLARE)
+#undef DECLARE
+
+#define DEFINE(ret, name, params, args) ret name params { return p_##name args; }
+OPUS_FUNCS(DEFINE)
+OPUS_OPTIONAL_FUNCS(DEFINE)
+#undef DEFINE
+
+#define DEFINE_VOID(ret, name, params, args) ret name params { p_##name args; }
+OPUS_VOID_FUNCS(DEFINE_VOID)
+OPUS_OPTIONAL_VOID_FUNCS(DEFINE_VOID)
+#undef DEFINE_VOID
+
+// OPUS_SET_FORCE_MODE_REQUEST is declared by the private opus_private.h
+#define OPUS_SET_FORCE_MODE_REQUEST 11002
+
+// OPUS_INT_CTLS lists the ctl requests taking an opus_int32 value
+#define OPUS_INT_CTLS(R) \
+    R(OPUS_SET_APPLICATION_REQUEST) \
+    R(OPUS_SET_BITRATE_REQUEST) \
+    R(OPUS_SET_MAX_BANDWIDTH_REQUEST) \
+    R(OPUS_SET_VBR_REQUEST) \
+    R(OPUS_SET_BANDWIDTH_REQUEST) \
+    R(OPUS_SET_COMPLEXITY_REQUEST) \
+    R(OPUS_SET_INBAND_FEC_REQUEST) \
+    R(OPUS_SET_PACKET_LOSS_PERC_REQUEST) \
+    R(OPUS_SET_DTX_REQUEST) \
+    R(OPUS_SET_VBR_CONSTRAINT_REQUEST) \
+    R(OPUS_SET_FORCE_CHANNELS_REQUEST) \
+    R(OPUS_SET_SIGNAL_REQUEST) \
+    R(OPUS_SET_GAIN_REQUEST) \
+    R(OPUS_SET_LSB_DEPTH_REQUEST) \
+    R(OPUS_SET_EXPERT_FRAME_DURATION_REQUEST) \
+    R(OPUS_SET_PREDICTION_DISABLED_REQUEST) \
+    R(OPUS_SET_PHASE_INVERSION_DISABLED_REQUEST) \
+    R(OPUS_SET_DRED_DURATION_REQUEST) \
+    R(OPUS_SET_FORCE_MODE_REQUEST)
+
+// OPUS_POINTER_CTLS lists the ctl requests taking a pointer to the value
+// to get, an opus_int32 or for OPUS_GET_FINAL_RANGE_REQUEST an opus_uint32
+#define OPUS_POINTER_CTLS(R) \
+    R(OPUS_GET_APPLICATION_REQUEST) \
+    R(OPUS_GET_BITRATE_REQUEST) \
+    R(OPUS_GET_MAX_BANDWIDTH_REQUEST) \
+    R(OPUS_GET_VBR_REQUEST) \
+    R(OPUS_GET_BANDWIDTH_REQUEST) \
+    R(OPUS_GET_COMPLEXITY_REQUEST) \
+    R(OPUS_GET_INBAND_FEC_REQUEST) \
+    R(OPUS_GET_PACKET_LOSS_PERC_REQUEST) \
+    R(OPUS_GET_DTX_REQUEST) \
+    R(OPUS_GET_VBR_CONSTRAINT_REQUEST) \
+    R(OPUS_GET_FORCE_CHANNELS_REQUEST) \
+    R(OPUS_GET_SIGNAL_REQUEST) \
+    R(OPUS_GET_LOOKAHEAD_REQUEST) \
+    R(OPUS_GET_SAMPLE_RATE_REQUEST) \
+    R(OPUS_GET_FINAL_RANGE_REQUEST) \
+    R(OPUS_GET_PITCH_REQUEST) \
+    R(OPUS_GET_GAIN_REQUEST) \
+    R(OPUS_GET_LSB_DEPTH_REQUEST) \
+    R(OPUS_GET_LAST_PACKET_DURATION_REQUEST) \
+    R(OPUS_GET_EXPERT_FRAME_DURATION_REQUEST) \
+    R(OPUS_GET_PREDICTION_DISABLED_REQUEST) \
+    R(OPUS_GET_PHASE_INVERSION_DISABLED_REQUEST) \
+    R(OPUS_GET_IN_DTX_REQUEST) \
+    R(OPUS_GET_DRED_DURATION_REQUEST) \
+    R(OPUS_PROJECTION_GET_DEMIXING_MATRIX_GAIN_REQUEST) \
+    R(OPUS_PROJECTION_GET_DEMIXING_MATRIX_SIZE_REQUEST)
+
+// forward_ctl passes the arguments of a ctl request on to ctl, reading them
+// from ap by the argument types of the request. Requests not listed above,
+// such as those of later libopus versions, follow the libopus convention of
+// an opus_int32 for even (SET) requests and a pointer for odd (GET) ones.
+static int forward_ctl(int (*ctl)(void *, int, ...), void *st, int request, va_list ap) {
+    switch (request) {
+#define CASE(request) case request:
+    OPUS_INT_CTLS(CASE)
+        return ctl(st, request, va_arg(ap, opus_int32));
+    OPUS_POINTER_CTLS(CASE)
+        return ctl(st, request, va_arg(ap, void *));
+#undef CASE
+    case OPUS_RESET_STATE:
+        return ctl(st, request);
+    case OPUS_SET_DNN_BLOB_REQUEST: {
+        void *data = va_arg(ap, void *);
+        return ctl(st, request, data, va_arg(ap, opus_int32));
+    }
+    case OPUS_MULTISTREAM_GET_ENCODER_STATE_REQUEST:
+    case OPUS_MULTISTREAM_GET_DECODER_STATE_REQUEST: {
+        opus_int32 stream = va_arg(ap, opus_int32);
+        return ctl(st, request, stream, va_arg(ap, void *));
+    }
+    case OPUS_PROJECTION_GET_DEMIXING_MATRIX_REQUEST: {
+        unsigned char *matrix = va_arg(ap, unsigned char *);
+        return ctl(st, request, matrix, va_arg(ap, opus_int32));
+    }
+    default:
+        if (request & 1) {
+            return ctl(st, request, va_arg(ap, void *));
+        }
+        return ctl(st, request, va_arg(ap, opus_int32));
+    }
+}
+
+int opus_encoder_ctl(OpusEncoder *st, int request, ...) {
+    va_list ap;
+    va_start(ap, request);
+    int ret = forward_ctl((int (*)(void *, int, ...))p_opus_encoder_ctl, st, request, ap);
+    va_end(ap);
+    return ret;
+}
+
+int opus_decoder_ctl(OpusDecoder *st, int request, ...) {
+    va_list ap;
+    va_start(ap, request);
+    int ret = forward_ctl((int (*)(void *, int, ...))p_opus_decoder_ctl, st, request, ap);
+    va_end(ap);
+    return ret;
+}
+
+int opus_multistream_encoder_ctl(OpusMSEncoder *st, int request, ...) {
+    va_list ap;
+    va_start(ap, request);
+    int ret = forward_ctl((int (*)(void *, int, ...))p_opus_multistream_encoder_ctl, st, request, ap);
+    va_end(ap);
+    return ret;
+}
+
+int opus_multistream_decoder_ctl(OpusMSDecoder *st, int request, ...) {
+    va_list ap;
+    va_start(ap, request);
+    int ret = forward_ctl((int (*)(void *, int, ...))p_opus_multistream_decoder_ctl, st, request, ap);
+    va_end(ap);
+    return ret;
+}
+
+int opus_projection_encoder_ctl(OpusProjectionEncoder *st, int request, ...) {
+    va_list ap;
+    va_start(ap, request);
+    int ret = forward_ctl((int (*)(void *, int, ...))p_opus_projection_encoder_ctl, st, request, ap);
+    va_end(ap);
+    return ret;
+}
+
+int opus_projection_decoder_ctl(OpusProjectionDecoder *st, int request, ...) {
+    va_list ap;
+    va_start(ap, request);
+    int ret = forward_ctl((int (*)(void *, int, ...))p_opus_projection_decoder_ctl, st, request, ap);
+    va_end(ap);
+    return ret;
+}
+
+// clear_funcs resets the function pointers, so that none is left pointing
+// into a library closed after a failed load
+static void clear_funcs(void) {
+#define CLEAR(ret, name, params, args) p_##name = NULL;
+    OPUS_FUNCS(CLEAR)
+    OPUS_VOID_FUNCS(CLEAR)
+    OPUS_CTL_FUNCS(CLEAR)
+    OPUS_OPTIONAL_FUNCS(CLEAR)
+    OPUS_OPTIONAL_VOID_FUNCS(CLEAR)
+    OPUS_OPTIONAL_CTL_FUNCS(CLEAR)
+#undef CLEAR
+}
+
+// go_opus_dlopen loads libopus from path and resolves the functions, returning
+// NULL on success or else a description of the failure
+const char *go_opus_dlopen(const char *path) {
+    void *handle = dl_open(path);
+    if (!handle) {
+        return dl_error();
+    }
+    void *sym;
+#define RESOLVE(ret, name, params, args) \
+    if (!(sym = dl_sym(handle, #name))) { \
+        dl_close(handle); \
+        clear_funcs(); \
+        return dl_missing(#name); \
+    } \
+    *(void **)&p_##name = sym;
+    OPUS_FUNCS(RESOLVE)
+    OPUS_VOID_FUNCS(RESOLVE)
+    OPUS_CTL_FUNCS(RESOLVE)
+#undef RESOLVE
+#define RESOLVE_OPTIONAL(ret, name, params, args) *(void **)&p_##name = dl_sym(handle, #name);
+    OPUS_OPTIONAL_FUNCS(RESOLVE_OPTIONAL)
+    OPUS_OPTIONAL_VOID_FUNCS(RESOLVE_OPTIONAL)
+    OPUS_OPTIONAL_CTL_FUNCS(RESOLVE_OPTIONAL)
+#undef RESOLVE_OPTIONAL
+    return NULL;
+}
+
+// go_opus_dlopen_has_projection reports whether the loaded libopus has the
+// projection functions
+int go_opus_dlopen_has_projection(void) {
+    return p_opus_projection_ambisonics_encoder_create && p_opus_projection_encode &&
+        p_opus_projection_encoder_destroy && p_opus_projection_encoder_ctl &&
+        p_opus_projection_decoder_create && p_opus_projection_decode &&
+        p_opus_projection_decoder_destroy && p_opus_projection_decoder_ctl;
+}
+
+// go_opus_dlopen_has_dred reports whether the loaded libopus has the DRED
+// decoding functions
+int go_opus_dlopen_has_dred(void) {
+    return p_opus_dred_decoder_create && p_opus_dred_decoder_destroy &&
+        p_opus_dred_alloc && p_opus_dred_free &&
+        p_opus_dred_parse && p_opus_decoder_dred_decode;
+}
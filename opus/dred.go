@@ -0,0 +1,118 @@
+package opus
+
+/*
+#include <opus.h>
+#include <stddef.h>
+
+// The DRED decoding functions were added in libopus 1.5 and are detected
+// like the projection functions, see projection.go.
+#if defined(GO_OPUS_DLOPEN)
+int go_opus_dlopen_has_dred(void);
+static int go_opus_has_dred(void) {
+    return go_opus_dlopen_has_dred();
+}
+#elif defined(__ELF__)
+#pragma weak opus_dred_decoder_create
+#pragma weak opus_dred_decoder_destroy
+#pragma weak opus_dred_alloc
+#pragma weak opus_dred_free
+#pragma weak opus_dred_parse
+#pragma weak opus_decoder_dred_decode
+static int go_opus_has_dred(void) {
+    return opus_dred_decoder_create != NULL && opus_decoder_dred_decode != NULL;
+}
+#else
+static int go_opus_has_dred(void) {
+    return 1;
+}
+#endif
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// OpusDREDDecoder extracts the Deep REDundancy (DRED) data carried by the
+// packets of an encoder with SetDREDDuration, from which DecodeDRED
+// recovers audio lost before the packet
+type OpusDREDDecoder struct {
+	decoder *C.OpusDREDDecoder
+	dred    *C.OpusDRED
+}
+
+// NewDREDDecoder creates a DRED decoder. It returns ErrUnsupported if
+// libopus is older than 1.5 or was built without DRED.
+func NewDREDDecoder() (*OpusDREDDecoder, error) {
+	if err := load(); err != nil {
+		return nil, err
+	}
+	if C.go_opus_has_dred() == 0 {
+		return nil, ErrUnsupported
+	}
+
+	var cErr C.int
+	decoder := C.opus_dred_decoder_create(&cErr)
+	if err := opusError(cErr); err != nil {
+		return nil, err
+	}
+	dred := C.opus_dred_alloc(&cErr)
+	if err := opusError(cErr); err != nil {
+		C.opus_dred_decoder_destroy(decoder)
+		return nil, err
+	}
+	return &OpusDREDDecoder{decoder: decoder, dred: dred}, nil
+}
+
+// Parse extracts the DRED data of packet, keeping up to maxSamples at
+// sampleRate of it. It returns how far before the start of packet the data
+// reaches, in samples at sampleRate, 0 if packet has none.
+func (d *OpusDREDDecoder) Parse(packet []byte, maxSamples int, sampleRate int) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("DRED decoder not initialized")
+	}
+	if len(packet) == 0 {
+		return 0, errors.New("empty input")
+	}
+
+	var end C.int
+	data := (*C.uchar)(unsafe.Pointer(&packet[0]))
+	ret := C.opus_dred_parse(d.decoder, d.dred, data, C.opus_int32(len(packet)),
+		C.opus_int32(maxSamples), C.opus_int32(sampleRate), &end, 0)
+	if ret < 0 {
+		return 0, opusError(ret)
+	}
+	return int(ret), nil
+}
+
+// Close frees the DRED decoder resources
+func (d *OpusDREDDecoder) Close() {
+	if d.decoder != nil {
+		C.opus_dred_free(d.dred)
+		C.opus_dred_decoder_destroy(d.decoder)
+		d.decoder, d.dred = nil, nil
+	}
+}
+
+// DecodeDRED recovers frameSize samples per channel of audio lost before the
+// packet last parsed by dred, starting offset samples before that packet,
+// and writes them to output as 16-bit PCM. offset and frameSize are at the
+// sample rate of the decoder; frameSize must be a multiple of 2.5 ms.
+func (d *OpusDecoder) DecodeDRED(dred *OpusDREDDecoder, offset int, output []byte, frameSize int) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	if dred == nil || dred.decoder == nil {
+		return 0, errors.New("DRED decoder not initialized")
+	}
+	if frameSize <= 0 || len(output) < frameSize*d.channels*2 {
+		return 0, errors.New("output buffer too small")
+	}
+
+	pcm := (*C.opus_int16)(unsafe.Pointer(&output[0]))
+	ret := C.opus_decoder_dred_decode(d.decoder, dred.dred, C.opus_int32(offset), pcm, C.opus_int32(frameSize))
+	if ret < 0 {
+		return int(ret), opusError(ret)
+	}
+	return int(ret), nil
+}
@@ -0,0 +1,318 @@
+package opus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FmtpParams holds the Opus parameters of an SDP a=fmtp line (RFC 7587
+// section 6.1). The plain parameters state what the party sending them wants
+// to receive, the sprop- parameters what it sends. Zero means absent, which
+// for the flags is also their default.
+type FmtpParams struct {
+	MaxPlaybackRate     int  // maxplaybackrate, highest rate worth sending, in Hz
+	SpropMaxCaptureRate int  // sprop-maxcapturerate, highest rate sent, in Hz
+	MaxAverageBitrate   int  // maxaveragebitrate, in bits per second
+	Stereo              bool // stereo, stereo is preferred
+	SpropStereo         bool // sprop-stereo, stereo may be sent
+	CBR                 bool // cbr, constant bitrate is preferred
+	UseInbandFEC        bool // useinbandfec, in-band FEC is wanted
+	UseDTX              bool // usedtx, DTX is preferred
+	Ptime               int  // ptime, preferred packet duration in ms
+	MinPtime            int  // minptime, shortest packet duration in ms
+
+	// Other holds unknown parameters by lower case name
+	Other map[string]string
+}
+
+// ParseFmtp parses the parameters of an a=fmtp line, such as
+// "minptime=10;useinbandfec=1". An "a=fmtp:<payload type>" prefix is
+// skipped. Parameter names are case-insensitive. Numbers out of the range
+// of their parameter, such as maxaveragebitrate=600000, are clamped to it;
+// only values of the wrong syntax are errors.
+func ParseFmtp(line string) (FmtpParams, error) {
+	var p FmtpParams
+	line = strings.TrimSpace(line)
+	if rest, ok := strings.CutPrefix(line, "a=fmtp:"); ok {
+		_, line, _ = strings.Cut(rest, " ")
+	}
+
+	for _, param := range strings.Split(line, ";") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+
+		var err error
+		switch name {
+		case "maxplaybackrate":
+			p.MaxPlaybackRate, err = fmtpInt(name, value, 8000, 48000)
+		case "sprop-maxcapturerate":
+			p.SpropMaxCaptureRate, err = fmtpInt(name, value, 8000, 48000)
+		case "maxaveragebitrate":
+			p.MaxAverageBitrate, err = fmtpInt(name, value, 6000, 510000)
+		case "stereo":
+			p.Stereo, err = fmtpBool(name, value)
+		case "sprop-stereo":
+			p.SpropStereo, err = fmtpBool(name, value)
+		case "cbr":
+			p.CBR, err = fmtpBool(name, value)
+		case "useinbandfec":
+			p.UseInbandFEC, err = fmtpBool(name, value)
+		case "usedtx":
+			p.UseDTX, err = fmtpBool(name, value)
+		case "ptime":
+			p.Ptime, err = fmtpInt(name, value, 1, math.MaxInt32)
+		case "minptime":
+			p.MinPtime, err = fmtpInt(name, value, 1, math.MaxInt32)
+		default:
+			if p.Other == nil {
+				p.Other = make(map[string]string)
+			}
+			p.Other[name] = value
+		}
+		if err != nil {
+			return FmtpParams{}, err
+		}
+	}
+	return p, nil
+}
+
+// fmtpInt parses an integer parameter, clamping it between lo and hi. Only
+// a value that is not a decimal number is an error.
+func fmtpInt(name, value string, lo, hi int) (int, error) {
+	if value == "" || strings.Trim(value, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid fmtp parameter %s=%s", name, value)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		// Too many digits for an int
+		return hi, nil
+	}
+	return min(max(n, lo), hi), nil
+}
+
+// fmtpBool parses a 0 or 1 flag parameter
+func fmtpBool(name, value string) (bool, error) {
+	switch value {
+	case "0":
+		return false, nil
+	case "1":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid fmtp parameter %s=%s", name, value)
+}
+
+// String returns the parameters in a=fmtp syntax without the prefix, leaving
+// out absent ones, with the unknown parameters last in name order
+func (p FmtpParams) String() string {
+	var params []string
+	addInt := func(name string, value int) {
+		if value != 0 {
+			params = append(params, name+"="+strconv.Itoa(value))
+		}
+	}
+	addBool := func(name string, value bool) {
+		if value {
+			params = append(params, name+"=1")
+		}
+	}
+	addInt("maxplaybackrate", p.MaxPlaybackRate)
+	addInt("sprop-maxcapturerate", p.SpropMaxCaptureRate)
+	addInt("maxaveragebitrate", p.MaxAverageBitrate)
+	addBool("stereo", p.Stereo)
+	addBool("sprop-stereo", p.SpropStereo)
+	addBool("cbr", p.CBR)
+	addBool("useinbandfec", p.UseInbandFEC)
+	addBool("usedtx", p.UseDTX)
+	addInt("ptime", p.Ptime)
+	addInt("minptime", p.MinPtime)
+
+	names := make([]string, 0, len(p.Other))
+	for name := range p.Other {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if p.Other[name] == "" {
+			params = append(params, name)
+		} else {
+			params = append(params, name+"="+p.Other[name])
+		}
+	}
+	return strings.Join(params, ";")
+}
+
+// EncoderConfig is an encoder configuration negotiated through fmtp
+type EncoderConfig struct {
+	Channels      int           // 1 or 2
+	MaxBandwidth  int           // OPUS_BANDWIDTH_* constant
+	Bitrate       int           // bits per second, 0 for the encoder default
+	VBR           bool          // variable bitrate
+	InbandFEC     bool          // in-band FEC
+	DTX           bool          // discontinuous transmission
+	FrameDuration time.Duration // duration of the frames passed to Encode
+}
+
+// frameDurations are the Opus frame durations in ascending order
+var frameDurations = []time.Duration{
+	2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond,
+	40 * time.Millisecond, 60 * time.Millisecond, 80 * time.Millisecond, 100 * time.Millisecond,
+	120 * time.Millisecond,
+}
+
+// EncoderConfig returns the configuration for an encoder sending to the
+// party that sent p. Without ptime the frames last 20 ms, longer than
+// minptime if that is longer.
+func (p FmtpParams) EncoderConfig() EncoderConfig {
+	c := EncoderConfig{
+		Channels:     1,
+		MaxBandwidth: bandwidthForRate(p.MaxPlaybackRate),
+		Bitrate:      p.MaxAverageBitrate,
+		VBR:          !p.CBR,
+		InbandFEC:    p.UseInbandFEC,
+		DTX:          p.UseDTX,
+	}
+	if p.Stereo {
+		c.Channels = 2
+	}
+
+	ptime := 20 * time.Millisecond
+	if p.Ptime != 0 {
+		ptime = time.Duration(p.Ptime) * time.Millisecond
+	}
+	minPtime := time.Duration(p.MinPtime) * time.Millisecond
+	// The longest frame duration not above ptime, or else the shortest
+	// one, then the shortest not below minptime if that is longer
+	c.FrameDuration = frameDurations[0]
+	for _, d := range frameDurations {
+		if d <= ptime {
+			c.FrameDuration = d
+		}
+	}
+	for _, d := range frameDurations {
+		if c.FrameDuration >= minPtime {
+			break
+		}
+		c.FrameDuration = d
+	}
+	return c
+}
+
+// bandwidthForRate returns the widest bandwidth worth coding for playback
+// at rate, full band if rate is 0
+func bandwidthForRate(rate int) int {
+	switch {
+	case rate == 0:
+		return OPUS_BANDWIDTH_FULLBAND
+	case rate <= 8000:
+		return OPUS_BANDWIDTH_NARROWBAND
+	case rate <= 12000:
+		return OPUS_BANDWIDTH_MEDIUMBAND
+	case rate <= 16000:
+		return OPUS_BANDWIDTH_WIDEBAND
+	case rate <= 24000:
+		return OPUS_BANDWIDTH_SUPERWIDEBAND
+	}
+	return OPUS_BANDWIDTH_FULLBAND
+}
+
+// FmtpParams returns the parameters asking a remote encoder for audio coded
+// as c, the inverse of FmtpParams.EncoderConfig
+func (c EncoderConfig) FmtpParams() FmtpParams {
+	p := FmtpParams{
+		MaxAverageBitrate: c.Bitrate,
+		Stereo:            c.Channels == 2,
+		CBR:               !c.VBR,
+		UseInbandFEC:      c.InbandFEC,
+		UseDTX:            c.DTX,
+	}
+	switch c.MaxBandwidth {
+	case OPUS_BANDWIDTH_NARROWBAND:
+		p.MaxPlaybackRate = 8000
+	case OPUS_BANDWIDTH_MEDIUMBAND:
+		p.MaxPlaybackRate = 12000
+	case OPUS_BANDWIDTH_WIDEBAND:
+		p.MaxPlaybackRate = 16000
+	case OPUS_BANDWIDTH_SUPERWIDEBAND:
+		p.MaxPlaybackRate = 24000
+	}
+	if c.FrameDuration != 0 && c.FrameDuration != 20*time.Millisecond {
+		p.Ptime = int((c.FrameDuration + time.Millisecond - 1) / time.Millisecond)
+	}
+	return p
+}
+
+// Apply configures e as c. The channel count of an encoder is fixed, so a
+// stereo encoder is forced to code mono if c has one channel; the frame
+// duration is up to the caller.
+func (c EncoderConfig) Apply(e *OpusEncoder) error {
+	if e.encoder == nil {
+		return errors.New("encoder not initialized")
+	}
+	if c.Bitrate != 0 {
+		if err := e.SetBitrate(c.Bitrate); err != nil {
+			return err
+		}
+	}
+	forceChannels := OPUS_AUTO
+	if c.Channels == 1 {
+		forceChannels = 1
+	}
+	maxBandwidth := c.MaxBandwidth
+	if maxBandwidth == 0 {
+		maxBandwidth = OPUS_BANDWIDTH_FULLBAND
+	}
+	for _, ctl := range []struct{ request, value int }{
+		{OPUS_SET_MAX_BANDWIDTH_REQUEST, maxBandwidth},
+		{OPUS_SET_VBR_REQUEST, boolInt(c.VBR)},
+		{OPUS_SET_INBAND_FEC_REQUEST, boolInt(c.InbandFEC)},
+		{OPUS_SET_DTX_REQUEST, boolInt(c.DTX)},
+		{OPUS_SET_FORCE_CHANNELS_REQUEST, forceChannels},
+	} {
+		if err := e.CtlInt(ctl.request, ctl.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// boolInt returns 1 for true and 0 for false
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// DecoderConfig is a decoder configuration negotiated through fmtp
+type DecoderConfig struct {
+	SampleRate int // Opus sample rate to decode at
+	Channels   int // 1 or 2
+}
+
+// DecoderConfig returns the configuration for a decoder receiving from the
+// party that sent p: the lowest Opus sample rate covering
+// sprop-maxcapturerate and stereo if sprop-stereo is set
+func (p FmtpParams) DecoderConfig() DecoderConfig {
+	c := DecoderConfig{SampleRate: 48000, Channels: 1}
+	if p.SpropStereo {
+		c.Channels = 2
+	}
+	if p.SpropMaxCaptureRate != 0 {
+		for _, rate := range []int{8000, 12000, 16000, 24000, 48000} {
+			if rate >= p.SpropMaxCaptureRate {
+				c.SampleRate = rate
+				break
+			}
+		}
+	}
+	return c
+}
@@ -0,0 +1,18 @@
+//go:build !dlopen && !vendored
+
+package opus
+
+// #cgo LDFLAGS: -L${SRCDIR} -lopus
+import "C"
+
+// Load does nothing in the default build, which links libopus at build time.
+// With the dlopen build tag it loads libopus from path at run time, see
+// library_dlopen.go.
+func Load(path string) error {
+	return nil
+}
+
+// load makes sure libopus is available before its first use
+func load() error {
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build dlopen
+
+package opus
+
+// #cgo CFLAGS: -I${SRCDIR}/../internal/dl -DGO_OPUS_DLOPEN
+// #cgo linux LDFLAGS: -ldl
+// #include <stdlib.h>
+// const char *go_opus_dlopen(const char *path);
+import "C"
+import (
+	"unsafe"
+
+	"github.com/justa-cai/go-libopus/internal/dl"
+)
+
+var libopus = dl.Library{Name: "libopus", Env: "LIBOPUS_PATH", Names: dl.Names("opus", "0")}
+
+// Load loads libopus from path, or if path is empty from the path in the
+// LIBOPUS_PATH environment variable or else under its standard names, such
+// as libopus.so.0. It does nothing once libopus is loaded.
+//
+// Encoders, decoders and the packet functions load libopus on first use, so
+// Load is only needed to choose the library or to check for it at startup.
+func Load(path string) error {
+	return libopus.Load(path, dlopen)
+}
+
+// load makes sure libopus is available before its first use
+func load() error {
+	return libopus.Implicit(dlopen)
+}
+
+// dlopen loads libopus from path, returning a description of the failure
+func dlopen(path string) string {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+	if msg := C.go_opus_dlopen(cPath); msg != nil {
+		return C.GoString(msg)
+	}
+	return ""
+}
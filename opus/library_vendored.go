@@ -0,0 +1,16 @@
+//go:build vendored && !dlopen
+
+package opus
+
+import _ "github.com/justa-cai/go-libopus/internal/libopus"
+
+// Load does nothing in the vendored build, which compiles libopus into the
+// package
+func Load(path string) error {
+	return nil
+}
+
+// load makes sure libopus is available before its first use
+func load() error {
+	return nil
+}
@@ -0,0 +1,246 @@
+package opus
+
+/*
+#include <opus_multistream.h>
+static int go_opus_ms_encoder_ctl_set_int(OpusMSEncoder *enc, int request, opus_int32 value) {
+    return opus_multistream_encoder_ctl(enc, request, value);
+}
+static int go_opus_ms_encoder_ctl_get_int(OpusMSEncoder *enc, int request, opus_int32 *value) {
+    return opus_multistream_encoder_ctl(enc, request, value);
+}
+static int go_opus_ms_encoder_ctl_get_uint32(OpusMSEncoder *enc, int request, opus_uint32 *value) {
+    return opus_multistream_encoder_ctl(enc, request, value);
+}
+static int go_opus_ms_decoder_ctl_set_int(OpusMSDecoder *dec, int request, opus_int32 value) {
+    return opus_multistream_decoder_ctl(dec, request, value);
+}
+static int go_opus_ms_decoder_ctl_get_int(OpusMSDecoder *dec, int request, opus_int32 *value) {
+    return opus_multistream_decoder_ctl(dec, request, value);
+}
+static int go_opus_ms_decoder_ctl_get_uint32(OpusMSDecoder *dec, int request, opus_uint32 *value) {
+    return opus_multistream_decoder_ctl(dec, request, value);
+}
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// StreamLayout describes how the channels of a multistream encoder or
+// decoder are coded, as stored in the channel mapping table of the OpusHead
+// header
+type StreamLayout struct {
+	Streams        int    // total number of streams
+	CoupledStreams int    // number of streams coding two channels, the first ones
+	Mapping        []byte // stream channel of each output channel, 255 for silence
+}
+
+// OpusMSEncoder represents an Opus multistream encoder, coding more than two
+// channels as several mono and stereo streams in one packet
+type OpusMSEncoder struct {
+	encoder  *C.OpusMSEncoder
+	channels int
+}
+
+// OpusMSDecoder represents an Opus multistream decoder
+type OpusMSDecoder struct {
+	decoder  *C.OpusMSDecoder
+	channels int
+}
+
+// NewMSEncoder creates a new Opus multistream encoder for the given layout
+func NewMSEncoder(sampleRate int, channels int, layout StreamLayout, application int) (*OpusMSEncoder, error) {
+	if sampleRate <= 0 || channels <= 0 || application < 0 {
+		return nil, errors.New("invalid parameter: must be positive")
+	}
+	if len(layout.Mapping) != channels {
+		return nil, errors.New("invalid parameter: mapping must have one entry per channel")
+	}
+	if err := load(); err != nil {
+		return nil, err
+	}
+
+	var err C.int
+	mapping := (*C.uchar)(unsafe.Pointer(&layout.Mapping[0]))
+	encoder := C.opus_multistream_encoder_create(C.opus_int32(sampleRate), C.int(channels),
+		C.int(layout.Streams), C.int(layout.CoupledStreams), mapping, C.int(application), &err)
+	if err != 0 {
+		return nil, errors.New(C.GoString(C.opus_strerror(err)))
+	}
+
+	return &OpusMSEncoder{encoder: encoder, channels: channels}, nil
+}
+
+// NewMSSurroundEncoder creates a new Opus multistream encoder choosing the
+// layout for the channel mapping family: 0 for mono and stereo, 1 for the
+// Vorbis channel orders of up to 8 channels and 255 for independent
+// channels. The layout is returned for the OpusHead header and the decoder.
+func NewMSSurroundEncoder(sampleRate int, channels int, mappingFamily int, application int) (*OpusMSEncoder, StreamLayout, error) {
+	if sampleRate <= 0 || channels <= 0 || channels > 255 || application < 0 {
+		return nil, StreamLayout{}, errors.New("invalid parameter: must be positive")
+	}
+	if err := load(); err != nil {
+		return nil, StreamLayout{}, err
+	}
+
+	var err, streams, coupledStreams C.int
+	mapping := make([]byte, channels)
+	encoder := C.opus_multistream_surround_encoder_create(C.opus_int32(sampleRate), C.int(channels),
+		C.int(mappingFamily), &streams, &coupledStreams, (*C.uchar)(unsafe.Pointer(&mapping[0])),
+		C.int(application), &err)
+	if err != 0 {
+		return nil, StreamLayout{}, errors.New(C.GoString(C.opus_strerror(err)))
+	}
+
+	layout := StreamLayout{Streams: int(streams), CoupledStreams: int(coupledStreams), Mapping: mapping}
+	return &OpusMSEncoder{encoder: encoder, channels: channels}, layout, nil
+}
+
+// CtlInt performs a ctl request taking an opus_int32 argument, applied to
+// every stream, see OpusEncoder.CtlInt
+func (e *OpusMSEncoder) CtlInt(request int, value int) error {
+	if e.encoder == nil {
+		return errors.New("encoder not initialized")
+	}
+	return opusError(C.go_opus_ms_encoder_ctl_set_int(e.encoder, C.int(request), C.opus_int32(value)))
+}
+
+// CtlGetInt performs a ctl request taking an opus_int32 pointer and returns
+// the value written, see OpusEncoder.CtlGetInt
+func (e *OpusMSEncoder) CtlGetInt(request int) (int, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	var value C.opus_int32
+	err := opusError(C.go_opus_ms_encoder_ctl_get_int(e.encoder, C.int(request), &value))
+	return int(value), err
+}
+
+// CtlGetUint32 performs a ctl request taking an opus_uint32 pointer and
+// returns the value written, see OpusEncoder.CtlGetUint32
+func (e *OpusMSEncoder) CtlGetUint32(request int) (uint32, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	var value C.opus_uint32
+	err := opusError(C.go_opus_ms_encoder_ctl_get_uint32(e.encoder, C.int(request), &value))
+	return uint32(value), err
+}
+
+// Encode encodes one frame of interleaved 16-bit PCM of all channels
+func (e *OpusMSEncoder) Encode(input []byte, output []byte) (int, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	if len(input) == 0 {
+		return 0, errors.New("empty input")
+	}
+	if len(output) == 0 {
+		return 0, errors.New("empty output buffer")
+	}
+
+	pcm := (*C.opus_int16)(unsafe.Pointer(&input[0]))
+	data := (*C.uchar)(unsafe.Pointer(&output[0]))
+	frameSize := len(input) / (2 * e.channels)
+
+	ret := C.opus_multistream_encode(e.encoder, pcm, C.int(frameSize), data, C.opus_int32(len(output)))
+	if ret < 0 {
+		return int(ret), errors.New(C.GoString(C.opus_strerror(C.int(ret))))
+	}
+	return int(ret), nil
+}
+
+// Close frees the encoder resources
+func (e *OpusMSEncoder) Close() {
+	if e.encoder != nil {
+		C.opus_multistream_encoder_destroy(e.encoder)
+		e.encoder = nil
+	}
+}
+
+// NewMSDecoder creates a new Opus multistream decoder for the given layout
+func NewMSDecoder(sampleRate int, channels int, layout StreamLayout) (*OpusMSDecoder, error) {
+	if sampleRate <= 0 || channels <= 0 {
+		return nil, errors.New("invalid parameter: must be positive")
+	}
+	if len(layout.Mapping) != channels {
+		return nil, errors.New("invalid parameter: mapping must have one entry per channel")
+	}
+	if err := load(); err != nil {
+		return nil, err
+	}
+
+	var err C.int
+	mapping := (*C.uchar)(unsafe.Pointer(&layout.Mapping[0]))
+	decoder := C.opus_multistream_decoder_create(C.opus_int32(sampleRate), C.int(channels),
+		C.int(layout.Streams), C.int(layout.CoupledStreams), mapping, &err)
+	if err != 0 {
+		return nil, errors.New(C.GoString(C.opus_strerror(err)))
+	}
+
+	return &OpusMSDecoder{decoder: decoder, channels: channels}, nil
+}
+
+// CtlInt performs a ctl request taking an opus_int32 argument, applied to
+// every stream, see OpusEncoder.CtlInt
+func (d *OpusMSDecoder) CtlInt(request int, value int) error {
+	if d.decoder == nil {
+		return errors.New("decoder not initialized")
+	}
+	return opusError(C.go_opus_ms_decoder_ctl_set_int(d.decoder, C.int(request), C.opus_int32(value)))
+}
+
+// CtlGetInt performs a ctl request taking an opus_int32 pointer and returns
+// the value written, see OpusEncoder.CtlGetInt
+func (d *OpusMSDecoder) CtlGetInt(request int) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	var value C.opus_int32
+	err := opusError(C.go_opus_ms_decoder_ctl_get_int(d.decoder, C.int(request), &value))
+	return int(value), err
+}
+
+// CtlGetUint32 performs a ctl request taking an opus_uint32 pointer and
+// returns the value written, see OpusEncoder.CtlGetUint32
+func (d *OpusMSDecoder) CtlGetUint32(request int) (uint32, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	var value C.opus_uint32
+	err := opusError(C.go_opus_ms_decoder_ctl_get_uint32(d.decoder, C.int(request), &value))
+	return uint32(value), err
+}
+
+// Decode decodes a packet into interleaved 16-bit PCM of all channels,
+// returning the number of samples per channel
+func (d *OpusMSDecoder) Decode(input []byte, output []byte) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	if len(input) == 0 {
+		return 0, errors.New("empty input")
+	}
+	if len(output) == 0 {
+		return 0, errors.New("empty output buffer")
+	}
+
+	data := (*C.uchar)(unsafe.Pointer(&input[0]))
+	pcm := (*C.opus_int16)(unsafe.Pointer(&output[0]))
+	frameSize := len(output) / (2 * d.channels)
+
+	ret := C.opus_multistream_decode(d.decoder, data, C.opus_int32(len(input)), pcm, C.int(frameSize), 0)
+	if ret < 0 {
+		return int(ret), errors.New(C.GoString(C.opus_strerror(C.int(ret))))
+	}
+	return int(ret), nil
+}
+
+// Close frees the decoder resources
+func (d *OpusMSDecoder) Close() {
+	if d.decoder != nil {
+		C.opus_multistream_decoder_destroy(d.decoder)
+		d.decoder = nil
+	}
+}
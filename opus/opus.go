@@ -2,8 +2,8 @@ package opus
 
 /*
 #cgo CFLAGS: -I${SRCDIR}/include/opus
-#cgo LDFLAGS: -L${SRCDIR} -lopus
 #include <opus.h>
+#include <stdlib.h>
 static int go_opus_encoder_set_bitrate(OpusEncoder *enc, opus_int32 bitrate) {
     return opus_encoder_ctl(enc, OPUS_SET_BITRATE(bitrate));
 }
@@ -13,6 +13,57 @@ static int go_opus_encoder_set_complexity(OpusEncoder *enc, int complexity) {
 static int go_opus_encoder_set_signal(OpusEncoder *enc, int signal) {
     return opus_encoder_ctl(enc, OPUS_SET_SIGNAL(signal));
 }
+static int go_opus_encoder_ctl_set_int(OpusEncoder *enc, int request, opus_int32 value) {
+    return opus_encoder_ctl(enc, request, value);
+}
+static int go_opus_encoder_ctl_get_int(OpusEncoder *enc, int request, opus_int32 *value) {
+    return opus_encoder_ctl(enc, request, value);
+}
+static int go_opus_encoder_ctl_get_uint32(OpusEncoder *enc, int request, opus_uint32 *value) {
+    return opus_encoder_ctl(enc, request, value);
+}
+static int go_opus_encoder_set_dnn_blob(OpusEncoder *enc, const void *data, opus_int32 len) {
+    return opus_encoder_ctl(enc, OPUS_SET_DNN_BLOB(data, len));
+}
+static int go_opus_decoder_ctl_set_int(OpusDecoder *dec, int request, opus_int32 value) {
+    return opus_decoder_ctl(dec, request, value);
+}
+static int go_opus_decoder_ctl_get_int(OpusDecoder *dec, int request, opus_int32 *value) {
+    return opus_decoder_ctl(dec, request, value);
+}
+static int go_opus_decoder_ctl_get_uint32(OpusDecoder *dec, int request, opus_uint32 *value) {
+    return opus_decoder_ctl(dec, request, value);
+}
+static int go_opus_decoder_set_dnn_blob(OpusDecoder *dec, const void *data, opus_int32 len) {
+    return opus_decoder_ctl(dec, OPUS_SET_DNN_BLOB(data, len));
+}
+// go_opus_packet_has_lbrr follows opus_packet_has_lbrr of libopus 1.5: the
+// LBRR flags are the first bits coded after the VAD flags of each SILK frame
+// in the first Opus frame, with probability 1/2, so they are its top bits.
+static int go_opus_packet_has_lbrr(const unsigned char *packet, opus_int32 len) {
+    const unsigned char *frames[48];
+    opus_int16 size[48];
+    int nb_frames = 1;
+    if (packet[0] & 0x80) {
+        return 0;
+    }
+    int frame_size = opus_packet_get_samples_per_frame(packet, 48000);
+    if (frame_size > 960) {
+        nb_frames = frame_size / 960;
+    }
+    int ret = opus_packet_parse(packet, len, NULL, frames, size, NULL);
+    if (ret <= 0) {
+        return ret;
+    }
+    if (size[0] == 0) {
+        return 0;
+    }
+    int lbrr = (frames[0][0] >> (7 - nb_frames)) & 1;
+    if (opus_packet_get_nb_channels(packet) == 2) {
+        lbrr = lbrr || ((frames[0][0] >> (6 - 2 * nb_frames)) & 1);
+    }
+    return lbrr;
+}
 */
 import "C"
 import (
@@ -20,6 +71,10 @@ import (
 	"unsafe"
 )
 
+// ErrUnsupported is returned for features the libopus in use was built
+// without or predates, see Version
+var ErrUnsupported = errors.New("not supported by this libopus")
+
 // OpusApplication constants
 const (
 	OpusApplicationVoIP     = 2048
@@ -35,6 +90,50 @@ const (
 	OPUS_SET_APPLICATION_REQUEST = 4000
 )
 
+// Opus control constants without a method, for CtlInt, CtlGetInt and
+// CtlGetUint32
+const (
+	OPUS_RESET_STATE                  = 4028 // takes no argument, the value of CtlInt is ignored
+	OPUS_GET_BITRATE_REQUEST          = 4003
+	OPUS_SET_MAX_BANDWIDTH_REQUEST    = 4004
+	OPUS_SET_VBR_REQUEST              = 4006
+	OPUS_SET_INBAND_FEC_REQUEST       = 4012
+	OPUS_SET_PACKET_LOSS_PERC_REQUEST = 4014
+	OPUS_SET_DTX_REQUEST              = 4016
+	OPUS_SET_FORCE_CHANNELS_REQUEST   = 4022
+	OPUS_GET_LOOKAHEAD_REQUEST        = 4027
+	OPUS_GET_FINAL_RANGE_REQUEST      = 4031
+	OPUS_SET_GAIN_REQUEST             = 4034
+)
+
+// Private Opus control constants of opus_private.h, subject to change
+// between libopus releases
+const (
+	OPUS_SET_FORCE_MODE_REQUEST = 11002
+
+	// Modes for OPUS_SET_FORCE_MODE_REQUEST
+	MODE_SILK_ONLY = 1000
+	MODE_HYBRID    = 1001
+	MODE_CELT_ONLY = 1002
+)
+
+// Opus control constants of libopus 1.5 and later
+const (
+	OPUS_SET_DRED_DURATION_REQUEST = 4050
+	OPUS_GET_DRED_DURATION_REQUEST = 4051
+	OPUS_SET_DNN_BLOB_REQUEST      = 4052
+)
+
+// Opus bandwidths, for OPUS_SET_MAX_BANDWIDTH_REQUEST
+const (
+	OPUS_AUTO                    = -1000
+	OPUS_BANDWIDTH_NARROWBAND    = 1101 // 4 kHz passband
+	OPUS_BANDWIDTH_MEDIUMBAND    = 1102 // 6 kHz passband
+	OPUS_BANDWIDTH_WIDEBAND      = 1103 // 8 kHz passband
+	OPUS_BANDWIDTH_SUPERWIDEBAND = 1104 // 12 kHz passband
+	OPUS_BANDWIDTH_FULLBAND      = 1105 // 20 kHz passband
+)
+
 // Opus signal types
 const (
 	OPUS_SIGNAL_AUTO  = -1000
@@ -44,12 +143,16 @@ const (
 
 // OpusEncoder represents an Opus encoder
 type OpusEncoder struct {
-	encoder *C.OpusEncoder
+	encoder  *C.OpusEncoder
+	channels int
+	blob     unsafe.Pointer // DNN weights referenced by libopus, see SetDNNBlob
 }
 
 // OpusDecoder represents an Opus decoder
 type OpusDecoder struct {
-	decoder *C.OpusDecoder
+	decoder  *C.OpusDecoder
+	channels int
+	blob     unsafe.Pointer // DNN weights referenced by libopus, see SetDNNBlob
 }
 
 // NewEncoder creates a new Opus encoder
@@ -57,6 +160,9 @@ func NewEncoder(sampleRate int, channels int, application int) (*OpusEncoder, er
 	if sampleRate <= 0 || channels <= 0 || application < 0 {
 		return nil, errors.New("invalid parameter: must be positive")
 	}
+	if err := load(); err != nil {
+		return nil, err
+	}
 
 	var err C.int
 	encoder := C.opus_encoder_create(C.opus_int32(sampleRate), C.int(channels), C.int(application), &err)
@@ -64,7 +170,7 @@ func NewEncoder(sampleRate int, channels int, application int) (*OpusEncoder, er
 		return nil, errors.New(C.GoString(C.opus_strerror(err)))
 	}
 
-	return &OpusEncoder{encoder: encoder}, nil
+	return &OpusEncoder{encoder: encoder, channels: channels}, nil
 }
 
 // SetBitrate sets the bitrate for the encoder
@@ -103,11 +209,71 @@ func (e *OpusEncoder) SetSignal(signal int) error {
 	return nil
 }
 
+// CtlInt performs a ctl request taking an opus_int32 argument, such as the
+// OPUS_SET_* requests. It gives access to requests without a method of
+// their own, including private ones and those of newer libopus releases.
+func (e *OpusEncoder) CtlInt(request int, value int) error {
+	if e.encoder == nil {
+		return errors.New("encoder not initialized")
+	}
+	return opusError(C.go_opus_encoder_ctl_set_int(e.encoder, C.int(request), C.opus_int32(value)))
+}
+
+// CtlGetInt performs a ctl request taking an opus_int32 pointer, such as the
+// OPUS_GET_* requests, and returns the value written
+func (e *OpusEncoder) CtlGetInt(request int) (int, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	var value C.opus_int32
+	err := opusError(C.go_opus_encoder_ctl_get_int(e.encoder, C.int(request), &value))
+	return int(value), err
+}
+
+// CtlGetUint32 performs a ctl request taking an opus_uint32 pointer, such as
+// OPUS_GET_FINAL_RANGE, and returns the value written
+func (e *OpusEncoder) CtlGetUint32(request int) (uint32, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	var value C.opus_uint32
+	err := opusError(C.go_opus_encoder_ctl_get_uint32(e.encoder, C.int(request), &value))
+	return uint32(value), err
+}
+
+// SetDREDDuration sets how much Deep REDundancy the encoder adds to each
+// packet, in units of 10 ms up to 104; 0 disables DRED. It returns
+// ErrUnsupported if libopus was built without DRED.
+func (e *OpusEncoder) SetDREDDuration(duration int) error {
+	return e.CtlInt(OPUS_SET_DRED_DURATION_REQUEST, duration)
+}
+
+// SetDNNBlob loads the weights of the DRED encoder from data, for libopus
+// builds without built-in weights. It returns ErrUnsupported if libopus has
+// built-in weights or no DNN features.
+func (e *OpusEncoder) SetDNNBlob(data []byte) error {
+	if e.encoder == nil {
+		return errors.New("encoder not initialized")
+	}
+	blob, err := setDNNBlob(data, func(cData unsafe.Pointer) C.int {
+		return C.go_opus_encoder_set_dnn_blob(e.encoder, cData, C.opus_int32(len(data)))
+	})
+	if err != nil {
+		return err
+	}
+	C.free(e.blob)
+	e.blob = blob
+	return nil
+}
+
 // NewDecoder creates a new Opus decoder
 func NewDecoder(sampleRate int, channels int) (*OpusDecoder, error) {
 	if sampleRate <= 0 || channels <= 0 {
 		return nil, errors.New("invalid parameter: must be positive")
 	}
+	if err := load(); err != nil {
+		return nil, err
+	}
 
 	var err C.int
 	decoder := C.opus_decoder_create(C.opus_int32(sampleRate), C.int(channels), &err)
@@ -115,7 +281,91 @@ func NewDecoder(sampleRate int, channels int) (*OpusDecoder, error) {
 		return nil, errors.New(C.GoString(C.opus_strerror(err)))
 	}
 
-	return &OpusDecoder{decoder: decoder}, nil
+	return &OpusDecoder{decoder: decoder, channels: channels}, nil
+}
+
+// CtlInt performs a ctl request taking an opus_int32 argument, such as the
+// OPUS_SET_* requests. It gives access to requests without a method of
+// their own, including private ones and those of newer libopus releases.
+func (d *OpusDecoder) CtlInt(request int, value int) error {
+	if d.decoder == nil {
+		return errors.New("decoder not initialized")
+	}
+	return opusError(C.go_opus_decoder_ctl_set_int(d.decoder, C.int(request), C.opus_int32(value)))
+}
+
+// CtlGetInt performs a ctl request taking an opus_int32 pointer, such as the
+// OPUS_GET_* requests, and returns the value written
+func (d *OpusDecoder) CtlGetInt(request int) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	var value C.opus_int32
+	err := opusError(C.go_opus_decoder_ctl_get_int(d.decoder, C.int(request), &value))
+	return int(value), err
+}
+
+// CtlGetUint32 performs a ctl request taking an opus_uint32 pointer, such as
+// OPUS_GET_FINAL_RANGE, and returns the value written
+func (d *OpusDecoder) CtlGetUint32(request int) (uint32, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	var value C.opus_uint32
+	err := opusError(C.go_opus_decoder_ctl_get_uint32(d.decoder, C.int(request), &value))
+	return uint32(value), err
+}
+
+// SetComplexity sets the decoder complexity from 0 to 10. With libopus 1.5
+// and later, 5 and up enable deep packet loss concealment and 6 and up
+// speech enhancement (OSCE) if libopus was built with them. It returns
+// ErrUnsupported with older libopus.
+func (d *OpusDecoder) SetComplexity(complexity int) error {
+	return d.CtlInt(OPUS_SET_COMPLEXITY_REQUEST, complexity)
+}
+
+// SetDNNBlob loads the weights of the deep PLC and OSCE models from data,
+// for libopus builds without built-in weights. It returns ErrUnsupported if
+// libopus has built-in weights or no DNN features.
+func (d *OpusDecoder) SetDNNBlob(data []byte) error {
+	if d.decoder == nil {
+		return errors.New("decoder not initialized")
+	}
+	blob, err := setDNNBlob(data, func(cData unsafe.Pointer) C.int {
+		return C.go_opus_decoder_set_dnn_blob(d.decoder, cData, C.opus_int32(len(data)))
+	})
+	if err != nil {
+		return err
+	}
+	C.free(d.blob)
+	d.blob = blob
+	return nil
+}
+
+// setDNNBlob copies data into C memory and passes it to set. libopus keeps
+// referencing the weights, so the copy is returned to be freed with the
+// encoder or decoder.
+func setDNNBlob(data []byte, set func(cData unsafe.Pointer) C.int) (unsafe.Pointer, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty DNN blob")
+	}
+	cData := C.CBytes(data)
+	if err := opusError(set(cData)); err != nil {
+		C.free(cData)
+		return nil, err
+	}
+	return cData, nil
+}
+
+// opusError converts a libopus return value to an error, nil for OPUS_OK
+func opusError(ret C.int) error {
+	switch ret {
+	case C.OPUS_OK:
+		return nil
+	case C.OPUS_UNIMPLEMENTED:
+		return ErrUnsupported
+	}
+	return errors.New(C.GoString(C.opus_strerror(ret)))
 }
 
 // Encode encodes audio data
@@ -132,7 +382,7 @@ func (e *OpusEncoder) Encode(input []byte, output []byte) (int, error) {
 
 	pcm := (*C.opus_int16)(unsafe.Pointer(&input[0]))
 	data := (*C.uchar)(unsafe.Pointer(&output[0]))
-	frameSize := len(input) / 2 // int16, 单通道
+	frameSize := len(input) / (2 * e.channels) // int16, 每通道采样数
 
 	ret := C.opus_encode(
 		e.encoder,
@@ -169,8 +419,8 @@ func (d *OpusDecoder) Decode(input []byte, output []byte) (int, error) {
 		data,
 		C.opus_int32(len(input)),
 		pcm,
-		C.int(len(output)/2), // 单通道，2字节每采样
-		0,                    // decode_fec
+		C.int(len(output)/(2*d.channels)), // 每通道采样数，2字节每采样
+		0,                                 // decode_fec
 	)
 
 	if ret < 0 {
@@ -180,11 +430,57 @@ func (d *OpusDecoder) Decode(input []byte, output []byte) (int, error) {
 	return int(ret), nil
 }
 
+// DecodePLC conceals frameSize samples per channel of lost audio using packet
+// loss concealment, writing them to output as 16-bit PCM. frameSize must be a
+// multiple of 2.5 ms.
+func (d *OpusDecoder) DecodePLC(output []byte, frameSize int) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	if frameSize <= 0 || len(output) < frameSize*d.channels*2 {
+		return 0, errors.New("output buffer too small")
+	}
+
+	pcm := (*C.opus_int16)(unsafe.Pointer(&output[0]))
+	ret := C.opus_decode(d.decoder, nil, 0, pcm, C.int(frameSize), 0)
+	if ret < 0 {
+		return int(ret), errors.New(C.GoString(C.opus_strerror(C.int(ret))))
+	}
+	return int(ret), nil
+}
+
+// DecodeFEC decodes the frame lost before the packet input from the in-band
+// FEC (LBRR) data input carries, writing frameSize samples per channel to
+// output. frameSize must be the duration of the lost frame and a multiple of
+// 2.5 ms. Without FEC data in input the frame is concealed as by DecodePLC;
+// input itself is decoded by a following Decode call.
+func (d *OpusDecoder) DecodeFEC(input []byte, output []byte, frameSize int) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	if len(input) == 0 {
+		return 0, errors.New("empty input")
+	}
+	if frameSize <= 0 || len(output) < frameSize*d.channels*2 {
+		return 0, errors.New("output buffer too small")
+	}
+
+	data := (*C.uchar)(unsafe.Pointer(&input[0]))
+	pcm := (*C.opus_int16)(unsafe.Pointer(&output[0]))
+	ret := C.opus_decode(d.decoder, data, C.opus_int32(len(input)), pcm, C.int(frameSize), 1)
+	if ret < 0 {
+		return int(ret), errors.New(C.GoString(C.opus_strerror(C.int(ret))))
+	}
+	return int(ret), nil
+}
+
 // Close frees the encoder resources
 func (e *OpusEncoder) Close() {
 	if e.encoder != nil {
 		C.opus_encoder_destroy(e.encoder)
 		e.encoder = nil
+		C.free(e.blob)
+		e.blob = nil
 	}
 }
 
@@ -193,5 +489,84 @@ func (d *OpusDecoder) Close() {
 	if d.decoder != nil {
 		C.opus_decoder_destroy(d.decoder)
 		d.decoder = nil
+		C.free(d.blob)
+		d.blob = nil
+	}
+}
+
+// PacketSamples returns the number of samples per channel in an Opus packet
+func PacketSamples(packet []byte, sampleRate int) (int, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty packet")
+	}
+	if err := load(); err != nil {
+		return 0, err
+	}
+	ret := C.opus_packet_get_nb_samples((*C.uchar)(unsafe.Pointer(&packet[0])), C.opus_int32(len(packet)), C.opus_int32(sampleRate))
+	if ret < 0 {
+		return 0, errors.New(C.GoString(C.opus_strerror(ret)))
+	}
+	return int(ret), nil
+}
+
+// PacketFrames returns the number of frames in an Opus packet
+func PacketFrames(packet []byte) (int, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty packet")
+	}
+	if err := load(); err != nil {
+		return 0, err
+	}
+	ret := C.opus_packet_get_nb_frames((*C.uchar)(unsafe.Pointer(&packet[0])), C.opus_int32(len(packet)))
+	if ret < 0 {
+		return 0, errors.New(C.GoString(C.opus_strerror(ret)))
 	}
+	return int(ret), nil
+}
+
+// PacketSamplesPerFrame returns the number of samples per frame in an Opus packet
+func PacketSamplesPerFrame(packet []byte, sampleRate int) (int, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty packet")
+	}
+	if err := load(); err != nil {
+		return 0, err
+	}
+	return int(C.opus_packet_get_samples_per_frame((*C.uchar)(unsafe.Pointer(&packet[0])), C.opus_int32(sampleRate))), nil
+}
+
+// PacketHasLBRR reports whether an Opus packet carries in-band FEC (LBRR)
+// data for the frame before it, like opus_packet_has_lbrr of libopus 1.5
+// but with any libopus version
+func PacketHasLBRR(packet []byte) (bool, error) {
+	if len(packet) == 0 {
+		return false, errors.New("empty packet")
+	}
+	if err := load(); err != nil {
+		return false, err
+	}
+	ret := C.go_opus_packet_has_lbrr((*C.uchar)(unsafe.Pointer(&packet[0])), C.opus_int32(len(packet)))
+	if ret < 0 {
+		return false, errors.New(C.GoString(C.opus_strerror(ret)))
+	}
+	return ret == 1, nil
+}
+
+// SoftClip applies the soft clipping of opus_pcm_soft_clip in place to the
+// whole frames of interleaved float samples pcm, bending samples beyond ±1
+// smoothly into range. mem holds a state value per channel, zero at first,
+// which carries the clipping across calls. Samples beyond ±2 are clipped hard.
+func SoftClip(pcm []float32, channels int, mem []float32) error {
+	if channels <= 0 || len(mem) < channels {
+		return errors.New("invalid channel count")
+	}
+	frames := len(pcm) / channels
+	if frames == 0 {
+		return nil
+	}
+	if err := load(); err != nil {
+		return err
+	}
+	C.opus_pcm_soft_clip((*C.float)(unsafe.Pointer(&pcm[0])), C.int(frames), C.int(channels), (*C.float)(unsafe.Pointer(&mem[0])))
+	return nil
 }
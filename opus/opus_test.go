@@ -1,7 +1,12 @@
 package opus_test
 
 import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/justa-cai/go-libopus/opus"
 )
@@ -129,3 +134,458 @@ func TestOpusErrorHandling(t *testing.T) {
 		t.Error("Expected error for empty input")
 	}
 }
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version             string
+		major, minor, patch int
+		suffix              string
+		fixed               bool
+	}{
+		{"libopus 1.5.2", 1, 5, 2, "", false},
+		{"libopus 1.3.1-fixed", 1, 3, 1, "", true},
+		{"libopus 1.4-12-g0123abcd", 1, 4, 0, "12-g0123abcd", false},
+		{"libopus 1.3-rc-fixed-fuzzing", 1, 3, 0, "rc", true},
+		{"libopus unknown", 0, 0, 0, "", false},
+	}
+	for _, test := range tests {
+		v := opus.ParseVersion(test.version)
+		if v.String != test.version || v.Major != test.major || v.Minor != test.minor || v.Patch != test.patch ||
+			v.Suffix != test.suffix || v.FixedPoint != test.fixed {
+			t.Errorf("Unexpected parse of %q: %+v", test.version, v)
+		}
+	}
+
+	v := opus.ParseVersion("libopus 1.5.2")
+	if !v.AtLeast(1, 5, 0) || !v.AtLeast(1, 4, 9) || v.AtLeast(1, 5, 3) || v.AtLeast(2, 0, 0) {
+		t.Errorf("Unexpected AtLeast results for %+v", v)
+	}
+}
+
+func TestVersionFeatures(t *testing.T) {
+	v, err := opus.Version()
+	if err != nil {
+		t.Fatalf("Failed to get version: %v", err)
+	}
+	if !strings.HasPrefix(v.String, "libopus ") {
+		t.Errorf("Unexpected version string %q", v.String)
+	}
+	t.Logf("%+v", v)
+
+	encoder, err := opus.NewEncoder(48000, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	decoder, err := opus.NewDecoder(48000, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+
+	// Feature-gated methods report missing features instead of failing otherwise
+	err = encoder.SetDREDDuration(10)
+	if v.DRED && err != nil {
+		t.Errorf("Failed to set DRED duration: %v", err)
+	}
+	if !v.DRED && err != opus.ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported without DRED, got %v", err)
+	}
+	if v.DNNWeights || !v.DRED && !v.OSCE {
+		if err := decoder.SetDNNBlob([]byte{0}); err != opus.ErrUnsupported {
+			t.Errorf("Expected ErrUnsupported for DNN blob, got %v", err)
+		}
+	}
+	err = decoder.SetComplexity(7)
+	if v.OSCE && err != nil {
+		t.Errorf("Failed to set decoder complexity: %v", err)
+	}
+	if err != nil && err != opus.ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported for decoder complexity, got %v", err)
+	}
+}
+
+func TestCtl(t *testing.T) {
+	encoder, err := opus.NewEncoder(48000, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	decoder, err := opus.NewDecoder(48000, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+
+	if err := encoder.SetSignal(opus.OPUS_SIGNAL_VOICE); err != nil {
+		t.Fatalf("Failed to set signal: %v", err)
+	}
+	if err := encoder.CtlInt(opus.OPUS_SET_BITRATE_REQUEST, 24000); err != nil {
+		t.Fatalf("Failed to set bitrate: %v", err)
+	}
+	if bitrate, err := encoder.CtlGetInt(opus.OPUS_GET_BITRATE_REQUEST); err != nil || bitrate != 24000 {
+		t.Errorf("Expected bitrate 24000, got %d (%v)", bitrate, err)
+	}
+
+	// The private force mode request makes a speech signal coded by CELT
+	if err := encoder.CtlInt(opus.OPUS_SET_FORCE_MODE_REQUEST, opus.MODE_CELT_ONLY); err != nil {
+		t.Fatalf("Failed to force CELT mode: %v", err)
+	}
+	input := make([]byte, 960*2)
+	for i := 0; i < 960; i++ {
+		binary.LittleEndian.PutUint16(input[i*2:], uint16(int16(8000*math.Sin(2*math.Pi*440*float64(i)/48000))))
+	}
+	packet := make([]byte, 1500)
+	n, err := encoder.Encode(input, packet)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if config := packet[0] >> 3; config < 16 {
+		t.Errorf("Expected a CELT-only packet, got TOC config %d", config)
+	}
+
+	// Both sides end with the same range coder state
+	if _, err := decoder.Decode(packet[:n], make([]byte, 960*2)); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	encoderRange, err := encoder.CtlGetUint32(opus.OPUS_GET_FINAL_RANGE_REQUEST)
+	if err != nil {
+		t.Fatalf("Failed to get encoder final range: %v", err)
+	}
+	decoderRange, err := decoder.CtlGetUint32(opus.OPUS_GET_FINAL_RANGE_REQUEST)
+	if err != nil {
+		t.Fatalf("Failed to get decoder final range: %v", err)
+	}
+	if encoderRange != decoderRange {
+		t.Errorf("Expected equal final ranges, got %#x and %#x", encoderRange, decoderRange)
+	}
+
+	// Unknown requests are reported, not crashed on
+	if err := encoder.CtlInt(3998, 0); err == nil {
+		t.Error("Expected error for unknown request")
+	}
+	var closed opus.OpusDecoder
+	if _, err := closed.CtlGetInt(opus.OPUS_GET_BITRATE_REQUEST); err == nil {
+		t.Error("Expected error for uninitialized decoder")
+	}
+}
+
+func TestMultistream(t *testing.T) {
+	const channels, frameSize = 6, 960
+	encoder, layout, err := opus.NewMSSurroundEncoder(48000, channels, 1, opus.OpusApplicationAudio)
+	if err != nil {
+		t.Fatalf("Failed to create multistream encoder: %v", err)
+	}
+	defer encoder.Close()
+	// 5.1 is coded as two stereo streams, centre and LFE
+	if layout.Streams != 4 || layout.CoupledStreams != 2 || len(layout.Mapping) != channels {
+		t.Errorf("Unexpected 5.1 layout %+v", layout)
+	}
+	decoder, err := opus.NewMSDecoder(48000, channels, layout)
+	if err != nil {
+		t.Fatalf("Failed to create multistream decoder: %v", err)
+	}
+	defer decoder.Close()
+
+	if err := encoder.CtlInt(opus.OPUS_SET_BITRATE_REQUEST, 256000); err != nil {
+		t.Fatalf("Failed to set bitrate: %v", err)
+	}
+	input := make([]byte, frameSize*channels*2)
+	for i := 0; i < frameSize*channels; i++ {
+		binary.LittleEndian.PutUint16(input[i*2:], uint16(int16(4000*math.Sin(float64(i)/7))))
+	}
+	packet := make([]byte, 4000)
+	n, err := encoder.Encode(input, packet)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	output := make([]byte, frameSize*channels*2)
+	samples, err := decoder.Decode(packet[:n], output)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if samples != frameSize {
+		t.Errorf("Expected %d samples per channel, got %d", frameSize, samples)
+	}
+
+	encoderRange, err := encoder.CtlGetUint32(opus.OPUS_GET_FINAL_RANGE_REQUEST)
+	if err != nil {
+		t.Fatalf("Failed to get encoder final range: %v", err)
+	}
+	decoderRange, err := decoder.CtlGetUint32(opus.OPUS_GET_FINAL_RANGE_REQUEST)
+	if err != nil {
+		t.Fatalf("Failed to get decoder final range: %v", err)
+	}
+	if encoderRange != decoderRange {
+		t.Errorf("Expected equal final ranges, got %#x and %#x", encoderRange, decoderRange)
+	}
+}
+
+func TestProjection(t *testing.T) {
+	const channels, frameSize = 4, 960 // first order ambisonics
+	encoder, layout, err := opus.NewProjectionEncoder(48000, channels, 3, opus.OpusApplicationAudio)
+	if err == opus.ErrUnsupported {
+		t.Skip("libopus has no projection API")
+	}
+	if err != nil {
+		t.Fatalf("Failed to create projection encoder: %v", err)
+	}
+	defer encoder.Close()
+	matrix, err := encoder.DemixingMatrix()
+	if err != nil {
+		t.Fatalf("Failed to get demixing matrix: %v", err)
+	}
+	decoder, err := opus.NewProjectionDecoder(48000, channels, layout, matrix)
+	if err != nil {
+		t.Fatalf("Failed to create projection decoder: %v", err)
+	}
+	defer decoder.Close()
+
+	input := make([]byte, frameSize*channels*2)
+	packet := make([]byte, 4000)
+	n, err := encoder.Encode(input, packet)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if samples, err := decoder.Decode(packet[:n], make([]byte, frameSize*channels*2)); err != nil || samples != frameSize {
+		t.Errorf("Expected %d samples per channel, got %d (%v)", frameSize, samples, err)
+	}
+	if _, err := decoder.CtlGetUint32(opus.OPUS_GET_FINAL_RANGE_REQUEST); err != nil {
+		t.Errorf("Failed to get final range: %v", err)
+	}
+}
+
+func TestPacketHasLBRR(t *testing.T) {
+	for _, fec := range []bool{false, true} {
+		encoder, err := opus.NewEncoder(16000, 1, opus.OpusApplicationVoIP)
+		if err != nil {
+			t.Fatalf("Failed to create encoder: %v", err)
+		}
+		defer encoder.Close()
+		if fec {
+			encoder.CtlInt(opus.OPUS_SET_INBAND_FEC_REQUEST, 1)
+			encoder.CtlInt(opus.OPUS_SET_PACKET_LOSS_PERC_REQUEST, 20)
+		}
+
+		input := make([]byte, 320*2)
+		packet := make([]byte, 1500)
+		withLBRR := 0
+		for frame := 0; frame < 10; frame++ {
+			for i := 0; i < 320; i++ {
+				binary.LittleEndian.PutUint16(input[i*2:], uint16(int16(8000*math.Sin(2*math.Pi*300*float64(frame*320+i)/16000))))
+			}
+			n, err := encoder.Encode(input, packet)
+			if err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+			lbrr, err := opus.PacketHasLBRR(packet[:n])
+			if err != nil {
+				t.Fatalf("Failed to check LBRR: %v", err)
+			}
+			if lbrr {
+				withLBRR++
+			}
+		}
+		// The first packet has no previous frame to protect
+		if fec && withLBRR < 8 || !fec && withLBRR != 0 {
+			t.Errorf("FEC %v: unexpected %d packets with LBRR", fec, withLBRR)
+		}
+	}
+
+	// CELT packets never carry LBRR
+	if lbrr, err := opus.PacketHasLBRR([]byte{0xf8, 0xff, 0xfe}); err != nil || lbrr {
+		t.Errorf("Expected no LBRR in CELT packet, got %v (%v)", lbrr, err)
+	}
+}
+
+func TestFmtp(t *testing.T) {
+	params, err := opus.ParseFmtp("a=fmtp:111 maxplaybackrate=16000; sprop-maxcapturerate=16000;Stereo=1;cbr=1;useinbandfec=1;usedtx=1;ptime=40;minptime=10;x-google-min-bitrate=30")
+	if err != nil {
+		t.Fatalf("Failed to parse fmtp: %v", err)
+	}
+	expected := opus.FmtpParams{
+		MaxPlaybackRate: 16000, SpropMaxCaptureRate: 16000, Stereo: true, CBR: true,
+		UseInbandFEC: true, UseDTX: true, Ptime: 40, MinPtime: 10,
+		Other: map[string]string{"x-google-min-bitrate": "30"},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, params)
+	}
+	if s := params.String(); s != "maxplaybackrate=16000;sprop-maxcapturerate=16000;stereo=1;cbr=1;useinbandfec=1;usedtx=1;ptime=40;minptime=10;x-google-min-bitrate=30" {
+		t.Errorf("Unexpected fmtp %q", s)
+	}
+
+	config := params.EncoderConfig()
+	if config != (opus.EncoderConfig{Channels: 2, MaxBandwidth: opus.OPUS_BANDWIDTH_WIDEBAND, InbandFEC: true, DTX: true, FrameDuration: 40 * time.Millisecond}) {
+		t.Errorf("Unexpected encoder config %+v", config)
+	}
+	if back := config.FmtpParams(); back.MaxPlaybackRate != 16000 || !back.Stereo || !back.CBR || back.Ptime != 40 {
+		t.Errorf("Unexpected fmtp for encoder config %+v", back)
+	}
+	if decoder := params.DecoderConfig(); decoder != (opus.DecoderConfig{SampleRate: 16000, Channels: 1}) {
+		t.Errorf("Unexpected decoder config %+v", decoder)
+	}
+
+	// Defaults: 20 ms unless minptime is longer, and full band
+	defaults, _ := opus.ParseFmtp("minptime=10;useinbandfec=1")
+	if c := defaults.EncoderConfig(); c.FrameDuration != 20*time.Millisecond || c.MaxBandwidth != opus.OPUS_BANDWIDTH_FULLBAND || !c.VBR || c.Channels != 1 {
+		t.Errorf("Unexpected default encoder config %+v", c)
+	}
+	if long, _ := opus.ParseFmtp("minptime=50"); long.EncoderConfig().FrameDuration != 60*time.Millisecond {
+		t.Errorf("Expected 60 ms frames for minptime 50, got %v", long.EncoderConfig().FrameDuration)
+	}
+
+	encoder, err := opus.NewEncoder(48000, 2, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	config.Bitrate = 32000
+	if err := config.Apply(encoder); err != nil {
+		t.Fatalf("Failed to apply encoder config: %v", err)
+	}
+	if bitrate, _ := encoder.CtlGetInt(opus.OPUS_GET_BITRATE_REQUEST); bitrate != 32000 {
+		t.Errorf("Expected bitrate 32000, got %d", bitrate)
+	}
+
+	// Numbers out of range are clamped
+	for line, expected := range map[string]opus.FmtpParams{
+		"maxaveragebitrate=600000":      {MaxAverageBitrate: 510000},
+		"maxaveragebitrate=10":          {MaxAverageBitrate: 6000},
+		"maxplaybackrate=96000":         {MaxPlaybackRate: 48000},
+		"sprop-maxcapturerate=100":      {SpropMaxCaptureRate: 8000},
+		"ptime=0;minptime=999999999999": {Ptime: 1, MinPtime: math.MaxInt32},
+	} {
+		params, err := opus.ParseFmtp(line)
+		if err != nil {
+			t.Errorf("Failed to parse fmtp %q: %v", line, err)
+		} else if !reflect.DeepEqual(params, expected) {
+			t.Errorf("Expected %+v for %q, got %+v", expected, line, params)
+		}
+	}
+
+	for _, bad := range []string{"stereo=2", "maxaveragebitrate=x", "ptime=-5", "minptime="} {
+		if _, err := opus.ParseFmtp(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestAdaptiveController(t *testing.T) {
+	encoder, err := opus.NewEncoder(48000, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	controller, err := opus.NewAdaptiveController(encoder, opus.EncoderSettings{Bitrate: 32000})
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+	if s := controller.Settings(); s.FrameDuration != 20*time.Millisecond {
+		t.Errorf("Expected 20ms frames by default, got %v", s.FrameDuration)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	update := func(second int, bandwidth int, loss float64) opus.EncoderSettings {
+		settings, err := controller.Update(opus.Feedback{
+			Time:         start.Add(time.Duration(second) * time.Second),
+			Bandwidth:    bandwidth,
+			LossFraction: loss,
+		})
+		if err != nil {
+			t.Fatalf("Failed to update controller: %v", err)
+		}
+		return settings
+	}
+
+	// Decreases apply at once, with FEC for the loss
+	if s := update(0, 20000, 0.05); s.Bitrate != 18000 || !s.InbandFEC || s.PacketLossPerc != 5 {
+		t.Errorf("Unexpected settings %+v", s)
+	}
+	if bitrate, _ := encoder.CtlGetInt(opus.OPUS_GET_BITRATE_REQUEST); bitrate != 18000 {
+		t.Errorf("Expected encoder bitrate 18000, got %d", bitrate)
+	}
+	// Small changes are ignored
+	if s := update(1, 21000, 0.05); s.Bitrate != 18000 {
+		t.Errorf("Expected bitrate held, got %d", s.Bitrate)
+	}
+	// Increases wait for the hold time, FEC stays on until loss is below 1%
+	var increased int
+	for second := 2; second < 20; second++ {
+		s := update(second, 40000, 0)
+		if s.Bitrate > 18000 && increased == 0 {
+			increased = second
+		}
+		if second == 3 && !s.InbandFEC {
+			t.Error("Expected FEC kept on within the hysteresis")
+		}
+	}
+	if increased != 3 {
+		t.Errorf("Expected the first increase 3s after the decrease, got %ds", increased)
+	}
+	if s := controller.Settings(); s.Bitrate < 30000 || s.InbandFEC || s.PacketLossPerc != 0 {
+		t.Errorf("Unexpected settings after recovery %+v", s)
+	}
+
+	decisions := controller.Decisions()
+	if len(decisions) != 20 || decisions[0].Previous.Bitrate != 32000 || decisions[0].Settings.Bitrate != 18000 {
+		t.Fatalf("Unexpected decisions %v", decisions)
+	}
+	if !strings.Contains(decisions[1].Reason, "held") || !strings.Contains(decisions[2].String(), "held") {
+		t.Errorf("Expected held bitrate logged, got %q and %q", decisions[1], decisions[2])
+	}
+
+	// Pluggable policy, which may lengthen the frames
+	controller.Policy = opus.PolicyFunc(func(feedback opus.Feedback, current opus.EncoderSettings) opus.EncoderSettings {
+		return opus.EncoderSettings{Bitrate: 8000, FrameDuration: 60 * time.Millisecond}
+	})
+	controller.LogSize = 5
+	if s := update(20, 0, 0); s.Bitrate != 8000 || s.FrameDuration != 60*time.Millisecond {
+		t.Errorf("Unexpected settings from policy %+v", s)
+	}
+	if len(controller.Decisions()) != 5 {
+		t.Errorf("Expected 5 decisions kept, got %d", len(controller.Decisions()))
+	}
+
+	// A held bitrate keeps the frame duration for it: the policy proposes 40ms
+	// frames for 11250 bits per second, within 10% of 12000
+	controller, err = opus.NewAdaptiveController(encoder, opus.EncoderSettings{Bitrate: 12000})
+	if err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+	if s := update(30, 12500, 0); s.Bitrate != 12000 || s.FrameDuration != 20*time.Millisecond {
+		t.Errorf("Expected bitrate and 20ms frames held, got %+v", s)
+	}
+	if d := controller.Decisions()[0]; d.Proposed.FrameDuration != 40*time.Millisecond {
+		t.Errorf("Expected 40ms frames proposed, got %+v", d.Proposed)
+	}
+
+	// From OPUS_AUTO, or zero which means it, the policy starts from the
+	// bitrate the encoder uses: a clean link probes up from it rather than
+	// dropping to the minimum, and a bandwidth estimate applies at once
+	for _, initial := range []int{opus.OPUS_AUTO, 0} {
+		controller, err = opus.NewAdaptiveController(encoder, opus.EncoderSettings{Bitrate: initial})
+		if err != nil {
+			t.Fatalf("Failed to create controller from bitrate %d: %v", initial, err)
+		}
+		if s := controller.Settings(); s.Bitrate != opus.OPUS_AUTO {
+			t.Errorf("Expected OPUS_AUTO for initial bitrate %d, got %d", initial, s.Bitrate)
+		}
+		auto, _ := encoder.CtlGetInt(opus.OPUS_GET_BITRATE_REQUEST)
+		if s := update(40, 0, 0); s.Bitrate != min(auto*115/100, 64000) {
+			t.Errorf("Expected bitrate %d up from auto %d, got %d", min(auto*115/100, 64000), auto, s.Bitrate)
+		}
+		controller, _ = opus.NewAdaptiveController(encoder, opus.EncoderSettings{Bitrate: initial})
+		if s := update(50, 20000, 0); s.Bitrate != 18000 {
+			t.Errorf("Expected bitrate 18000 from auto, got %d", s.Bitrate)
+		}
+		// Within MinChange of the automatic bitrate, OPUS_AUTO is kept
+		controller, _ = opus.NewAdaptiveController(encoder, opus.EncoderSettings{Bitrate: initial})
+		policy := opus.NewDefaultPolicy()
+		policy.MaxBitrate = 510000
+		controller.Policy = policy
+		if s := update(60, auto*105/100*10/9, 0); s.Bitrate != opus.OPUS_AUTO {
+			t.Errorf("Expected OPUS_AUTO held near %d, got %d", auto, s.Bitrate)
+		}
+	}
+}
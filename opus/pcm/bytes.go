@@ -0,0 +1,123 @@
+package pcm
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Int16ToBytesLE packs 16-bit samples into little-endian bytes, the format of
+// OpusEncoder.Encode and OpusDecoder.Decode
+func Int16ToBytesLE(dst []byte, src []int16) int {
+	n := min(len(dst)/2, len(src))
+	for i, v := range src[:n] {
+		binary.LittleEndian.PutUint16(dst[i*2:], uint16(v))
+	}
+	return n
+}
+
+// Int16ToBytesBE packs 16-bit samples into big-endian bytes
+func Int16ToBytesBE(dst []byte, src []int16) int {
+	n := min(len(dst)/2, len(src))
+	for i, v := range src[:n] {
+		binary.BigEndian.PutUint16(dst[i*2:], uint16(v))
+	}
+	return n
+}
+
+// BytesLEToInt16 unpacks little-endian 16-bit samples
+func BytesLEToInt16(dst []int16, src []byte) int {
+	n := min(len(dst), len(src)/2)
+	for i := range dst[:n] {
+		dst[i] = int16(binary.LittleEndian.Uint16(src[i*2:]))
+	}
+	return n
+}
+
+// BytesBEToInt16 unpacks big-endian 16-bit samples
+func BytesBEToInt16(dst []int16, src []byte) int {
+	n := min(len(dst), len(src)/2)
+	for i := range dst[:n] {
+		dst[i] = int16(binary.BigEndian.Uint16(src[i*2:]))
+	}
+	return n
+}
+
+// Int24ToBytesLE packs 24-bit samples, held in the low bits of int32, into 3
+// little-endian bytes each
+func Int24ToBytesLE(dst []byte, src []int32) int {
+	n := min(len(dst)/3, len(src))
+	for i, v := range src[:n] {
+		b := dst[i*3 : i*3+3]
+		b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+	}
+	return n
+}
+
+// Int24ToBytesBE packs 24-bit samples, held in the low bits of int32, into 3
+// big-endian bytes each
+func Int24ToBytesBE(dst []byte, src []int32) int {
+	n := min(len(dst)/3, len(src))
+	for i, v := range src[:n] {
+		b := dst[i*3 : i*3+3]
+		b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v)
+	}
+	return n
+}
+
+// BytesLEToInt24 unpacks packed little-endian 24-bit samples, sign extending
+// them to int32
+func BytesLEToInt24(dst []int32, src []byte) int {
+	n := min(len(dst), len(src)/3)
+	for i := range dst[:n] {
+		b := src[i*3 : i*3+3]
+		dst[i] = int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+	}
+	return n
+}
+
+// BytesBEToInt24 unpacks packed big-endian 24-bit samples, sign extending
+// them to int32
+func BytesBEToInt24(dst []int32, src []byte) int {
+	n := min(len(dst), len(src)/3)
+	for i := range dst[:n] {
+		b := src[i*3 : i*3+3]
+		dst[i] = int32(uint32(b[2])<<8|uint32(b[1])<<16|uint32(b[0])<<24) >> 8
+	}
+	return n
+}
+
+// Float32ToBytesLE packs float samples into little-endian IEEE 754 bytes
+func Float32ToBytesLE(dst []byte, src []float32) int {
+	n := min(len(dst)/4, len(src))
+	for i, v := range src[:n] {
+		binary.LittleEndian.PutUint32(dst[i*4:], math.Float32bits(v))
+	}
+	return n
+}
+
+// Float32ToBytesBE packs float samples into big-endian IEEE 754 bytes
+func Float32ToBytesBE(dst []byte, src []float32) int {
+	n := min(len(dst)/4, len(src))
+	for i, v := range src[:n] {
+		binary.BigEndian.PutUint32(dst[i*4:], math.Float32bits(v))
+	}
+	return n
+}
+
+// BytesLEToFloat32 unpacks little-endian IEEE 754 float samples
+func BytesLEToFloat32(dst []float32, src []byte) int {
+	n := min(len(dst), len(src)/4)
+	for i := range dst[:n] {
+		dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(src[i*4:]))
+	}
+	return n
+}
+
+// BytesBEToFloat32 unpacks big-endian IEEE 754 float samples
+func BytesBEToFloat32(dst []float32, src []byte) int {
+	n := min(len(dst), len(src)/4)
+	for i := range dst[:n] {
+		dst[i] = math.Float32frombits(binary.BigEndian.Uint32(src[i*4:]))
+	}
+	return n
+}
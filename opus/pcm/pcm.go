@@ -0,0 +1,176 @@
+// Package pcm converts audio samples between the formats found around
+// OpusEncoder.Encode and OpusDecoder.Decode: 16-bit, 24-bit and float
+// samples, interleaved and planar channels and little and big-endian bytes.
+// The converters write into buffers given by the caller and never allocate;
+// they convert as many samples as both buffers hold and return the count.
+package pcm
+
+import "math"
+
+// Sample is a sample type the channel layout functions work on
+type Sample interface {
+	~int16 | ~int32 | ~float32
+}
+
+// Interleave interleaves the planes of samples, one per channel, into dst,
+// which gets frames of a sample from each plane in turn. It returns the
+// number of frames written.
+func Interleave[T Sample](dst []T, planes [][]T) int {
+	if len(planes) == 0 {
+		return 0
+	}
+	frames := len(dst) / len(planes)
+	for _, plane := range planes {
+		frames = min(frames, len(plane))
+	}
+	for c, plane := range planes {
+		for i, v := range plane[:frames] {
+			dst[i*len(planes)+c] = v
+		}
+	}
+	return frames
+}
+
+// Deinterleave splits the interleaved frames of src into planes, one per
+// channel, and returns the number of frames written
+func Deinterleave[T Sample](planes [][]T, src []T) int {
+	if len(planes) == 0 {
+		return 0
+	}
+	frames := len(src) / len(planes)
+	for _, plane := range planes {
+		frames = min(frames, len(plane))
+	}
+	for c, plane := range planes {
+		for i := range plane[:frames] {
+			plane[i] = src[i*len(planes)+c]
+		}
+	}
+	return frames
+}
+
+// Int16ToFloat32 converts 16-bit samples to floats with full scale at ±1
+func Int16ToFloat32(dst []float32, src []int16) int {
+	n := min(len(dst), len(src))
+	for i, v := range src[:n] {
+		dst[i] = float32(v) * (1.0 / (1 << 15))
+	}
+	return n
+}
+
+// Float32ToInt16 converts floats with full scale at ±1 to 16-bit samples,
+// rounding them and clipping them to range
+func Float32ToInt16(dst []int16, src []float32) int {
+	n := min(len(dst), len(src))
+	for i, v := range src[:n] {
+		dst[i] = int16(min(max(math.Round(float64(v)*(1<<15)), math.MinInt16), math.MaxInt16))
+	}
+	return n
+}
+
+// Int24ToFloat32 converts 24-bit samples, held in the low bits of int32, to
+// floats with full scale at ±1
+func Int24ToFloat32(dst []float32, src []int32) int {
+	n := min(len(dst), len(src))
+	for i, v := range src[:n] {
+		dst[i] = float32(v) * (1.0 / (1 << 23))
+	}
+	return n
+}
+
+// Float32ToInt24 converts floats with full scale at ±1 to 24-bit samples,
+// held in the low bits of int32, rounding them and clipping them to range
+func Float32ToInt24(dst []int32, src []float32) int {
+	n := min(len(dst), len(src))
+	for i, v := range src[:n] {
+		dst[i] = int32(min(max(math.Round(float64(v)*(1<<23)), -(1<<23)), 1<<23-1))
+	}
+	return n
+}
+
+// Int16ToInt24 widens 16-bit samples to 24 bits
+func Int16ToInt24(dst []int32, src []int16) int {
+	n := min(len(dst), len(src))
+	for i, v := range src[:n] {
+		dst[i] = int32(v) << 8
+	}
+	return n
+}
+
+// Int24ToInt16 narrows 24-bit samples to 16 bits, rounding them
+func Int24ToInt16(dst []int16, src []int32) int {
+	n := min(len(dst), len(src))
+	for i, v := range src[:n] {
+		dst[i] = int16(min((v+0x80)>>8, math.MaxInt16))
+	}
+	return n
+}
+
+// ClipStats counts the samples clipped by a Quantizer
+type ClipStats struct {
+	Samples int64   // samples converted
+	Clipped int64   // samples beyond the range of 16 bits
+	Peak    float32 // largest magnitude of the input
+}
+
+// ClipRate returns the fraction of the samples that were clipped
+func (s ClipStats) ClipRate() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.Clipped) / float64(s.Samples)
+}
+
+// Quantizer converts floats to 16-bit samples like Float32ToInt16, adding
+// dither if enabled and keeping statistics of the clipped samples. The zero
+// value converts without dither.
+type Quantizer struct {
+	// Dither adds triangular (TPDF) dither of ±1 LSB before rounding, which
+	// turns the distortion of quantizing quiet signals into a steady noise
+	// floor
+	Dither bool
+	// Stats accumulates over all conversions until reset by the caller
+	Stats ClipStats
+
+	seed uint32 // xorshift state
+}
+
+// NewQuantizer creates a quantizer with dither
+func NewQuantizer() *Quantizer {
+	return &Quantizer{Dither: true}
+}
+
+// random returns a uniform random number in [0, 1)
+func (q *Quantizer) random() float32 {
+	if q.seed == 0 {
+		q.seed = 0x9e3779b9
+	}
+	q.seed ^= q.seed << 13
+	q.seed ^= q.seed >> 17
+	q.seed ^= q.seed << 5
+	return float32(q.seed>>8) * (1.0 / (1 << 24))
+}
+
+// Float32ToInt16 converts floats with full scale at ±1 to 16-bit samples
+func (q *Quantizer) Float32ToInt16(dst []int16, src []float32) int {
+	n := min(len(dst), len(src))
+	for i, v := range src[:n] {
+		q.Stats.Peak = max(q.Stats.Peak, float32(math.Abs(float64(v))))
+		s := v * (1 << 15)
+		if q.Dither {
+			s += q.random() - q.random()
+		}
+		r := math.Round(float64(s))
+		switch {
+		case r > math.MaxInt16:
+			r = math.MaxInt16
+			q.Stats.Clipped++
+		case r < math.MinInt16:
+			r = math.MinInt16
+			q.Stats.Clipped++
+		}
+		dst[i] = int16(r)
+	}
+	q.Stats.Samples += int64(n)
+	return n
+}
@@ -0,0 +1,227 @@
+package pcm_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/opus/pcm"
+)
+
+func TestConvert(t *testing.T) {
+	ints := []int16{0, 1, -1, 16384, -16384, 32767, -32768}
+	floats := make([]float32, len(ints))
+	if n := pcm.Int16ToFloat32(floats, ints); n != len(ints) {
+		t.Fatalf("Expected %d samples converted, got %d", len(ints), n)
+	}
+	if floats[3] != 0.5 || floats[6] != -1 {
+		t.Errorf("Unexpected float samples %v", floats)
+	}
+	back := make([]int16, len(ints))
+	pcm.Float32ToInt16(back, floats)
+	if !reflect.DeepEqual(back, ints) {
+		t.Errorf("Expected %v back, got %v", ints, back)
+	}
+
+	// Out of range floats clip and the shorter buffer limits the count
+	if n := pcm.Float32ToInt16(back[:2], []float32{1.5, -2, 0}); n != 2 || back[0] != 32767 || back[1] != -32768 {
+		t.Errorf("Expected 2 clipped samples, got %d: %v", n, back[:2])
+	}
+
+	ints24 := make([]int32, len(ints))
+	pcm.Int16ToInt24(ints24, ints)
+	if ints24[5] != 32767<<8 || ints24[6] != -1<<23 {
+		t.Errorf("Unexpected 24-bit samples %v", ints24)
+	}
+	pcm.Int24ToInt16(back, ints24)
+	if !reflect.DeepEqual(back, ints) {
+		t.Errorf("Expected %v back from 24 bits, got %v", ints, back)
+	}
+	pcm.Float32ToInt24(ints24, []float32{0.5, 1, -1})
+	if ints24[0] != 1<<22 || ints24[1] != 1<<23-1 || ints24[2] != -1<<23 {
+		t.Errorf("Unexpected 24-bit samples %v", ints24[:3])
+	}
+	pcm.Int24ToFloat32(floats, ints24[:1])
+	if floats[0] != 0.5 {
+		t.Errorf("Expected 0.5, got %v", floats[0])
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	left, right := []float32{1, 2, 3}, []float32{-1, -2, -3, -4}
+	frames := make([]float32, 8)
+	// The shorter plane limits the frames
+	if n := pcm.Interleave(frames, [][]float32{left, right}); n != 3 {
+		t.Fatalf("Expected 3 frames, got %d", n)
+	}
+	if expected := []float32{1, -1, 2, -2, 3, -3}; !reflect.DeepEqual(frames[:6], expected) {
+		t.Errorf("Expected %v, got %v", expected, frames[:6])
+	}
+	planes := [][]int16{make([]int16, 2), make([]int16, 2)}
+	if n := pcm.Deinterleave(planes, []int16{1, -1, 2, -2, 3}); n != 2 {
+		t.Fatalf("Expected 2 frames, got %d", n)
+	}
+	if !reflect.DeepEqual(planes, [][]int16{{1, 2}, {-1, -2}}) {
+		t.Errorf("Unexpected planes %v", planes)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	ints := []int16{0x0102, -2}
+	b := make([]byte, 4)
+	pcm.Int16ToBytesLE(b, ints)
+	if !bytes.Equal(b, []byte{2, 1, 0xfe, 0xff}) {
+		t.Errorf("Unexpected little-endian bytes % x", b)
+	}
+	back := make([]int16, 2)
+	if pcm.BytesLEToInt16(back, b); !reflect.DeepEqual(back, ints) {
+		t.Errorf("Expected %v back, got %v", ints, back)
+	}
+	pcm.Int16ToBytesBE(b, ints)
+	if !bytes.Equal(b, []byte{1, 2, 0xff, 0xfe}) {
+		t.Errorf("Unexpected big-endian bytes % x", b)
+	}
+	if pcm.BytesBEToInt16(back, b); !reflect.DeepEqual(back, ints) {
+		t.Errorf("Expected %v back, got %v", ints, back)
+	}
+
+	ints24 := []int32{0x010203, -2, -1 << 23}
+	b = make([]byte, 9)
+	pcm.Int24ToBytesLE(b, ints24)
+	if !bytes.Equal(b, []byte{3, 2, 1, 0xfe, 0xff, 0xff, 0, 0, 0x80}) {
+		t.Errorf("Unexpected little-endian 24-bit bytes % x", b)
+	}
+	back24 := make([]int32, 3)
+	if pcm.BytesLEToInt24(back24, b); !reflect.DeepEqual(back24, ints24) {
+		t.Errorf("Expected %v back, got %v", ints24, back24)
+	}
+	pcm.Int24ToBytesBE(b, ints24)
+	if !bytes.Equal(b, []byte{1, 2, 3, 0xff, 0xff, 0xfe, 0x80, 0, 0}) {
+		t.Errorf("Unexpected big-endian 24-bit bytes % x", b)
+	}
+	if pcm.BytesBEToInt24(back24, b); !reflect.DeepEqual(back24, ints24) {
+		t.Errorf("Expected %v back, got %v", ints24, back24)
+	}
+
+	floats := []float32{0.5, -1}
+	b = make([]byte, 8)
+	pcm.Float32ToBytesLE(b, floats)
+	if !bytes.Equal(b, []byte{0, 0, 0, 0x3f, 0, 0, 0x80, 0xbf}) {
+		t.Errorf("Unexpected little-endian float bytes % x", b)
+	}
+	backFloats := make([]float32, 2)
+	if pcm.BytesLEToFloat32(backFloats, b); !reflect.DeepEqual(backFloats, floats) {
+		t.Errorf("Expected %v back, got %v", floats, backFloats)
+	}
+	pcm.Float32ToBytesBE(b, floats)
+	if pcm.BytesBEToFloat32(backFloats, b); b[0] != 0x3f || !reflect.DeepEqual(backFloats, floats) {
+		t.Errorf("Expected %v back from % x, got %v", floats, b, backFloats)
+	}
+}
+
+func TestQuantizer(t *testing.T) {
+	var q pcm.Quantizer
+	out := make([]int16, 4)
+	q.Float32ToInt16(out, []float32{0.5, 1.25, -1.5, 0})
+	if !reflect.DeepEqual(out, []int16{16384, 32767, -32768, 0}) {
+		t.Errorf("Unexpected samples %v", out)
+	}
+	if q.Stats.Samples != 4 || q.Stats.Clipped != 2 || q.Stats.Peak != 1.5 || q.Stats.ClipRate() != 0.5 {
+		t.Errorf("Unexpected statistics %+v", q.Stats)
+	}
+
+	// A tone of a third of an LSB vanishes without dither, and survives
+	// with it as the average of the output
+	const frames = 48000
+	in := make([]float32, frames)
+	for i := range in {
+		in[i] = float32(math.Sin(2*math.Pi*float64(i)/48)) / 3 / (1 << 15)
+	}
+	out = make([]int16, frames)
+	pcm.Float32ToInt16(out, in)
+	for _, v := range out {
+		if v != 0 {
+			t.Fatalf("Expected silence without dither, got %d", v)
+		}
+	}
+	dither := pcm.NewQuantizer()
+	dither.Float32ToInt16(out, in)
+	var correlation, noise float64
+	for i, v := range out {
+		correlation += float64(v) * math.Sin(2*math.Pi*float64(i)/48)
+		noise += float64(v) * float64(v)
+	}
+	// The tone's amplitude is recovered, and the noise stays around the
+	// 1/6 LSB² of the quantization plus 1/6 of TPDF dither
+	if amplitude := 2 * correlation / frames; math.Abs(amplitude-1.0/3) > 0.05 {
+		t.Errorf("Expected dithered tone amplitude 1/3, got %v", amplitude)
+	}
+	if power := noise / frames; power > 0.6 {
+		t.Errorf("Dither noise power %v too high", power)
+	}
+	if dither.Stats.Clipped != 0 {
+		t.Errorf("Unexpected clipping %+v", dither.Stats)
+	}
+}
+
+func TestAllocations(t *testing.T) {
+	floats := make([]float32, 960)
+	ints := make([]int16, 960)
+	b := make([]byte, 960*3)
+	ints24 := make([]int32, 960)
+	planes := [][]float32{make([]float32, 480), make([]float32, 480)}
+	q := pcm.NewQuantizer()
+	allocs := testing.AllocsPerRun(10, func() {
+		pcm.Int16ToFloat32(floats, ints)
+		q.Float32ToInt16(ints, floats)
+		pcm.Int16ToBytesLE(b, ints)
+		pcm.BytesLEToInt16(ints, b)
+		pcm.Float32ToInt24(ints24, floats)
+		pcm.Int24ToBytesBE(b, ints24)
+		pcm.Deinterleave(planes, floats)
+		pcm.Interleave(floats, planes)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func TestSoftClipper(t *testing.T) {
+	clipper, err := pcm.NewSoftClipper(2)
+	if err != nil {
+		t.Fatalf("Failed to create soft clipper: %v", err)
+	}
+	// A loud stereo sine wave, split over two calls
+	samples := make([]float32, 2*480)
+	for i := range samples {
+		samples[i] = float32(1.5 * math.Sin(2*math.Pi*float64(i/2)/96))
+	}
+	quiet := []float32{0.25, -0.25}
+	if err := clipper.Process(samples[:480]); err != nil {
+		t.Fatalf("Failed to soft clip: %v", err)
+	}
+	if err := clipper.Process(samples[480:]); err != nil {
+		t.Fatalf("Failed to soft clip: %v", err)
+	}
+	peak := float32(0)
+	for _, v := range samples {
+		peak = max(peak, float32(math.Abs(float64(v))))
+	}
+	if peak > 1 || peak < 0.9 {
+		t.Errorf("Expected peaks just within ±1, got %v", peak)
+	}
+
+	// Samples in range are left alone once the state is clear
+	clipper.Reset()
+	if err := clipper.Process(quiet); err != nil {
+		t.Fatalf("Failed to soft clip: %v", err)
+	}
+	if quiet[0] != 0.25 || quiet[1] != -0.25 {
+		t.Errorf("Expected quiet samples unchanged, got %v", quiet)
+	}
+
+	if _, err := pcm.NewSoftClipper(0); err == nil {
+		t.Error("Expected error for no channels")
+	}
+}
@@ -0,0 +1,34 @@
+package pcm
+
+import (
+	"errors"
+
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// SoftClipper keeps float audio, such as decoder output, within ±1 with the
+// soft clipping of opus_pcm_soft_clip, which bends peaks smoothly into range
+// instead of flattening them. It keeps per channel state so that a stream can
+// be processed in any number of calls.
+type SoftClipper struct {
+	channels int
+	mem      []float32
+}
+
+// NewSoftClipper creates a soft clipper for interleaved audio with channels
+func NewSoftClipper(channels int) (*SoftClipper, error) {
+	if channels <= 0 {
+		return nil, errors.New("invalid channel count")
+	}
+	return &SoftClipper{channels: channels, mem: make([]float32, channels)}, nil
+}
+
+// Process soft clips the whole frames of interleaved samples pcm in place
+func (c *SoftClipper) Process(pcm []float32) error {
+	return opus.SoftClip(pcm, c.channels, c.mem)
+}
+
+// Reset clears the state, as at the start of a new stream
+func (c *SoftClipper) Reset() {
+	clear(c.mem)
+}
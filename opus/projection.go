@@ -0,0 +1,284 @@
+package opus
+
+/*
+#include <opus_projection.h>
+#include <stddef.h>
+
+// Projection was added in libopus 1.3. With the dlopen build tag the stubs
+// report whether it was found, elsewhere on ELF platforms its functions are
+// weak references so that the package still links against older libopus.
+// The linker does not pull weak references out of a static libopus.a, which
+// therefore reports projection as unsupported.
+#if defined(GO_OPUS_DLOPEN)
+int go_opus_dlopen_has_projection(void);
+static int go_opus_has_projection(void) {
+    return go_opus_dlopen_has_projection();
+}
+#elif defined(__ELF__)
+#pragma weak opus_projection_ambisonics_encoder_create
+#pragma weak opus_projection_encode
+#pragma weak opus_projection_encoder_destroy
+#pragma weak opus_projection_encoder_ctl
+#pragma weak opus_projection_decoder_create
+#pragma weak opus_projection_decode
+#pragma weak opus_projection_decoder_destroy
+#pragma weak opus_projection_decoder_ctl
+static int go_opus_has_projection(void) {
+    return opus_projection_ambisonics_encoder_create != NULL && opus_projection_decoder_create != NULL;
+}
+#else
+static int go_opus_has_projection(void) {
+    return 1;
+}
+#endif
+
+static int go_opus_projection_encoder_ctl_set_int(OpusProjectionEncoder *enc, int request, opus_int32 value) {
+    return opus_projection_encoder_ctl(enc, request, value);
+}
+static int go_opus_projection_encoder_ctl_get_int(OpusProjectionEncoder *enc, int request, opus_int32 *value) {
+    return opus_projection_encoder_ctl(enc, request, value);
+}
+static int go_opus_projection_encoder_ctl_get_uint32(OpusProjectionEncoder *enc, int request, opus_uint32 *value) {
+    return opus_projection_encoder_ctl(enc, request, value);
+}
+static int go_opus_projection_encoder_get_demixing_matrix(OpusProjectionEncoder *enc, unsigned char *matrix, opus_int32 size) {
+    return opus_projection_encoder_ctl(enc, OPUS_PROJECTION_GET_DEMIXING_MATRIX(matrix, size));
+}
+static int go_opus_projection_decoder_ctl_set_int(OpusProjectionDecoder *dec, int request, opus_int32 value) {
+    return opus_projection_decoder_ctl(dec, request, value);
+}
+static int go_opus_projection_decoder_ctl_get_int(OpusProjectionDecoder *dec, int request, opus_int32 *value) {
+    return opus_projection_decoder_ctl(dec, request, value);
+}
+static int go_opus_projection_decoder_ctl_get_uint32(OpusProjectionDecoder *dec, int request, opus_uint32 *value) {
+    return opus_projection_decoder_ctl(dec, request, value);
+}
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// Opus projection control constants
+const (
+	OPUS_PROJECTION_GET_DEMIXING_MATRIX_GAIN_REQUEST = 6001
+	OPUS_PROJECTION_GET_DEMIXING_MATRIX_SIZE_REQUEST = 6003
+	OPUS_PROJECTION_GET_DEMIXING_MATRIX_REQUEST      = 6005
+)
+
+// hasProjection reports whether the loaded libopus has the projection API
+func hasProjection() bool {
+	return C.go_opus_has_projection() != 0
+}
+
+// OpusProjectionEncoder represents an Opus projection encoder, coding
+// ambisonics through a mixing matrix into multistream packets
+type OpusProjectionEncoder struct {
+	encoder  *C.OpusProjectionEncoder
+	channels int
+}
+
+// OpusProjectionDecoder represents an Opus projection decoder
+type OpusProjectionDecoder struct {
+	decoder  *C.OpusProjectionDecoder
+	channels int
+}
+
+// NewProjectionEncoder creates a new Opus projection encoder for ambisonics
+// of the given order, as channels (order+1)² plus optionally 2 for
+// non-diegetic stereo, with mapping family 3. The returned layout has the
+// stream counts but no mapping; the decoder takes the DemixingMatrix
+// instead. It returns ErrUnsupported with libopus older than 1.3.
+func NewProjectionEncoder(sampleRate int, channels int, mappingFamily int, application int) (*OpusProjectionEncoder, StreamLayout, error) {
+	if sampleRate <= 0 || channels <= 0 || application < 0 {
+		return nil, StreamLayout{}, errors.New("invalid parameter: must be positive")
+	}
+	if err := load(); err != nil {
+		return nil, StreamLayout{}, err
+	}
+	if !hasProjection() {
+		return nil, StreamLayout{}, ErrUnsupported
+	}
+
+	var err, streams, coupledStreams C.int
+	encoder := C.opus_projection_ambisonics_encoder_create(C.opus_int32(sampleRate), C.int(channels),
+		C.int(mappingFamily), &streams, &coupledStreams, C.int(application), &err)
+	if err != 0 {
+		return nil, StreamLayout{}, errors.New(C.GoString(C.opus_strerror(err)))
+	}
+
+	layout := StreamLayout{Streams: int(streams), CoupledStreams: int(coupledStreams)}
+	return &OpusProjectionEncoder{encoder: encoder, channels: channels}, layout, nil
+}
+
+// CtlInt performs a ctl request taking an opus_int32 argument, see
+// OpusEncoder.CtlInt
+func (e *OpusProjectionEncoder) CtlInt(request int, value int) error {
+	if e.encoder == nil {
+		return errors.New("encoder not initialized")
+	}
+	return opusError(C.go_opus_projection_encoder_ctl_set_int(e.encoder, C.int(request), C.opus_int32(value)))
+}
+
+// CtlGetInt performs a ctl request taking an opus_int32 pointer and returns
+// the value written, see OpusEncoder.CtlGetInt
+func (e *OpusProjectionEncoder) CtlGetInt(request int) (int, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	var value C.opus_int32
+	err := opusError(C.go_opus_projection_encoder_ctl_get_int(e.encoder, C.int(request), &value))
+	return int(value), err
+}
+
+// CtlGetUint32 performs a ctl request taking an opus_uint32 pointer and
+// returns the value written, see OpusEncoder.CtlGetUint32
+func (e *OpusProjectionEncoder) CtlGetUint32(request int) (uint32, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	var value C.opus_uint32
+	err := opusError(C.go_opus_projection_encoder_ctl_get_uint32(e.encoder, C.int(request), &value))
+	return uint32(value), err
+}
+
+// DemixingMatrix returns the matrix the decoder needs to undo the mixing of
+// the encoder, as stored in the channel mapping table of the OpusHead header
+func (e *OpusProjectionEncoder) DemixingMatrix() ([]byte, error) {
+	size, err := e.CtlGetInt(OPUS_PROJECTION_GET_DEMIXING_MATRIX_SIZE_REQUEST)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		return nil, errors.New("empty demixing matrix")
+	}
+	matrix := make([]byte, size)
+	ret := C.go_opus_projection_encoder_get_demixing_matrix(e.encoder, (*C.uchar)(unsafe.Pointer(&matrix[0])), C.opus_int32(size))
+	if err := opusError(ret); err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
+
+// Encode encodes one frame of interleaved 16-bit PCM of all channels
+func (e *OpusProjectionEncoder) Encode(input []byte, output []byte) (int, error) {
+	if e.encoder == nil {
+		return 0, errors.New("encoder not initialized")
+	}
+	if len(input) == 0 {
+		return 0, errors.New("empty input")
+	}
+	if len(output) == 0 {
+		return 0, errors.New("empty output buffer")
+	}
+
+	pcm := (*C.opus_int16)(unsafe.Pointer(&input[0]))
+	data := (*C.uchar)(unsafe.Pointer(&output[0]))
+	frameSize := len(input) / (2 * e.channels)
+
+	ret := C.opus_projection_encode(e.encoder, pcm, C.int(frameSize), data, C.opus_int32(len(output)))
+	if ret < 0 {
+		return int(ret), errors.New(C.GoString(C.opus_strerror(C.int(ret))))
+	}
+	return int(ret), nil
+}
+
+// Close frees the encoder resources
+func (e *OpusProjectionEncoder) Close() {
+	if e.encoder != nil {
+		C.opus_projection_encoder_destroy(e.encoder)
+		e.encoder = nil
+	}
+}
+
+// NewProjectionDecoder creates a new Opus projection decoder for the stream
+// counts of layout and the demixing matrix of the encoder. It returns
+// ErrUnsupported with libopus older than 1.3.
+func NewProjectionDecoder(sampleRate int, channels int, layout StreamLayout, demixingMatrix []byte) (*OpusProjectionDecoder, error) {
+	if sampleRate <= 0 || channels <= 0 {
+		return nil, errors.New("invalid parameter: must be positive")
+	}
+	if len(demixingMatrix) == 0 {
+		return nil, errors.New("empty demixing matrix")
+	}
+	if err := load(); err != nil {
+		return nil, err
+	}
+	if !hasProjection() {
+		return nil, ErrUnsupported
+	}
+
+	var err C.int
+	matrix := (*C.uchar)(unsafe.Pointer(&demixingMatrix[0]))
+	decoder := C.opus_projection_decoder_create(C.opus_int32(sampleRate), C.int(channels),
+		C.int(layout.Streams), C.int(layout.CoupledStreams), matrix, C.opus_int32(len(demixingMatrix)), &err)
+	if err != 0 {
+		return nil, errors.New(C.GoString(C.opus_strerror(err)))
+	}
+
+	return &OpusProjectionDecoder{decoder: decoder, channels: channels}, nil
+}
+
+// CtlInt performs a ctl request taking an opus_int32 argument, see
+// OpusEncoder.CtlInt
+func (d *OpusProjectionDecoder) CtlInt(request int, value int) error {
+	if d.decoder == nil {
+		return errors.New("decoder not initialized")
+	}
+	return opusError(C.go_opus_projection_decoder_ctl_set_int(d.decoder, C.int(request), C.opus_int32(value)))
+}
+
+// CtlGetInt performs a ctl request taking an opus_int32 pointer and returns
+// the value written, see OpusEncoder.CtlGetInt
+func (d *OpusProjectionDecoder) CtlGetInt(request int) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	var value C.opus_int32
+	err := opusError(C.go_opus_projection_decoder_ctl_get_int(d.decoder, C.int(request), &value))
+	return int(value), err
+}
+
+// CtlGetUint32 performs a ctl request taking an opus_uint32 pointer and
+// returns the value written, see OpusEncoder.CtlGetUint32
+func (d *OpusProjectionDecoder) CtlGetUint32(request int) (uint32, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	var value C.opus_uint32
+	err := opusError(C.go_opus_projection_decoder_ctl_get_uint32(d.decoder, C.int(request), &value))
+	return uint32(value), err
+}
+
+// Decode decodes a packet into interleaved 16-bit PCM of all channels,
+// returning the number of samples per channel
+func (d *OpusProjectionDecoder) Decode(input []byte, output []byte) (int, error) {
+	if d.decoder == nil {
+		return 0, errors.New("decoder not initialized")
+	}
+	if len(input) == 0 {
+		return 0, errors.New("empty input")
+	}
+	if len(output) == 0 {
+		return 0, errors.New("empty output buffer")
+	}
+
+	data := (*C.uchar)(unsafe.Pointer(&input[0]))
+	pcm := (*C.opus_int16)(unsafe.Pointer(&output[0]))
+	frameSize := len(output) / (2 * d.channels)
+
+	ret := C.opus_projection_decode(d.decoder, data, C.opus_int32(len(input)), pcm, C.int(frameSize), 0)
+	if ret < 0 {
+		return int(ret), errors.New(C.GoString(C.opus_strerror(C.int(ret))))
+	}
+	return int(ret), nil
+}
+
+// Close frees the decoder resources
+func (d *OpusProjectionDecoder) Close() {
+	if d.decoder != nil {
+		C.opus_projection_decoder_destroy(d.decoder)
+		d.decoder = nil
+	}
+}
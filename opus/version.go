@@ -0,0 +1,164 @@
+package opus
+
+/*
+#include <stddef.h>
+#include <opus.h>
+
+// go_opus_encoder_knows_dnn_blob and go_opus_decoder_knows_dnn_blob report
+// whether the ctl knows OPUS_SET_DNN_BLOB_REQUEST, which libopus only
+// handles when built to load its weights at run time. A NULL blob is
+// rejected with OPUS_BAD_ARG before anything is loaded.
+static int go_opus_encoder_knows_dnn_blob(OpusEncoder *enc) {
+    return opus_encoder_ctl(enc, OPUS_SET_DNN_BLOB_REQUEST, (const void *)NULL, (opus_int32)0) != OPUS_UNIMPLEMENTED;
+}
+static int go_opus_decoder_knows_dnn_blob(OpusDecoder *dec) {
+    return opus_decoder_ctl(dec, OPUS_SET_DNN_BLOB_REQUEST, (const void *)NULL, (opus_int32)0) != OPUS_UNIMPLEMENTED;
+}
+*/
+import "C"
+import (
+	"encoding/binary"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LibraryVersion describes the libopus in use, as reported by Version
+type LibraryVersion struct {
+	String              string // version string, e.g. "libopus 1.5.2-fixed"
+	Major, Minor, Patch int    // all 0 if the version string has no version
+	Suffix              string // pre-release or git suffix, e.g. "rc1" or "12-g0123abcd"
+
+	FixedPoint bool // fixed-point rather than floating-point build
+	DRED       bool // Deep REDundancy, see SetDREDDuration
+	OSCE       bool // speech enhancement, used at decoder complexity 6 and up
+	DNNWeights bool // DRED or OSCE have built-in weights, SetDNNBlob is not needed
+	Projection bool // ambisonics projection of libopus 1.3 and later, see NewProjectionEncoder
+}
+
+// AtLeast reports whether the version is major.minor.patch or later
+func (v LibraryVersion) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// versionPattern matches versions like 1.5.2, 1.3-rc or 1.4-12-g0123abcd
+var versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?(?:-(.+))?$`)
+
+// ParseVersion parses a libopus version string as returned by
+// opus_get_version_string. The build flags besides FixedPoint are only
+// discovered by Version.
+func ParseVersion(version string) LibraryVersion {
+	v := LibraryVersion{String: version}
+	rest := strings.TrimPrefix(version, "libopus ")
+	// Build suffixes appended after the version
+	for _, suffix := range []string{"-fuzzing", "-fixed"} {
+		if trimmed, ok := strings.CutSuffix(rest, suffix); ok {
+			rest = trimmed
+			v.FixedPoint = v.FixedPoint || suffix == "-fixed"
+		}
+	}
+	m := versionPattern.FindStringSubmatch(rest)
+	if m == nil {
+		return v
+	}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	v.Suffix = m[4]
+	return v
+}
+
+// probedVersion holds the result of probing libopus, done once
+var probedVersion = sync.OnceValue(probeVersion)
+
+// Version returns the version of libopus and the optional features it was
+// built with, discovered by trying them on a throwaway encoder and decoder
+func Version() (LibraryVersion, error) {
+	if err := load(); err != nil {
+		return LibraryVersion{}, err
+	}
+	return probedVersion(), nil
+}
+
+func probeVersion() LibraryVersion {
+	v := ParseVersion(C.GoString(C.opus_get_version_string()))
+
+	// Without DRED and without external weights, the requests are unknown
+	externalWeights := false
+	if encoder, err := NewEncoder(48000, 1, OpusApplicationVoIP); err == nil {
+		_, err := encoder.CtlGetInt(OPUS_GET_DRED_DURATION_REQUEST)
+		v.DRED = err == nil
+		externalWeights = C.go_opus_encoder_knows_dnn_blob(encoder.encoder) != 0
+		encoder.Close()
+	}
+	if decoder, err := NewDecoder(48000, 1); err == nil {
+		externalWeights = externalWeights || C.go_opus_decoder_knows_dnn_blob(decoder.decoder) != 0
+		decoder.Close()
+	}
+	v.OSCE = probeOSCE()
+	v.Projection = hasProjection()
+	v.DNNWeights = (v.DRED || v.OSCE) && !externalWeights
+	return v
+}
+
+// probeOSCE reports whether decoding SILK at complexity 6 and up changes the
+// output, which only OSCE does
+func probeOSCE() bool {
+	const frameSize = 960
+	encoder, err := NewEncoder(48000, 1, OpusApplicationVoIP)
+	if err != nil {
+		return false
+	}
+	defer encoder.Close()
+	encoder.SetBitrate(16000)
+	encoder.SetSignal(OPUS_SIGNAL_VOICE)
+	encoder.CtlInt(C.OPUS_SET_MAX_BANDWIDTH_REQUEST, C.OPUS_BANDWIDTH_WIDEBAND)
+
+	var decoders [2]*OpusDecoder
+	for i, complexity := range []int{0, 7} {
+		if decoders[i], err = NewDecoder(48000, 1); err != nil {
+			return false
+		}
+		defer decoders[i].Close()
+		// Decoder complexity was added together with OSCE in libopus 1.5
+		if decoders[i].SetComplexity(complexity) != nil {
+			return false
+		}
+	}
+
+	input := make([]byte, frameSize*2)
+	packet := make([]byte, 1500)
+	outputs := [2][]byte{make([]byte, frameSize*2), make([]byte, frameSize*2)}
+	for frame := 0; frame < 10; frame++ {
+		// A voiced sound: a 150 Hz tone with harmonics
+		for i := 0; i < frameSize; i++ {
+			t := float64(frame*frameSize+i) / 48000
+			sample := 0.0
+			for h := 1; h <= 8; h++ {
+				sample += math.Sin(2*math.Pi*150*float64(h)*t) / float64(h)
+			}
+			binary.LittleEndian.PutUint16(input[i*2:], uint16(int16(sample*8000)))
+		}
+		n, err := encoder.Encode(input, packet)
+		if err != nil {
+			return false
+		}
+		for i, decoder := range decoders {
+			if _, err := decoder.Decode(packet[:n], outputs[i]); err != nil {
+				return false
+			}
+		}
+		if string(outputs[0]) != string(outputs[1]) {
+			return true
+		}
+	}
+	return false
+}
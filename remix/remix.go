@@ -0,0 +1,233 @@
+// Package remix converts interleaved audio between channel layouts with mixing
+// matrices, such as a 5.1 capture into stereo before encoding or mono decoder
+// output into stereo for playback. The standard layouts follow the Vorbis
+// channel order used by Ogg Opus channel mapping family 1.
+package remix
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// speaker is a loudspeaker position of a standard layout
+type speaker int
+
+const (
+	frontLeft speaker = iota
+	frontRight
+	frontCenter
+	lfe
+	sideLeft
+	sideRight
+	rearLeft
+	rearRight
+	rearCenter
+)
+
+// layouts are the speakers of each channel count in Vorbis channel order.
+// Mono is a center speaker.
+var layouts = [][]speaker{
+	1: {frontCenter},
+	2: {frontLeft, frontRight},
+	3: {frontLeft, frontCenter, frontRight},
+	4: {frontLeft, frontRight, rearLeft, rearRight},
+	5: {frontLeft, frontCenter, frontRight, rearLeft, rearRight},
+	6: {frontLeft, frontCenter, frontRight, rearLeft, rearRight, lfe},
+	7: {frontLeft, frontCenter, frontRight, sideLeft, sideRight, rearCenter, lfe},
+	8: {frontLeft, frontCenter, frontRight, sideLeft, sideRight, rearLeft, rearRight, lfe},
+}
+
+// minus3dB is the gain of a speaker shared between two others
+const minus3dB = math.Sqrt2 / 2
+
+// fallbacks are where the signal of a speaker missing from the output goes,
+// in order of preference: the first group of speakers all present in the
+// output gets it, the gain split equally in power
+var fallbacks = map[speaker][][]speaker{
+	frontLeft:   {{frontCenter}},
+	frontRight:  {{frontCenter}},
+	frontCenter: {{frontLeft, frontRight}},
+	sideLeft:    {{rearLeft}, {frontLeft}, {frontCenter}},
+	sideRight:   {{rearRight}, {frontRight}, {frontCenter}},
+	rearLeft:    {{sideLeft}, {frontLeft}, {frontCenter}},
+	rearRight:   {{sideRight}, {frontRight}, {frontCenter}},
+	rearCenter:  {{rearLeft, rearRight}, {sideLeft, sideRight}, {frontLeft, frontRight}, {frontCenter}},
+}
+
+// Standard returns the mixing matrix from the standard layout of in channels
+// to that of out channels, both from 1 to 8. Speakers present in both layouts
+// are copied, and the others mixed into their nearest neighbors at -3 dB per
+// pair, except for the LFE channel, which is dropped. Mono input goes to the
+// center speaker, or at full level to both front speakers of layouts without
+// one. Rows whose gains add up to more than 1 are scaled down so that the
+// output cannot clip.
+func Standard(in, out int) ([][]float32, error) {
+	if in < 1 || in >= len(layouts) || out < 1 || out >= len(layouts) {
+		return nil, fmt.Errorf("no standard layout for %d to %d channels", in, out)
+	}
+	present := make(map[speaker]int)
+	for i, s := range layouts[out] {
+		present[s] = i
+	}
+
+	matrix := make([][]float64, out)
+	for i := range matrix {
+		matrix[i] = make([]float64, in)
+	}
+	for j, s := range layouts[in] {
+		if i, ok := present[s]; ok {
+			matrix[i][j] = 1
+			continue
+		}
+		if in == 1 {
+			// Mono played on both front speakers keeps its level on each
+			matrix[0][j], matrix[1][j] = 1, 1
+			continue
+		}
+		for _, group := range fallbacks[s] {
+			found := true
+			for _, t := range group {
+				if _, ok := present[t]; !ok {
+					found = false
+				}
+			}
+			if !found {
+				continue
+			}
+			gain := 1.0
+			if len(group) == 2 {
+				gain = minus3dB
+			}
+			for _, t := range group {
+				matrix[present[t]][j] += gain
+			}
+			break
+		}
+	}
+
+	result := make([][]float32, out)
+	for i, row := range matrix {
+		sum := 0.0
+		for _, gain := range row {
+			sum += math.Abs(gain)
+		}
+		scale := 1.0
+		if sum > 1 {
+			scale = 1 / sum
+		}
+		result[i] = make([]float32, in)
+		for j, gain := range row {
+			result[i][j] = float32(gain * scale)
+		}
+	}
+	return result, nil
+}
+
+// Remixer mixes interleaved frames of one channel count into another
+type Remixer struct {
+	in, out int
+	matrix  []float32 // out rows of in gains
+}
+
+// New creates a remixer from a matrix of gains with a row for each output
+// channel and a column for each input channel
+func New(matrix [][]float32) (*Remixer, error) {
+	if len(matrix) == 0 || len(matrix[0]) == 0 {
+		return nil, errors.New("empty matrix")
+	}
+	r := &Remixer{in: len(matrix[0]), out: len(matrix)}
+	for _, row := range matrix {
+		if len(row) != r.in {
+			return nil, errors.New("matrix rows differ in length")
+		}
+		r.matrix = append(r.matrix, row...)
+	}
+	return r, nil
+}
+
+// NewStandard creates a remixer with the Standard matrix from in to out
+// channels
+func NewStandard(in, out int) (*Remixer, error) {
+	matrix, err := Standard(in, out)
+	if err != nil {
+		return nil, err
+	}
+	return New(matrix)
+}
+
+// InputChannels returns the number of channels of the input
+func (r *Remixer) InputChannels() int {
+	return r.in
+}
+
+// OutputChannels returns the number of channels of the output
+func (r *Remixer) OutputChannels() int {
+	return r.out
+}
+
+// Matrix returns a copy of the mixing matrix
+func (r *Remixer) Matrix() [][]float32 {
+	matrix := make([][]float32, r.out)
+	for i := range matrix {
+		matrix[i] = append([]float32(nil), r.matrix[i*r.in:(i+1)*r.in]...)
+	}
+	return matrix
+}
+
+// mix computes output channel i of a frame
+func (r *Remixer) mix(frame []float32, i int) float32 {
+	sum := float32(0)
+	for j, gain := range r.matrix[i*r.in : (i+1)*r.in] {
+		sum += gain * frame[j]
+	}
+	return sum
+}
+
+// Process mixes the whole frames of interleaved samples in, appending the
+// output frames to out and returning it
+func (r *Remixer) Process(out, in []float32) []float32 {
+	for n := 0; n+r.in <= len(in); n += r.in {
+		frame := in[n : n+r.in]
+		for i := 0; i < r.out; i++ {
+			out = append(out, r.mix(frame, i))
+		}
+	}
+	return out
+}
+
+// ProcessInt16 mixes interleaved 16-bit samples like Process, rounding and
+// clipping the output
+func (r *Remixer) ProcessInt16(out, in []int16) []int16 {
+	frame := make([]float32, r.in)
+	for n := 0; n+r.in <= len(in); n += r.in {
+		for j, v := range in[n : n+r.in] {
+			frame[j] = float32(v)
+		}
+		for i := 0; i < r.out; i++ {
+			out = append(out, clip(r.mix(frame, i)))
+		}
+	}
+	return out
+}
+
+// ProcessPCM16 mixes interleaved little-endian 16-bit PCM, as taken by
+// OpusEncoder.Encode and produced by OpusDecoder.Decode, like ProcessInt16
+func (r *Remixer) ProcessPCM16(out, in []byte) []byte {
+	frame := make([]float32, r.in)
+	for n := 0; n+r.in*2 <= len(in); n += r.in * 2 {
+		for j := range frame {
+			frame[j] = float32(int16(binary.LittleEndian.Uint16(in[n+j*2:])))
+		}
+		for i := 0; i < r.out; i++ {
+			out = binary.LittleEndian.AppendUint16(out, uint16(clip(r.mix(frame, i))))
+		}
+	}
+	return out
+}
+
+// clip rounds a sample to 16 bits, clipping it
+func clip(v float32) int16 {
+	return int16(min(max(math.Round(float64(v)), math.MinInt16), math.MaxInt16))
+}
@@ -0,0 +1,126 @@
+package remix_test
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/remix"
+)
+
+func TestStandard(t *testing.T) {
+	const h = float32(math.Sqrt2 / 2)
+	for _, test := range []struct {
+		in, out int
+		matrix  [][]float32
+	}{
+		{1, 2, [][]float32{{1}, {1}}},
+		{2, 1, [][]float32{{0.5, 0.5}}},
+		{2, 2, [][]float32{{1, 0}, {0, 1}}},
+		{1, 6, [][]float32{{0}, {1}, {0}, {0}, {0}, {0}}},
+		{2, 6, [][]float32{{1, 0}, {0, 0}, {0, 1}, {0, 0}, {0, 0}, {0, 0}}},
+		// FL C FR RL RR LFE: the center at -3 dB on both sides, the rear on
+		// its side and no LFE, scaled to unity gain
+		{6, 2, [][]float32{
+			{1 / (2 + h), h / (2 + h), 0, 1 / (2 + h), 0, 0},
+			{0, h / (2 + h), 1 / (2 + h), 0, 1 / (2 + h), 0},
+		}},
+	} {
+		matrix, err := remix.Standard(test.in, test.out)
+		if err != nil {
+			t.Fatalf("Failed to get %d to %d matrix: %v", test.in, test.out, err)
+		}
+		for i := range matrix {
+			for j := range matrix[i] {
+				if math.Abs(float64(matrix[i][j]-test.matrix[i][j])) > 1e-6 {
+					t.Errorf("%d to %d: expected %v, got %v", test.in, test.out, test.matrix, matrix)
+				}
+			}
+		}
+	}
+
+	for in := 1; in <= 8; in++ {
+		for out := 1; out <= 8; out++ {
+			matrix, err := remix.Standard(in, out)
+			if err != nil {
+				t.Fatalf("Failed to get %d to %d matrix: %v", in, out, err)
+			}
+			if len(matrix) != out || len(matrix[0]) != in {
+				t.Fatalf("%d to %d: matrix is %dx%d", in, out, len(matrix), len(matrix[0]))
+			}
+			// No output clips and every channel is heard, but the LFE only
+			// in layouts with one
+			heard := make([]bool, in)
+			for _, row := range matrix {
+				sum := float32(0)
+				for j, gain := range row {
+					sum += gain
+					heard[j] = heard[j] || gain > 0
+				}
+				if sum > 1+1e-6 {
+					t.Errorf("%d to %d: row %v adds up to %v", in, out, row, sum)
+				}
+			}
+			for j, ok := range heard {
+				lfe := in >= 6 && j == in-1
+				if ok != (!lfe || out >= 6) {
+					t.Errorf("%d to %d: channel %d heard %v", in, out, j, ok)
+				}
+			}
+		}
+	}
+
+	if _, err := remix.Standard(9, 2); err == nil {
+		t.Error("Expected error for 9 channels")
+	}
+}
+
+func TestRemixer(t *testing.T) {
+	r, err := remix.New([][]float32{{1, 1}, {0.5, -0.5}, {2, 0}})
+	if err != nil {
+		t.Fatalf("Failed to create remixer: %v", err)
+	}
+	if r.InputChannels() != 2 || r.OutputChannels() != 3 {
+		t.Errorf("Expected 2 to 3 channels, got %d to %d", r.InputChannels(), r.OutputChannels())
+	}
+
+	// A trailing partial frame is ignored
+	out := r.Process(nil, []float32{0.25, 0.5, -1, 1, 0.5})
+	if expected := []float32{0.75, -0.125, 0.5, 0, -1, -2}; !reflect.DeepEqual(out, expected) {
+		t.Errorf("Expected %v, got %v", expected, out)
+	}
+	// Integer output saturates
+	out16 := r.ProcessInt16(nil, []int16{20000, 20000, -20000, 1})
+	if expected := []int16{32767, 0, 32767, -19999, -10001, -32768}; !reflect.DeepEqual(out16, expected) {
+		t.Errorf("Expected %v, got %v", expected, out16)
+	}
+	pcm := make([]byte, 8)
+	for i, v := range []int16{20000, 20000, -20000, 1} {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(v))
+	}
+	outPCM := r.ProcessPCM16(nil, pcm)
+	for i, v := range out16 {
+		if int16(binary.LittleEndian.Uint16(outPCM[i*2:])) != v {
+			t.Fatalf("Expected PCM %v, got % x", out16, outPCM)
+		}
+	}
+
+	if _, err := remix.New([][]float32{{1, 0}, {1}}); err == nil {
+		t.Error("Expected error for a ragged matrix")
+	}
+
+	// Mono to stereo and back is lossless
+	up, err := remix.NewStandard(1, 2)
+	if err != nil {
+		t.Fatalf("Failed to create remixer: %v", err)
+	}
+	down, err := remix.NewStandard(2, 1)
+	if err != nil {
+		t.Fatalf("Failed to create remixer: %v", err)
+	}
+	mono := []int16{0, 1, -1, 32767, -32768, 12345}
+	if back := down.ProcessInt16(nil, up.ProcessInt16(nil, mono)); !reflect.DeepEqual(back, mono) {
+		t.Errorf("Expected %v back, got %v", mono, back)
+	}
+}
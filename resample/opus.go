@@ -0,0 +1,160 @@
+package resample
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// Encoder encodes audio at any sample rate with an OpusEncoder, resampling
+// it to the rate of the encoder and cutting it into frames
+type Encoder struct {
+	encoder   *opus.OpusEncoder
+	resampler *Resampler
+	channels  int
+	frameSize int     // samples per channel per frame at the encoder rate
+	pending   []int16 // resampled samples short of a frame
+	pcm       []byte
+	packet    []byte
+}
+
+// NewEncoder creates an encoder taking interleaved 16-bit PCM at inputRate
+// and encoding frames of frameSize samples per channel with encoder, which
+// was created with encoderRate and channels
+func NewEncoder(encoder *opus.OpusEncoder, encoderRate, inputRate, channels, frameSize int, quality Quality) (*Encoder, error) {
+	if frameSize <= 0 {
+		return nil, errors.New("invalid frame size")
+	}
+	resampler, err := New(inputRate, encoderRate, channels, quality)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{
+		encoder:   encoder,
+		resampler: resampler,
+		channels:  channels,
+		frameSize: frameSize,
+		pcm:       make([]byte, frameSize*channels*2),
+		packet:    make([]byte, 4000),
+	}, nil
+}
+
+// Encode takes interleaved little-endian 16-bit PCM at the input rate and
+// returns the packets of the frames completed by it, if any
+func (e *Encoder) Encode(input []byte) ([][]byte, error) {
+	samples := make([]int16, len(input)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(input[i*2:]))
+	}
+	e.pending = e.resampler.ProcessInt16(e.pending, samples)
+	return e.encodeFrames()
+}
+
+// Flush encodes the rest of the input, padding the last frame with silence
+func (e *Encoder) Flush() ([][]byte, error) {
+	e.pending = e.resampler.FlushInt16(e.pending)
+	if n := len(e.pending) % (e.frameSize * e.channels); n != 0 {
+		e.pending = append(e.pending, make([]int16, e.frameSize*e.channels-n)...)
+	}
+	return e.encodeFrames()
+}
+
+// encodeFrames encodes the complete frames pending
+func (e *Encoder) encodeFrames() ([][]byte, error) {
+	var packets [][]byte
+	size := e.frameSize * e.channels
+	n := 0
+	for ; n+size <= len(e.pending); n += size {
+		for i, v := range e.pending[n : n+size] {
+			binary.LittleEndian.PutUint16(e.pcm[i*2:], uint16(v))
+		}
+		length, err := e.encoder.Encode(e.pcm, e.packet)
+		if err != nil {
+			return packets, err
+		}
+		packets = append(packets, append([]byte(nil), e.packet[:length]...))
+	}
+	e.pending = e.pending[:copy(e.pending, e.pending[n:])]
+	return packets, nil
+}
+
+// Decoder decodes Opus packets with an OpusDecoder and resamples the audio
+// to any output rate
+type Decoder struct {
+	decoder   *opus.OpusDecoder
+	resampler *Resampler
+	channels  int
+	pcm       []byte
+	samples   []int16
+	out       []int16
+	output    []byte
+}
+
+// NewDecoder creates a decoder producing interleaved 16-bit PCM at
+// outputRate from decoder, which was created with decoderRate and channels
+func NewDecoder(decoder *opus.OpusDecoder, decoderRate, outputRate, channels int, quality Quality) (*Decoder, error) {
+	resampler, err := New(decoderRate, outputRate, channels, quality)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{
+		decoder:   decoder,
+		resampler: resampler,
+		channels:  channels,
+		// Room for the longest Opus packet, 120 ms
+		pcm: make([]byte, decoderRate*120/1000*channels*2),
+	}, nil
+}
+
+// Decode decodes packet and returns the interleaved little-endian 16-bit
+// PCM at the output rate available so far, valid until the next call. The
+// resampler holds back half a filter length of samples, so the output of a
+// packet lags slightly behind; Flush returns the rest at the end of the
+// stream.
+func (d *Decoder) Decode(packet []byte) ([]byte, error) {
+	n, err := d.decoder.Decode(packet, d.pcm)
+	if err != nil {
+		return nil, err
+	}
+	return d.resample(d.pcm[:n*d.channels*2]), nil
+}
+
+// DecodePLC conceals frameSize samples per channel, at the decoder rate, of
+// lost audio and returns it like Decode
+func (d *Decoder) DecodePLC(frameSize int) ([]byte, error) {
+	n, err := d.decoder.DecodePLC(d.pcm, frameSize)
+	if err != nil {
+		return nil, err
+	}
+	return d.resample(d.pcm[:n*d.channels*2]), nil
+}
+
+// Flush returns the PCM held back by the resampler at the end of the
+// stream, like Decode. The decoder takes no more packets afterwards.
+func (d *Decoder) Flush() []byte {
+	d.out = d.resampler.FlushInt16(d.out[:0])
+	return d.output16()
+}
+
+// resample converts decoded PCM to the output rate
+func (d *Decoder) resample(pcm []byte) []byte {
+	d.samples = d.samples[:0]
+	for i := 0; i+1 < len(pcm); i += 2 {
+		d.samples = append(d.samples, int16(binary.LittleEndian.Uint16(pcm[i:])))
+	}
+	d.out = d.resampler.ProcessInt16(d.out[:0], d.samples)
+	return d.output16()
+}
+
+// output16 converts the resampled samples to little-endian bytes
+func (d *Decoder) output16() []byte {
+	if cap(d.output) < len(d.out)*2 {
+		d.output = make([]byte, len(d.out)*2)
+	}
+	d.output = d.output[:len(d.out)*2]
+	for i, v := range d.out {
+		binary.LittleEndian.PutUint16(d.output[i*2:], uint16(v))
+	}
+	return d.output
+}
@@ -0,0 +1,238 @@
+// Package resample converts audio between sample rates with a polyphase
+// windowed sinc filter, so that audio at rates Opus does not support, such
+// as 44.1 kHz, can be encoded, and decoded audio played at any rate
+package resample
+
+import (
+	"errors"
+	"math"
+)
+
+// Quality selects the length and steepness of the filter
+type Quality int
+
+// Quality levels, trading bandwidth and stopband attenuation for speed. The
+// passband is flat within 0.1 dB.
+const (
+	QualityLow    Quality = iota // 16 taps, passband to 65% of Nyquist, 50 dB stopband
+	QualityMedium                // 32 taps, passband to 75% of Nyquist, 70 dB stopband
+	QualityHigh                  // 64 taps, passband to 85% of Nyquist, 95 dB stopband
+)
+
+// filterParams are the taps per phase, Kaiser window beta and cutoff, as a
+// fraction of the lower Nyquist frequency, of each quality. The cutoff
+// places the end of the transition band at Nyquist.
+var filterParams = map[Quality]struct {
+	taps   int
+	beta   float64
+	cutoff float64
+}{
+	QualityLow:    {16, 4.55, 0.82},
+	QualityMedium: {32, 6.76, 0.87},
+	QualityHigh:   {64, 9.5, 0.905},
+}
+
+// Resampler converts interleaved audio from one sample rate to another.
+// Output sample k is the input interpolated at time k / outRate, so the
+// output is aligned with the input, but it is only produced once the input
+// reaches half the filter length past it; Flush produces the rest.
+type Resampler struct {
+	channels int
+	up, down int       // output rate is input rate * up / down
+	taps     int       // filter taps per phase
+	filter   []float32 // up phases of taps coefficients
+
+	buf     []float32 // input frames from the first one still needed
+	index   int       // frame in buf of the next output frame
+	phase   int       // fraction of the next output frame position, in 1/up
+	partial []float32 // samples of an input frame split across calls
+	inputs  int64     // input frames received
+	outputs int64     // output frames produced
+	flushed bool
+}
+
+// New creates a resampler from inRate to outRate for interleaved audio
+// with channels
+func New(inRate, outRate, channels int, quality Quality) (*Resampler, error) {
+	params, ok := filterParams[quality]
+	if !ok {
+		return nil, errors.New("invalid quality")
+	}
+	if inRate <= 0 || outRate <= 0 {
+		return nil, errors.New("invalid sample rate")
+	}
+	if channels <= 0 {
+		return nil, errors.New("invalid channel count")
+	}
+
+	g := gcd(inRate, outRate)
+	r := &Resampler{
+		channels: channels,
+		up:       outRate / g,
+		down:     inRate / g,
+	}
+
+	// When downsampling the cutoff moves down to the output Nyquist
+	// frequency and the filter stretches accordingly
+	cutoff := params.cutoff
+	scale := 1.0
+	switch {
+	case r.up == r.down:
+		// At the same rate a full band sinc is zero at every input frame
+		// but the current one, passing the input through unchanged
+		cutoff = 1
+	case r.down > r.up:
+		scale = float64(r.up) / float64(r.down)
+		cutoff *= scale
+	}
+	half := int(math.Ceil(float64(params.taps) / 2 / scale))
+	r.taps = 2 * half
+
+	r.filter = make([]float32, r.up*r.taps)
+	for p := 0; p < r.up; p++ {
+		for j := 0; j < r.taps; j++ {
+			// Distance of input frame j from the output position, in input frames
+			t := float64(p)/float64(r.up) + float64(half-1-j)
+			r.filter[p*r.taps+j] = float32(cutoff * sinc(cutoff*t) * kaiser(t/float64(half), params.beta))
+		}
+	}
+
+	// Zeros before the start, so that the first output frame is at time 0
+	r.buf = make([]float32, (half-1)*channels)
+	r.index = half - 1
+	return r, nil
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// sinc returns sin(πx)/(πx)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// kaiser returns the Kaiser window of beta at x in [-1, 1]
+func kaiser(x, beta float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return besselI0(beta*math.Sqrt(1-x*x)) / besselI0(beta)
+}
+
+// besselI0 returns the zeroth order modified Bessel function of the first
+// kind, by its power series
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > sum*1e-12; k++ {
+		term *= (x / 2) * (x / 2) / float64(k*k)
+		sum += term
+	}
+	return sum
+}
+
+// Process resamples interleaved samples, appending the output frames
+// available so far to out and returning it. in need not hold whole frames:
+// the samples of a frame split across calls are kept until it is complete.
+func (r *Resampler) Process(out, in []float32) []float32 {
+	if r.flushed {
+		return out
+	}
+	if len(r.partial) > 0 {
+		n := min(r.channels-len(r.partial), len(in))
+		r.partial = append(r.partial, in[:n]...)
+		in = in[n:]
+		if len(r.partial) < r.channels {
+			return out
+		}
+		r.buf = append(r.buf, r.partial...)
+		r.inputs++
+		r.partial = r.partial[:0]
+	}
+	whole := len(in) - len(in)%r.channels
+	r.buf = append(r.buf, in[:whole]...)
+	r.inputs += int64(whole / r.channels)
+	r.partial = append(r.partial, in[whole:]...)
+	return r.run(out, -1)
+}
+
+// Flush resamples the end of the input, appending the remaining output
+// frames to out. An incomplete last input frame is dropped. The resampler
+// takes no more input afterwards.
+func (r *Resampler) Flush(out []float32) []float32 {
+	if r.flushed {
+		return out
+	}
+	r.flushed = true
+	// Zeros after the end, up to the last output frame
+	r.buf = append(r.buf, make([]float32, r.taps/2*r.channels)...)
+	total := (r.inputs*int64(r.up) + int64(r.down) - 1) / int64(r.down)
+	return r.run(out, total)
+}
+
+// run produces output frames while the input lasts, up to limit in total
+// unless limit is negative
+func (r *Resampler) run(out []float32, limit int64) []float32 {
+	frames := len(r.buf) / r.channels
+	half := r.taps / 2
+	for r.index+half < frames && (limit < 0 || r.outputs < limit) {
+		coefs := r.filter[r.phase*r.taps : (r.phase+1)*r.taps]
+		start := (r.index - half + 1) * r.channels
+		for c := 0; c < r.channels; c++ {
+			sum := float32(0)
+			in := r.buf[start+c:]
+			for j, coef := range coefs {
+				sum += in[j*r.channels] * coef
+			}
+			out = append(out, sum)
+		}
+		r.outputs++
+		r.phase += r.down
+		r.index += r.phase / r.up
+		r.phase %= r.up
+	}
+
+	// Drop the input frames no longer needed
+	if drop := r.index - half + 1; drop > 0 {
+		drop = min(drop, frames)
+		r.buf = r.buf[:copy(r.buf, r.buf[drop*r.channels:])]
+		r.index -= drop
+	}
+	return out
+}
+
+// ProcessInt16 resamples interleaved 16-bit samples like Process, rounding
+// and clipping the output
+func (r *Resampler) ProcessInt16(out, in []int16) []int16 {
+	return appendInt16(out, r.Process(nil, toFloat32(in)))
+}
+
+// FlushInt16 resamples the end of the input like Flush
+func (r *Resampler) FlushInt16(out []int16) []int16 {
+	return appendInt16(out, r.Flush(nil))
+}
+
+// toFloat32 converts 16-bit samples to floats with full scale at ±1
+func toFloat32(in []int16) []float32 {
+	out := make([]float32, len(in))
+	for i, v := range in {
+		out[i] = float32(v) / (1 << 15)
+	}
+	return out
+}
+
+// appendInt16 appends float samples to out as 16-bit samples, rounding and
+// clipping them
+func appendInt16(out []int16, in []float32) []int16 {
+	for _, v := range in {
+		out = append(out, int16(min(max(math.Round(float64(v)*(1<<15)), math.MinInt16), math.MaxInt16)))
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+package resample_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/opus"
+	"github.com/justa-cai/go-libopus/resample"
+)
+
+// tone generates frames of a sine wave of freq at rate with amplitude
+func tone(freq, rate float64, frames int, amplitude float64) []float32 {
+	samples := make([]float32, frames)
+	for i := range samples {
+		samples[i] = float32(amplitude * math.Sin(2*math.Pi*freq*float64(i)/rate))
+	}
+	return samples
+}
+
+// level returns the amplitude of the component of samples at freq, skipping
+// the edges where the filter sees the zeros around the signal
+func level(samples []float32, freq, rate float64) float64 {
+	var re, im float64
+	skip := len(samples) / 10
+	window := samples[skip : len(samples)-skip]
+	for i, v := range window {
+		angle := 2 * math.Pi * freq * float64(i+skip) / rate
+		re += float64(v) * math.Cos(angle)
+		im += float64(v) * math.Sin(angle)
+	}
+	return 2 * math.Hypot(re, im) / float64(len(window))
+}
+
+// resampleAll resamples samples in one call followed by Flush
+func resampleAll(t *testing.T, inRate, outRate, channels int, quality resample.Quality, samples []float32) []float32 {
+	r, err := resample.New(inRate, outRate, channels, quality)
+	if err != nil {
+		t.Fatalf("Failed to create resampler: %v", err)
+	}
+	return r.Flush(r.Process(nil, samples))
+}
+
+func TestPassband(t *testing.T) {
+	// Tones well inside the passband keep their level and phase
+	for _, freq := range []float64{100, 1000, 5000, 10000, 15000} {
+		out := resampleAll(t, 44100, 48000, 1, resample.QualityHigh, tone(freq, 44100, 44100, 0.5))
+		gain := 20 * math.Log10(level(out, freq, 48000)/0.5)
+		if math.Abs(gain) > 0.1 {
+			t.Errorf("%v Hz: passband gain %.3f dB", freq, gain)
+		}
+		// The output is aligned with the input
+		expected := tone(freq, 48000, len(out), 0.5)
+		for i := len(out) / 10; i < len(out)*9/10; i++ {
+			if math.Abs(float64(out[i]-expected[i])) > 0.01 {
+				t.Fatalf("%v Hz: sample %d is %v, expected %v", freq, i, out[i], expected[i])
+			}
+		}
+	}
+}
+
+func TestAliasing(t *testing.T) {
+	// Tones above the output Nyquist frequency are filtered out rather than
+	// folded back into the band
+	for _, test := range []struct {
+		quality   resample.Quality
+		minReject float64
+	}{
+		{resample.QualityLow, 40},
+		{resample.QualityMedium, 60},
+		{resample.QualityHigh, 80},
+	} {
+		for _, freq := range []float64{10000, 12000, 20000} {
+			out := resampleAll(t, 48000, 16000, 1, test.quality, tone(freq, 48000, 48000, 0.5))
+			// The alias of freq folded around 8 kHz
+			alias := math.Abs(16000*math.Round(freq/16000) - freq)
+			reject := -20 * math.Log10(level(out, alias, 16000)/0.5)
+			if reject < test.minReject {
+				t.Errorf("Quality %d, %v Hz: alias at %v Hz only %.1f dB down", test.quality, freq, alias, reject)
+			}
+		}
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	// Stereo input split into uneven chunks resamples exactly as in one call
+	for _, rates := range [][2]int{{44100, 48000}, {48000, 44100}, {48000, 8000}, {16000, 48000}} {
+		in := make([]float32, 2*rates[0]/10)
+		for i := range in {
+			in[i] = float32(math.Sin(float64(i) * 0.01))
+		}
+		whole := resampleAll(t, rates[0], rates[1], 2, resample.QualityMedium, in)
+
+		r, err := resample.New(rates[0], rates[1], 2, resample.QualityMedium)
+		if err != nil {
+			t.Fatalf("Failed to create resampler: %v", err)
+		}
+		// Chunks split frames too
+		var chunked []float32
+		for i, size := 0, 1; i < len(in); i, size = i+size, size*3%997+1 {
+			chunked = r.Process(chunked, in[i:min(i+size, len(in))])
+		}
+		chunked = r.Flush(chunked)
+
+		// The output covers the input
+		frames := (len(in)/2*rates[1] + rates[0] - 1) / rates[0]
+		if len(whole) != frames*2 || len(chunked) != len(whole) {
+			t.Fatalf("%v: expected %d frames, got %d and %d", rates, frames, len(whole)/2, len(chunked)/2)
+		}
+		for i := range whole {
+			if whole[i] != chunked[i] {
+				t.Fatalf("%v: sample %d is %v streamed, %v in one call", rates, i, chunked[i], whole[i])
+			}
+		}
+	}
+
+	r, err := resample.New(48000, 48000, 1, resample.QualityLow)
+	if err != nil {
+		t.Fatalf("Failed to create resampler: %v", err)
+	}
+	in := []int16{0, 1000, -1000, 32767, -32768}
+	out := r.FlushInt16(r.ProcessInt16(nil, in))
+	for i := range in {
+		if out[i] != in[i] {
+			t.Errorf("Expected %v unchanged at the same rate, got %v", in, out)
+			break
+		}
+	}
+
+	if _, err := resample.New(0, 48000, 1, resample.QualityHigh); err == nil {
+		t.Error("Expected error for a zero sample rate")
+	}
+	if _, err := resample.New(48000, 44100, 1, resample.Quality(7)); err == nil {
+		t.Error("Expected error for an invalid quality")
+	}
+}
+
+func TestOpus(t *testing.T) {
+	const inputRate, codecRate, frameSize = 44100, 48000, 960
+
+	for _, channels := range []int{1, 2} {
+		encoder, err := opus.NewEncoder(codecRate, channels, opus.OpusApplicationAudio)
+		if err != nil {
+			t.Fatalf("Failed to create encoder: %v", err)
+		}
+		defer encoder.Close()
+		if err := encoder.SetBitrate(64000 * channels); err != nil {
+			t.Fatalf("Failed to set bitrate: %v", err)
+		}
+		decoder, err := opus.NewDecoder(codecRate, channels)
+		if err != nil {
+			t.Fatalf("Failed to create decoder: %v", err)
+		}
+		defer decoder.Close()
+
+		enc, err := resample.NewEncoder(encoder, codecRate, inputRate, channels, frameSize, resample.QualityHigh)
+		if err != nil {
+			t.Fatalf("Failed to create resampling encoder: %v", err)
+		}
+		dec, err := resample.NewDecoder(decoder, codecRate, inputRate, channels, resample.QualityHigh)
+		if err != nil {
+			t.Fatalf("Failed to create resampling decoder: %v", err)
+		}
+
+		// A second of 1 kHz at 44.1 kHz, 2 kHz on the second channel, fed
+		// in 10 ms chunks
+		tones := [][]float32{tone(1000, inputRate, inputRate, 0.5), tone(2000, inputRate, inputRate, 0.5)}
+		pcm := make([]byte, inputRate*channels*2)
+		for i := 0; i < inputRate; i++ {
+			for c := 0; c < channels; c++ {
+				binary.LittleEndian.PutUint16(pcm[(i*channels+c)*2:], uint16(int16(tones[c][i]*(1<<15))))
+			}
+		}
+		chunk := 882 * channels
+		var packets [][]byte
+		for i := 0; i < len(pcm); i += chunk {
+			p, err := enc.Encode(pcm[i:min(i+chunk, len(pcm))])
+			if err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+			packets = append(packets, p...)
+		}
+		p, err := enc.Flush()
+		if err != nil {
+			t.Fatalf("Failed to flush encoder: %v", err)
+		}
+		packets = append(packets, p...)
+		if len(packets) != 50 {
+			t.Errorf("%d channels: expected 50 packets of 20 ms, got %d", channels, len(packets))
+		}
+
+		decoded := make([][]float32, channels)
+		appendDecoded := func(out []byte) {
+			for i := 0; i+1 < len(out); i += 2 {
+				c := i / 2 % channels
+				decoded[c] = append(decoded[c], float32(int16(binary.LittleEndian.Uint16(out[i:])))/(1<<15))
+			}
+		}
+		for _, packet := range packets {
+			out, err := dec.Decode(packet)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			appendDecoded(out)
+		}
+		appendDecoded(dec.Flush())
+		// 50 packets of 960 samples at 48 kHz
+		if expected := 50 * frameSize * inputRate / codecRate; len(decoded[0]) != expected {
+			t.Errorf("%d channels: expected %d samples at 44.1 kHz, got %d", channels, expected, len(decoded[0]))
+		}
+		for c, freq := range []float64{1000, 2000}[:channels] {
+			if gain := 20 * math.Log10(level(decoded[c], freq, inputRate)/0.5); math.Abs(gain) > 1 {
+				t.Errorf("%d channels: %v Hz tone on channel %d decoded at %.2f dB", channels, freq, c, gain)
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package rtpopus
+
+import (
+	"errors"
+
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// Frame is an Opus packet received over RTP, in sequence order, with the
+// gap before it
+type Frame struct {
+	Payload        []byte // Opus packet
+	SequenceNumber uint16
+	Timestamp      uint32
+	Marker         bool
+	Samples        int  // duration of Payload in samples at 48 kHz
+	Lost           int  // packets missing right before this one
+	Gap            int  // samples at 48 kHz missing before this one, lost or not sent during DTX
+	NewSource      bool // first frame of the stream or of a new SSRC
+}
+
+// Stats counts the packets seen by a Depacketizer
+type Stats struct {
+	Received   int // packets accepted
+	Lost       int // packets never received, counted when skipped
+	Late       int // packets received after their place was skipped
+	Duplicates int // packets received twice
+}
+
+// Depacketizer puts received RTP packets back in order and reports the
+// packets and time missing between them. Up to Window later packets are
+// held back waiting for a missing one before it is declared lost, so
+// reordering within the window costs no audio but adds its delay.
+type Depacketizer struct {
+	PayloadType uint8  // payload type accepted, 0 for any
+	SSRC        uint32 // source accepted, 0 for the source of the latest packet
+	Window      int    // packets held back for reordering
+
+	source    uint32
+	queue     []queuedFrame // sorted by sequence
+	highest   int64         // highest extended sequence number received
+	next      int64         // extended sequence number expected next
+	end       uint32        // timestamp at the end of the last frame returned
+	received  bool          // a packet of source was received
+	started   bool          // a frame of source was returned
+	newSource bool          // the next frame is the first of source
+	stats     Stats
+}
+
+// queuedFrame is a frame waiting to be returned, with its extended
+// sequence number
+type queuedFrame struct {
+	Frame
+	sequence int64
+}
+
+// NewDepacketizer creates a depacketizer holding back up to window packets
+// for reordering
+func NewDepacketizer(window int) *Depacketizer {
+	return &Depacketizer{Window: window}
+}
+
+// Push adds a received RTP packet. The payload is copied, so data can be
+// reused. Late and duplicate packets are counted and dropped without error.
+func (d *Depacketizer) Push(data []byte) error {
+	var packet Packet
+	if err := packet.Unmarshal(data); err != nil {
+		return err
+	}
+	if d.PayloadType != 0 && packet.PayloadType != d.PayloadType {
+		return errors.New("unexpected payload type")
+	}
+	if d.SSRC != 0 && packet.SSRC != d.SSRC {
+		return errors.New("unexpected SSRC")
+	}
+	samples, err := opus.PacketSamples(packet.Payload, ClockRate)
+	if err != nil {
+		return err
+	}
+
+	if !d.received || packet.SSRC != d.source {
+		// A new source starts over, dropping what is left of the old one
+		d.source, d.queue = packet.SSRC, d.queue[:0]
+		d.highest = int64(packet.SequenceNumber)
+		d.received, d.started, d.newSource = true, false, true
+	}
+	// Extend the sequence number by the wraparounds since the highest one
+	sequence := d.highest + int64(int16(packet.SequenceNumber-uint16(d.highest)))
+	if d.started && sequence < d.next {
+		d.stats.Late++
+		return nil
+	}
+
+	i := len(d.queue)
+	for i > 0 && d.queue[i-1].sequence >= sequence {
+		if d.queue[i-1].sequence == sequence {
+			d.stats.Duplicates++
+			return nil
+		}
+		i--
+	}
+	frame := queuedFrame{
+		Frame: Frame{
+			Payload:        append([]byte(nil), packet.Payload...),
+			SequenceNumber: packet.SequenceNumber,
+			Timestamp:      packet.Timestamp,
+			Marker:         packet.Marker,
+			Samples:        samples,
+		},
+		sequence: sequence,
+	}
+	d.queue = append(d.queue, queuedFrame{})
+	copy(d.queue[i+1:], d.queue[i:])
+	d.queue[i] = frame
+	d.highest = max(d.highest, sequence)
+	d.stats.Received++
+	return nil
+}
+
+// Pop returns the next frame in sequence order, false if it is missing and
+// fewer than Window later packets are waiting. At the start of a stream the
+// first packet is returned at once if the packets waiting follow it without
+// a gap, so a stream received in order starts without delay.
+func (d *Depacketizer) Pop() (Frame, bool) {
+	if len(d.queue) == 0 {
+		return Frame{}, false
+	}
+	if !d.inOrder() && len(d.queue) <= d.Window {
+		return Frame{}, false
+	}
+	return d.pop(), true
+}
+
+// inOrder reports whether the first queued frame is the one expected next,
+// or at the start of the stream whether the queue has no gap
+func (d *Depacketizer) inOrder() bool {
+	if d.started {
+		return d.queue[0].sequence == d.next
+	}
+	last := d.queue[len(d.queue)-1]
+	return last.sequence-d.queue[0].sequence == int64(len(d.queue)-1)
+}
+
+// Flush returns all frames held back, as at the end of the stream
+func (d *Depacketizer) Flush() []Frame {
+	var frames []Frame
+	for len(d.queue) > 0 {
+		frames = append(frames, d.pop())
+	}
+	return frames
+}
+
+// pop removes the first queued frame, filling in the gap before it
+func (d *Depacketizer) pop() Frame {
+	queued := d.queue[0]
+	d.queue = d.queue[:copy(d.queue, d.queue[1:])]
+
+	frame := queued.Frame
+	if d.started {
+		frame.Lost = int(queued.sequence - d.next)
+		// Frames overlapping the previous one have no gap
+		frame.Gap = max(int(int32(frame.Timestamp-d.end)), 0)
+	}
+	frame.NewSource = d.newSource
+	d.stats.Lost += frame.Lost
+	d.next = queued.sequence + 1
+	d.end = frame.Timestamp + uint32(frame.Samples)
+	d.started, d.newSource = true, false
+	return frame
+}
+
+// Stats returns the packet counters
+func (d *Depacketizer) Stats() Stats {
+	return d.stats
+}
+
+// Decoder decodes the frames of a Depacketizer with an OpusDecoder,
+// concealing the gaps between them with packet loss concealment
+type Decoder struct {
+	// MaxConceal is the longest gap concealed, in samples at 48 kHz. Only its
+	// end is concealed for longer gaps. It defaults to one second.
+	MaxConceal int
+
+	decoder    *opus.OpusDecoder
+	sampleRate int
+	channels   int
+	buf        []byte
+}
+
+// NewDecoder creates a decoder for frames using decoder, which was created
+// with sampleRate and channels
+func NewDecoder(decoder *opus.OpusDecoder, sampleRate int, channels int) *Decoder {
+	return &Decoder{MaxConceal: ClockRate, decoder: decoder, sampleRate: sampleRate, channels: channels}
+}
+
+// Decode conceals the gap before frame and decodes its payload, returning
+// interleaved 16-bit PCM valid until the next call. The decoder is reset at
+// the start of a new source, whose gap is not concealed.
+func (d *Decoder) Decode(frame Frame) ([]byte, error) {
+	unit := d.sampleRate / 400 // 2.5 ms, the granularity of concealment
+	gap := min(frame.Gap, d.MaxConceal) * d.sampleRate / ClockRate
+	gap = (gap + unit/2) / unit * unit
+	if frame.NewSource {
+		if err := d.decoder.CtlInt(opus.OPUS_RESET_STATE, 0); err != nil {
+			return nil, err
+		}
+		gap = 0
+	}
+
+	samples := frame.Samples * d.sampleRate / ClockRate
+	frameBytes := d.channels * 2
+	if size := (gap + samples) * frameBytes; cap(d.buf) < size {
+		d.buf = make([]byte, size)
+	}
+	d.buf = d.buf[:(gap+samples)*frameBytes]
+
+	n := 0
+	for n < gap {
+		// At most 120 ms per call
+		chunk := min(gap-n, 48*unit)
+		if _, err := d.decoder.DecodePLC(d.buf[n*frameBytes:(n+chunk)*frameBytes], chunk); err != nil {
+			return nil, err
+		}
+		n += chunk
+	}
+	ret, err := d.decoder.Decode(frame.Payload, d.buf[n*frameBytes:])
+	if err != nil {
+		return nil, err
+	}
+	return d.buf[:(n+ret)*frameBytes], nil
+}
@@ -0,0 +1,283 @@
+package rtpopus
+
+import (
+	"math"
+	"time"
+
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// JitterStats counts what a JitterBuffer did with the packets it received
+type JitterStats struct {
+	Received     int           // packets accepted
+	Late         int           // packets arriving after their playout time, dropped
+	Duplicates   int           // packets received twice
+	Lost         int           // packets never received, counted when skipped
+	ConcealedFEC int           // frames recovered from the in-band FEC of the next packet
+	ConcealedPLC int           // frames of lost packets concealed by PLC
+	Underruns    int           // frames concealed because the buffer ran empty, adding delay
+	Dropped      int           // frames decoded and discarded to reduce the delay
+	Jitter       time.Duration // interarrival jitter (RFC 3550 section 6.4.1)
+	TargetDelay  time.Duration // buffering delay aimed for
+}
+
+// JitterBuffer holds received RTP packets for a delay adapted to the
+// observed jitter and decodes them at the pace of Read. A missing frame is
+// recovered from the in-band FEC of the packet after it if possible, or else
+// concealed by PLC.
+//
+// The target delay is one frame plus four times the interarrival jitter,
+// within MinDelay and MaxDelay. When the buffer runs empty the frame is
+// concealed without advancing playout, which grows the delay; when more than
+// a frame above the target is buffered a frame is dropped.
+type JitterBuffer struct {
+	MinDelay time.Duration // lowest target delay, default 20 ms
+	MaxDelay time.Duration // highest target delay, default 500 ms
+
+	decoder    *opus.OpusDecoder
+	sampleRate int
+	channels   int
+
+	source       uint32
+	packets      []jitterPacket // sorted by sequence
+	received     bool           // a packet of source was received
+	reset        bool           // the decoder is reset before the next decode
+	highest      int64          // highest extended sequence number received
+	highestTS    int64          // extended timestamp of the packet with highest
+	started      bool           // playout started
+	playout      int64          // extended timestamp of the next sample to play
+	lastSeq      int64          // extended sequence number of the last packet decoded
+	frameSamples int            // duration of the last packet at 48 kHz
+
+	epoch      time.Time // arrival time of the first packet
+	transit    float64   // relative transit time of the last packet in seconds
+	hasTransit bool
+	jitter     float64 // interarrival jitter in seconds
+
+	buf   []byte
+	stats JitterStats
+}
+
+// jitterPacket is a buffered packet with its extended sequence number and
+// timestamp
+type jitterPacket struct {
+	payload   []byte
+	sequence  int64
+	timestamp int64
+	samples   int // duration at 48 kHz
+}
+
+// NewJitterBuffer creates a jitter buffer decoding with decoder, which was
+// created with sampleRate and channels
+func NewJitterBuffer(decoder *opus.OpusDecoder, sampleRate int, channels int) *JitterBuffer {
+	return &JitterBuffer{
+		MinDelay:     20 * time.Millisecond,
+		MaxDelay:     500 * time.Millisecond,
+		decoder:      decoder,
+		sampleRate:   sampleRate,
+		channels:     channels,
+		frameSamples: ClockRate / 50,
+	}
+}
+
+// Push adds an RTP packet received at arrival. The payload is copied, so
+// data can be reused. Late and duplicate packets are counted and dropped
+// without error. A packet of a new SSRC starts over.
+func (j *JitterBuffer) Push(data []byte, arrival time.Time) error {
+	var packet Packet
+	if err := packet.Unmarshal(data); err != nil {
+		return err
+	}
+	samples, err := opus.PacketSamples(packet.Payload, ClockRate)
+	if err != nil {
+		return err
+	}
+
+	if !j.received || packet.SSRC != j.source {
+		j.source, j.packets = packet.SSRC, j.packets[:0]
+		j.highest, j.highestTS = int64(packet.SequenceNumber), int64(packet.Timestamp)
+		j.received, j.started, j.reset, j.hasTransit = true, false, true, false
+		j.epoch = arrival
+	}
+	// Extend sequence number and timestamp by their wraparounds
+	sequence := j.highest + int64(int16(packet.SequenceNumber-uint16(j.highest)))
+	timestamp := j.highestTS + int64(int32(packet.Timestamp-uint32(j.highestTS)))
+
+	// Relative transit time in seconds, whose variation is the jitter
+	transit := arrival.Sub(j.epoch).Seconds() - float64(timestamp)/ClockRate
+	if j.hasTransit {
+		j.jitter += (math.Abs(transit-j.transit) - j.jitter) / 16
+	}
+	j.transit, j.hasTransit = transit, true
+
+	if j.started && (sequence <= j.lastSeq || timestamp < j.playout) {
+		j.stats.Late++
+		return nil
+	}
+	i := len(j.packets)
+	for i > 0 && j.packets[i-1].sequence >= sequence {
+		if j.packets[i-1].sequence == sequence {
+			j.stats.Duplicates++
+			return nil
+		}
+		i--
+	}
+	j.packets = append(j.packets, jitterPacket{})
+	copy(j.packets[i+1:], j.packets[i:])
+	j.packets[i] = jitterPacket{
+		payload:   append([]byte(nil), packet.Payload...),
+		sequence:  sequence,
+		timestamp: timestamp,
+		samples:   samples,
+	}
+	if sequence > j.highest {
+		j.highest, j.highestTS = sequence, timestamp
+	}
+	j.stats.Received++
+	return nil
+}
+
+// targetDelay returns the delay to buffer in samples at 48 kHz
+func (j *JitterBuffer) targetDelay() int {
+	target := time.Duration(float64(j.frameSamples)/ClockRate*float64(time.Second) + 4*j.jitter*float64(time.Second))
+	target = min(max(target, j.MinDelay), j.MaxDelay)
+	return int(target * ClockRate / time.Second)
+}
+
+// buffered returns the audio buffered ahead of playout in samples at 48 kHz
+func (j *JitterBuffer) buffered() int {
+	if len(j.packets) == 0 {
+		return 0
+	}
+	start := j.playout
+	if !j.started {
+		start = j.packets[0].timestamp
+	}
+	last := j.packets[len(j.packets)-1]
+	return int(last.timestamp + int64(last.samples) - start)
+}
+
+// Read returns the next frame of interleaved 16-bit PCM, valid until the
+// next call, and should be called once per frame duration. It returns nil
+// until the target delay is buffered at the start of a stream.
+func (j *JitterBuffer) Read() ([]byte, error) {
+	if !j.started {
+		if len(j.packets) == 0 || j.buffered() < j.targetDelay() {
+			return nil, nil
+		}
+		j.started = true
+		j.playout = j.packets[0].timestamp
+		j.lastSeq = j.packets[0].sequence - 1
+	}
+
+	for {
+		if len(j.packets) == 0 {
+			j.stats.Underruns++
+			return j.conceal(j.frameSamples)
+		}
+		next := j.packets[0]
+		if next.timestamp > j.playout && next.sequence == j.lastSeq+1 {
+			// Nothing lost, the time was not sent during DTX or was
+			// concealed during an underrun
+			j.playout = next.timestamp
+		}
+		if next.timestamp > j.playout {
+			return j.recover(next)
+		}
+
+		j.packets = j.packets[1:]
+		pcm, err := j.decode(next)
+		if err != nil {
+			return nil, err
+		}
+		if len(j.packets) > 0 && j.buffered() > j.targetDelay()+j.frameSamples {
+			j.stats.Dropped++
+			continue
+		}
+		return pcm, nil
+	}
+}
+
+// decode decodes a buffered packet at playout
+func (j *JitterBuffer) decode(packet jitterPacket) ([]byte, error) {
+	if j.reset {
+		if err := j.decoder.CtlInt(opus.OPUS_RESET_STATE, 0); err != nil {
+			return nil, err
+		}
+		j.reset = false
+	}
+	j.stats.Lost += int(packet.sequence - j.lastSeq - 1)
+	j.lastSeq = packet.sequence
+	j.playout = packet.timestamp + int64(packet.samples)
+	j.frameSamples = packet.samples
+
+	buf := j.output(packet.samples)
+	n, err := j.decoder.Decode(packet.payload, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n*j.channels*2], nil
+}
+
+// recover replaces the frame at playout, missing before next, from the
+// in-band FEC of next if it directly follows, or else by PLC. The FEC of next
+// covers a frame of its own duration: libopus conceals a shorter gap by PLC.
+func (j *JitterBuffer) recover(next jitterPacket) ([]byte, error) {
+	gap := int(next.timestamp - j.playout)
+	if gap == next.samples {
+		if fec, err := opus.PacketHasLBRR(next.payload); err == nil && fec {
+			frameSize := j.frameSize(gap)
+			buf := j.output(gap)
+			if _, err := j.decoder.DecodeFEC(next.payload, buf, frameSize); err != nil {
+				return nil, err
+			}
+			j.stats.ConcealedFEC++
+			j.playout = next.timestamp
+			return buf[:frameSize*j.channels*2], nil
+		}
+	}
+	j.stats.ConcealedPLC++
+	samples := min(gap, j.frameSamples)
+	pcm, err := j.conceal(samples)
+	j.playout += int64(samples)
+	return pcm, err
+}
+
+// conceal conceals samples at 48 kHz by PLC, in calls of at most 120 ms
+func (j *JitterBuffer) conceal(samples int) ([]byte, error) {
+	frameSize := j.frameSize(samples)
+	buf := j.output(samples)[:frameSize*j.channels*2]
+	unit := j.sampleRate / 400
+	for n := 0; n < frameSize; {
+		chunk := min(frameSize-n, 48*unit)
+		if _, err := j.decoder.DecodePLC(buf[n*j.channels*2:], chunk); err != nil {
+			return nil, err
+		}
+		n += chunk
+	}
+	return buf, nil
+}
+
+// frameSize converts samples at 48 kHz to the decoder rate, rounded to a
+// multiple of 2.5 ms and at least 2.5 ms
+func (j *JitterBuffer) frameSize(samples int) int {
+	unit := j.sampleRate / 400
+	return max((samples*j.sampleRate/ClockRate+unit/2)/unit, 1) * unit
+}
+
+// output returns the buffer for samples at 48 kHz of decoded audio
+func (j *JitterBuffer) output(samples int) []byte {
+	size := j.frameSize(samples) * j.channels * 2
+	if cap(j.buf) < size {
+		j.buf = make([]byte, size)
+	}
+	return j.buf[:size]
+}
+
+// Stats returns the counters and the current jitter and target delay
+func (j *JitterBuffer) Stats() JitterStats {
+	stats := j.stats
+	stats.Jitter = time.Duration(j.jitter * float64(time.Second))
+	stats.TargetDelay = time.Duration(j.targetDelay()) * time.Second / ClockRate
+	return stats
+}
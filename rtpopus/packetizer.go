@@ -0,0 +1,89 @@
+package rtpopus
+
+import (
+	"math/rand/v2"
+
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// maxDTXSize is the largest encoder output that need not be sent: with DTX
+// enabled, libopus returns packets of at most 2 bytes during silence
+const maxDTXSize = 2
+
+// Packetizer wraps the output of an OpusEncoder in RTP packets. Timestamps
+// advance by the duration of each Opus packet at 48 kHz, so they are correct
+// whatever the sample rate of the encoder.
+type Packetizer struct {
+	PayloadType uint8
+	SSRC        uint32
+
+	sequence  uint16
+	timestamp uint32
+	talkspurt bool // a packet was sent since the last silence
+}
+
+// NewPacketizer creates a packetizer for the given payload type. A zero ssrc
+// is replaced by a random one. The sequence number and timestamp start at
+// random values as RFC 3550 requires.
+func NewPacketizer(payloadType uint8, ssrc uint32) *Packetizer {
+	for ssrc == 0 {
+		ssrc = rand.Uint32()
+	}
+	return &Packetizer{
+		PayloadType: payloadType,
+		SSRC:        ssrc,
+		sequence:    uint16(rand.Uint32()),
+		timestamp:   rand.Uint32(),
+	}
+}
+
+// Packetize returns the RTP packet carrying one Opus packet as returned by
+// OpusEncoder.Encode; the payload shares frame. DTX frames of at most 2
+// bytes only advance the timestamp and return nil, and the next packet sent
+// has the marker bit set as the start of a talkspurt.
+func (p *Packetizer) Packetize(frame []byte) (*Packet, error) {
+	samples, err := opus.PacketSamples(frame, ClockRate)
+	if err != nil {
+		return nil, err
+	}
+	if len(frame) <= maxDTXSize {
+		p.Skip(samples)
+		return nil, nil
+	}
+	return p.packetize(frame, samples), nil
+}
+
+// packetize returns the RTP packet carrying payload, samples at 48 kHz long
+func (p *Packetizer) packetize(payload []byte, samples int) *Packet {
+	packet := &Packet{
+		Header: Header{
+			Marker:         !p.talkspurt,
+			PayloadType:    p.PayloadType,
+			SequenceNumber: p.sequence,
+			Timestamp:      p.timestamp,
+			SSRC:           p.SSRC,
+		},
+		Payload: payload,
+	}
+	p.sequence++
+	p.timestamp += uint32(samples)
+	p.talkspurt = true
+	return packet
+}
+
+// Skip advances the timestamp by samples at 48 kHz of audio that is not
+// sent, such as while muted, ending the current talkspurt
+func (p *Packetizer) Skip(samples int) {
+	p.timestamp += uint32(samples)
+	p.talkspurt = false
+}
+
+// SequenceNumber returns the sequence number of the next packet
+func (p *Packetizer) SequenceNumber() uint16 {
+	return p.sequence
+}
+
+// Timestamp returns the timestamp of the next packet
+func (p *Packetizer) Timestamp() uint32 {
+	return p.timestamp
+}
@@ -0,0 +1,276 @@
+package rtpopus
+
+import (
+	"errors"
+
+	"github.com/justa-cai/go-libopus/opus"
+)
+
+// RED block limits of the 14-bit timestamp offset and 10-bit block length
+// in the block headers (RFC 2198 section 3)
+const (
+	maxREDOffset = 1<<14 - 1
+	maxREDLength = 1<<10 - 1
+)
+
+// REDBlock is a block of a RED payload (RFC 2198): an earlier redundant
+// payload, or the primary payload of the packet
+type REDBlock struct {
+	PayloadType     uint8
+	TimestampOffset uint16 // how much earlier than the packet timestamp, 0 for the primary
+	Payload         []byte
+}
+
+// MarshalRED returns the RED payload carrying blocks, the primary one last.
+// The timestamp offset of the primary block is ignored.
+func MarshalRED(blocks []REDBlock) ([]byte, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("no RED blocks")
+	}
+	size := 1 + len(blocks[len(blocks)-1].Payload)
+	for _, block := range blocks[:len(blocks)-1] {
+		if block.TimestampOffset > maxREDOffset || len(block.Payload) > maxREDLength {
+			return nil, errors.New("redundant block too long or too old")
+		}
+		size += 4 + len(block.Payload)
+	}
+	for _, block := range blocks {
+		if block.PayloadType > 127 {
+			return nil, errors.New("invalid payload type")
+		}
+	}
+
+	data := make([]byte, 0, size)
+	for _, block := range blocks[:len(blocks)-1] {
+		offset, length := block.TimestampOffset, len(block.Payload)
+		data = append(data, 1<<7|block.PayloadType, byte(offset>>6), byte(offset<<2)|byte(length>>8), byte(length))
+	}
+	data = append(data, blocks[len(blocks)-1].PayloadType)
+	for _, block := range blocks {
+		data = append(data, block.Payload...)
+	}
+	return data, nil
+}
+
+// UnmarshalRED parses a RED payload into its blocks, the primary one last.
+// The block payloads reference payload.
+func UnmarshalRED(payload []byte) ([]REDBlock, error) {
+	var blocks []REDBlock
+	n := 0
+	for {
+		if n >= len(payload) {
+			return nil, errors.New("RED payload too short")
+		}
+		if payload[n]&(1<<7) == 0 {
+			blocks = append(blocks, REDBlock{PayloadType: payload[n]})
+			n++
+			break
+		}
+		if n+4 > len(payload) {
+			return nil, errors.New("RED payload too short")
+		}
+		blocks = append(blocks, REDBlock{
+			PayloadType:     payload[n] & 0x7f,
+			TimestampOffset: uint16(payload[n+1])<<6 | uint16(payload[n+2]>>2),
+			Payload:         make([]byte, int(payload[n+2]&3)<<8|int(payload[n+3])),
+		})
+		n += 4
+	}
+	for i := range blocks[:len(blocks)-1] {
+		length := len(blocks[i].Payload)
+		if n+length > len(payload) {
+			return nil, errors.New("RED block exceeds payload")
+		}
+		blocks[i].Payload = payload[n : n+length]
+		n += length
+	}
+	blocks[len(blocks)-1].Payload = payload[n:]
+	return blocks, nil
+}
+
+// REDPacketizer wraps the output of an OpusEncoder in RTP packets with a RED
+// payload, each carrying up to Distance previous Opus packets along with
+// the current one. A previous packet is left out with all older ones when it
+// does not fit in a RED block, so the redundant blocks are always those of
+// the packets sent directly before, which REDUnpacker relies on.
+type REDPacketizer struct {
+	Distance int // previous packets carried in each packet
+
+	packetizer  *Packetizer
+	opusPayload uint8
+	history     []redundantFrame // packets sent, oldest first
+}
+
+// redundantFrame is a copy of a sent Opus packet with its timestamp
+type redundantFrame struct {
+	payload   []byte
+	timestamp uint32
+}
+
+// NewREDPacketizer creates a RED packetizer sending packets with
+// payloadType, carrying Opus packets of opusPayloadType and distance
+// previous ones. A zero ssrc is replaced by a random one.
+func NewREDPacketizer(payloadType, opusPayloadType uint8, ssrc uint32, distance int) *REDPacketizer {
+	return &REDPacketizer{
+		Distance:    distance,
+		packetizer:  NewPacketizer(payloadType, ssrc),
+		opusPayload: opusPayloadType,
+	}
+}
+
+// Packetize returns the RTP packet carrying one Opus packet as returned by
+// OpusEncoder.Encode and the previous ones. The packet is copied for later
+// redundancy, so frame can be reused. DTX frames return nil as with
+// Packetizer.
+func (r *REDPacketizer) Packetize(frame []byte) (*Packet, error) {
+	samples, err := opus.PacketSamples(frame, ClockRate)
+	if err != nil {
+		return nil, err
+	}
+	if len(frame) <= maxDTXSize {
+		r.packetizer.Skip(samples)
+		return nil, nil
+	}
+
+	timestamp := r.packetizer.Timestamp()
+	first := len(r.history)
+	for first > 0 && len(r.history)-first < r.Distance {
+		previous := r.history[first-1]
+		if timestamp-previous.timestamp > maxREDOffset || len(previous.payload) > maxREDLength {
+			break
+		}
+		first--
+	}
+	blocks := make([]REDBlock, 0, len(r.history)-first+1)
+	for _, previous := range r.history[first:] {
+		blocks = append(blocks, REDBlock{
+			PayloadType:     r.opusPayload,
+			TimestampOffset: uint16(timestamp - previous.timestamp),
+			Payload:         previous.payload,
+		})
+	}
+	blocks = append(blocks, REDBlock{PayloadType: r.opusPayload, Payload: frame})
+	payload, err := MarshalRED(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	r.history = append(r.history[first:], redundantFrame{append([]byte(nil), frame...), timestamp})
+	if len(r.history) > r.Distance {
+		r.history = r.history[len(r.history)-r.Distance:]
+	}
+	return r.packetizer.packetize(payload, samples), nil
+}
+
+// Packetizer returns the packetizer numbering the RED packets
+func (r *REDPacketizer) Packetizer() *Packetizer {
+	return r.packetizer
+}
+
+// REDStats counts the packets seen by a REDUnpacker
+type REDStats struct {
+	Received   int // packets received
+	Recovered  int // packets never received, recovered from redundant blocks
+	Duplicates int // packets received twice or after their recovery, dropped
+}
+
+// REDUnpacker turns received RED packets back into RTP packets carrying
+// plain Opus, for a Depacketizer or JitterBuffer. The redundant blocks of
+// a packet are returned only for packets not received or recovered before,
+// so a lost packet is recovered as soon as a later one carrying it arrives,
+// leaving PLC to the losses longer than the redundancy distance.
+type REDUnpacker struct {
+	PayloadType uint8 // RED payload type, packets of other types pass through
+
+	source   uint32
+	received bool   // a packet of source was received
+	highest  int64  // highest extended sequence number returned
+	seen     uint64 // bit i set if highest-i was returned
+	stats    REDStats
+}
+
+// NewREDUnpacker creates an unpacker for RED packets of payloadType
+func NewREDUnpacker(payloadType uint8) *REDUnpacker {
+	return &REDUnpacker{PayloadType: payloadType}
+}
+
+// Unpack returns the RTP packets in data, oldest first: those recovered
+// from its redundant blocks and its primary packet. The recovered packets
+// take the header of data without the marker bit and extension, and the
+// sequence numbers of the packets sent directly before it.
+func (u *REDUnpacker) Unpack(data []byte) ([][]byte, error) {
+	var packet Packet
+	if err := packet.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	blocks := []REDBlock{{PayloadType: packet.PayloadType, Payload: packet.Payload}}
+	if packet.PayloadType == u.PayloadType {
+		var err error
+		if blocks, err = UnmarshalRED(packet.Payload); err != nil {
+			return nil, err
+		}
+	}
+
+	if !u.received || packet.SSRC != u.source {
+		u.source, u.received = packet.SSRC, true
+		u.highest, u.seen = int64(packet.SequenceNumber), 0
+	}
+	// Extend the sequence number by the wraparounds since the highest one
+	sequence := u.highest + int64(int16(packet.SequenceNumber-uint16(u.highest)))
+
+	var packets [][]byte
+	for i, block := range blocks {
+		redundant := i < len(blocks)-1
+		blockSequence := sequence - int64(len(blocks)-1-i)
+		if !u.mark(blockSequence, redundant) {
+			if !redundant {
+				u.stats.Duplicates++
+			}
+			continue
+		}
+
+		unpacked := Packet{Header: packet.Header, Payload: block.Payload}
+		unpacked.PayloadType = block.PayloadType
+		if redundant {
+			unpacked.SequenceNumber = uint16(blockSequence)
+			unpacked.Timestamp -= uint32(block.TimestampOffset)
+			unpacked.Marker, unpacked.Extension, unpacked.ExtensionData = false, false, nil
+			u.stats.Recovered++
+		} else {
+			u.stats.Received++
+		}
+		data, err := unpacked.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, data)
+	}
+	return packets, nil
+}
+
+// mark records sequence as returned, false if it already was. Redundant
+// packets too old to tell are not returned, primary ones are.
+func (u *REDUnpacker) mark(sequence int64, redundant bool) bool {
+	if sequence > u.highest {
+		if shift := sequence - u.highest; shift < 64 {
+			u.seen <<= shift
+		} else {
+			u.seen = 0
+		}
+		u.highest = sequence
+	}
+	age := u.highest - sequence
+	if age >= 64 {
+		return !redundant
+	}
+	if u.seen&(1<<age) != 0 {
+		return false
+	}
+	u.seen |= 1 << age
+	return true
+}
+
+// Stats returns the packet counters
+func (u *REDUnpacker) Stats() REDStats {
+	return u.stats
+}
@@ -0,0 +1,129 @@
+// Package rtpopus carries Opus packets over RTP (RFC 7587)
+package rtpopus
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ClockRate is the RTP clock rate of Opus, whatever the sample rate of the
+// encoder (RFC 7587 section 4.1)
+const ClockRate = 48000
+
+// headerSize is the size of the fixed RTP header without CSRCs
+const headerSize = 12
+
+// Header represents an RTP header (RFC 3550 section 5.1)
+type Header struct {
+	Marker         bool     // First packet of a talkspurt
+	PayloadType    uint8    // Dynamic payload type negotiated for Opus
+	SequenceNumber uint16   // Incremented by one for each packet sent
+	Timestamp      uint32   // Sampling instant of the first sample at ClockRate
+	SSRC           uint32   // Synchronization source
+	CSRC           []uint32 // Contributing sources, at most 15
+	Extension      bool     // Header extension present
+	ExtensionID    uint16   // Profile defined header extension identifier
+	ExtensionData  []byte   // Header extension data, a multiple of 4 bytes
+}
+
+// Packet represents an RTP packet carrying one Opus packet
+type Packet struct {
+	Header
+	Payload []byte
+}
+
+// Marshal returns the wire format of the packet, without padding
+func (p *Packet) Marshal() ([]byte, error) {
+	if len(p.CSRC) > 15 {
+		return nil, errors.New("too many CSRCs")
+	}
+	if p.PayloadType > 127 {
+		return nil, errors.New("invalid payload type")
+	}
+	if p.Extension && (len(p.ExtensionData)%4 != 0 || len(p.ExtensionData) > 0xffff*4) {
+		return nil, errors.New("invalid header extension length")
+	}
+
+	size := headerSize + 4*len(p.CSRC) + len(p.Payload)
+	if p.Extension {
+		size += 4 + len(p.ExtensionData)
+	}
+	data := make([]byte, size)
+	data[0] = 2<<6 | byte(len(p.CSRC))
+	if p.Extension {
+		data[0] |= 1 << 4
+	}
+	data[1] = p.PayloadType
+	if p.Marker {
+		data[1] |= 1 << 7
+	}
+	binary.BigEndian.PutUint16(data[2:], p.SequenceNumber)
+	binary.BigEndian.PutUint32(data[4:], p.Timestamp)
+	binary.BigEndian.PutUint32(data[8:], p.SSRC)
+	n := headerSize
+	for _, csrc := range p.CSRC {
+		binary.BigEndian.PutUint32(data[n:], csrc)
+		n += 4
+	}
+	if p.Extension {
+		binary.BigEndian.PutUint16(data[n:], p.ExtensionID)
+		binary.BigEndian.PutUint16(data[n+2:], uint16(len(p.ExtensionData)/4))
+		n += 4 + copy(data[n+4:], p.ExtensionData)
+	}
+	copy(data[n:], p.Payload)
+	return data, nil
+}
+
+// Unmarshal parses the wire format of an RTP packet, removing any padding.
+// The payload, CSRCs and extension data reference data.
+func (p *Packet) Unmarshal(data []byte) error {
+	if len(data) < headerSize {
+		return errors.New("RTP packet too short")
+	}
+	if data[0]>>6 != 2 {
+		return errors.New("unsupported RTP version")
+	}
+	padding := data[0]&(1<<5) != 0
+	p.Extension = data[0]&(1<<4) != 0
+	csrcs := int(data[0] & 0x0f)
+	p.Marker = data[1]&(1<<7) != 0
+	p.PayloadType = data[1] & 0x7f
+	p.SequenceNumber = binary.BigEndian.Uint16(data[2:])
+	p.Timestamp = binary.BigEndian.Uint32(data[4:])
+	p.SSRC = binary.BigEndian.Uint32(data[8:])
+
+	n := headerSize + 4*csrcs
+	if len(data) < n {
+		return errors.New("RTP packet too short for its CSRCs")
+	}
+	p.CSRC = p.CSRC[:0]
+	for i := headerSize; i < n; i += 4 {
+		p.CSRC = append(p.CSRC, binary.BigEndian.Uint32(data[i:]))
+	}
+	p.ExtensionID, p.ExtensionData = 0, nil
+	if p.Extension {
+		if len(data) < n+4 {
+			return errors.New("RTP packet too short for its header extension")
+		}
+		p.ExtensionID = binary.BigEndian.Uint16(data[n:])
+		length := 4 * int(binary.BigEndian.Uint16(data[n+2:]))
+		n += 4
+		if len(data) < n+length {
+			return errors.New("RTP packet too short for its header extension")
+		}
+		p.ExtensionData = data[n : n+length]
+		n += length
+	}
+
+	end := len(data)
+	if padding {
+		// The last byte counts the padding bytes, itself included
+		pad := int(data[end-1])
+		if pad == 0 || end-pad < n {
+			return errors.New("invalid RTP padding")
+		}
+		end -= pad
+	}
+	p.Payload = data[n:end]
+	return nil
+}
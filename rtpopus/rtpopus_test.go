@@ -0,0 +1,600 @@
+package rtpopus_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/justa-cai/go-libopus/opus"
+	"github.com/justa-cai/go-libopus/rtpopus"
+)
+
+const testFrameSize = 320 // 20ms at 16kHz
+
+// testFrame generates a tone for frame i, or silence
+func testFrame(i int, silent bool) []byte {
+	data := make([]byte, testFrameSize*2)
+	for j := 0; j < testFrameSize && !silent; j++ {
+		sample := 8000 * math.Sin(2*math.Pi*440*float64(i*testFrameSize+j)/16000)
+		binary.LittleEndian.PutUint16(data[j*2:], uint16(int16(sample)))
+	}
+	return data
+}
+
+// encodePackets encodes frames tone frames at 16 kHz into RTP packets
+func encodePackets(t *testing.T, packetizer *rtpopus.Packetizer, frames int) [][]byte {
+	encoder, err := opus.NewEncoder(16000, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+
+	var packets [][]byte
+	buf := make([]byte, 1500)
+	for i := 0; i < frames; i++ {
+		n, err := encoder.Encode(testFrame(i, false), buf)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		packet, err := packetizer.Packetize(buf[:n])
+		if err != nil {
+			t.Fatalf("Failed to packetize: %v", err)
+		}
+		data, err := packet.Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal packet: %v", err)
+		}
+		packets = append(packets, data)
+	}
+	return packets
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	packet := &rtpopus.Packet{
+		Header: rtpopus.Header{
+			Marker:         true,
+			PayloadType:    111,
+			SequenceNumber: 65535,
+			Timestamp:      0xdeadbeef,
+			SSRC:           0x12345678,
+			CSRC:           []uint32{1, 2},
+			Extension:      true,
+			ExtensionID:    0xbede,
+			ExtensionData:  []byte{0x10, 0xff, 0, 0},
+		},
+		Payload: []byte{0xf8, 0xff, 0xfe},
+	}
+	data, err := packet.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal packet: %v", err)
+	}
+	if len(data) != 12+8+8+3 || data[0] != 0x92 || data[1] != 0x80|111 {
+		t.Errorf("Unexpected wire format % x", data)
+	}
+
+	// Padding is removed on parsing
+	data[0] |= 1 << 5
+	data = append(data, 0, 0, 3)
+	var parsed rtpopus.Packet
+	if err := parsed.Unmarshal(data); err != nil {
+		t.Fatalf("Failed to unmarshal packet: %v", err)
+	}
+	if !parsed.Marker || parsed.PayloadType != 111 || parsed.SequenceNumber != 65535 ||
+		parsed.Timestamp != 0xdeadbeef || parsed.SSRC != 0x12345678 || len(parsed.CSRC) != 2 || parsed.CSRC[1] != 2 ||
+		!parsed.Extension || parsed.ExtensionID != 0xbede || !bytes.Equal(parsed.ExtensionData, packet.ExtensionData) {
+		t.Errorf("Header mismatch: %+v", parsed.Header)
+	}
+	if !bytes.Equal(parsed.Payload, packet.Payload) {
+		t.Errorf("Expected payload % x, got % x", packet.Payload, parsed.Payload)
+	}
+
+	for _, bad := range [][]byte{data[:11], {0x40, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, {0x81, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		if err := parsed.Unmarshal(bad); err == nil {
+			t.Errorf("Expected error for % x", bad)
+		}
+	}
+}
+
+func TestPacketizer(t *testing.T) {
+	encoder, err := opus.NewEncoder(16000, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	if err := encoder.CtlInt(opus.OPUS_SET_DTX_REQUEST, 1); err != nil {
+		t.Fatalf("Failed to enable DTX: %v", err)
+	}
+
+	packetizer := rtpopus.NewPacketizer(111, 0)
+	if packetizer.SSRC == 0 {
+		t.Error("Expected random SSRC")
+	}
+	timestamp, sequence := packetizer.Timestamp(), packetizer.SequenceNumber()
+
+	// Tone, silence long enough for DTX, tone again
+	buf := make([]byte, 1500)
+	var sent []*rtpopus.Packet
+	dtx := 0
+	afterDTX := true
+	for i := 0; i < 80; i++ {
+		n, err := encoder.Encode(testFrame(i, i >= 20 && i < 60), buf)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		packet, err := packetizer.Packetize(append([]byte(nil), buf[:n]...))
+		if err != nil {
+			t.Fatalf("Failed to packetize: %v", err)
+		}
+		if packet == nil {
+			dtx++
+			afterDTX = true
+			continue
+		}
+		sent = append(sent, packet)
+
+		// Markers start the stream and each talkspurt after DTX, including
+		// the comfort noise updates sent during silence
+		if packet.Marker != afterDTX {
+			t.Errorf("Frame %d: expected marker %v, got %v", i, afterDTX, packet.Marker)
+		}
+		afterDTX = false
+
+		// 20 ms at 16 kHz is 960 at the RTP clock rate
+		if packet.Timestamp != timestamp+uint32(i*960) {
+			t.Errorf("Frame %d: expected timestamp %d, got %d", i, timestamp+uint32(i*960), packet.Timestamp)
+		}
+		if packet.SequenceNumber != sequence+uint16(len(sent)-1) {
+			t.Errorf("Frame %d: expected sequence number %d, got %d", i, sequence+uint16(len(sent)-1), packet.SequenceNumber)
+		}
+		if packet.SSRC != packetizer.SSRC || packet.PayloadType != 111 {
+			t.Errorf("Frame %d: unexpected SSRC %#x or payload type %d", i, packet.SSRC, packet.PayloadType)
+		}
+	}
+	if dtx == 0 {
+		t.Error("Expected DTX frames during silence")
+	}
+}
+
+func TestDepacketizer(t *testing.T) {
+	packetizer := rtpopus.NewPacketizer(111, 0x1234)
+	packets := encodePackets(t, packetizer, 10)
+
+	// Reorder 2 and 3, lose 5, duplicate 6, deliver 7 late
+	depacketizer := rtpopus.NewDepacketizer(1)
+	order := []int{0, 1, 3, 2, 4, 6, 6, 8, 9, 7}
+	var frames []rtpopus.Frame
+	for _, i := range order {
+		if err := depacketizer.Push(packets[i]); err != nil {
+			t.Fatalf("Failed to push packet %d: %v", i, err)
+		}
+		for {
+			frame, ok := depacketizer.Pop()
+			if !ok {
+				break
+			}
+			frames = append(frames, frame)
+		}
+	}
+	frames = append(frames, depacketizer.Flush()...)
+
+	var first rtpopus.Packet
+	first.Unmarshal(packets[0])
+	expected := []int{0, 1, 2, 3, 4, 6, 8, 9}
+	if len(frames) != len(expected) {
+		t.Fatalf("Expected %d frames, got %d", len(expected), len(frames))
+	}
+	for i, frame := range frames {
+		if frame.SequenceNumber != first.SequenceNumber+uint16(expected[i]) {
+			t.Errorf("Frame %d: expected packet %d, got sequence number %d", i, expected[i], frame.SequenceNumber-first.SequenceNumber)
+		}
+		lost := 0
+		if i > 0 {
+			lost = expected[i] - expected[i-1] - 1
+		}
+		if frame.Lost != lost || frame.Gap != lost*960 || frame.Samples != 960 {
+			t.Errorf("Frame %d: expected %d lost, got %d lost, gap %d, %d samples", i, lost, frame.Lost, frame.Gap, frame.Samples)
+		}
+		if frame.NewSource != (i == 0) {
+			t.Errorf("Frame %d: unexpected NewSource %v", i, frame.NewSource)
+		}
+	}
+	stats := depacketizer.Stats()
+	if stats != (rtpopus.Stats{Received: 8, Lost: 2, Late: 1, Duplicates: 1}) {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// A stream received in order starts at once despite the window, and a
+	// gap holds the packets after it back
+	depacketizer = rtpopus.NewDepacketizer(3)
+	for _, i := range []int{0, 1, 3} {
+		depacketizer.Push(packets[i])
+		frame, ok := depacketizer.Pop()
+		if ok != (i < 3) || ok && frame.SequenceNumber != first.SequenceNumber+uint16(i) {
+			t.Errorf("Packet %d: unexpected frame %d, %v", i, frame.SequenceNumber-first.SequenceNumber, ok)
+		}
+	}
+	startup := rtpopus.NewDepacketizer(3)
+	startup.Push(packets[5])
+	startup.Push(packets[7])
+	if _, ok := startup.Pop(); ok {
+		t.Error("Expected the first packet held back before a gap")
+	}
+
+	// A new SSRC starts over
+	other := encodePackets(t, rtpopus.NewPacketizer(111, 0x5678), 1)
+	if err := depacketizer.Push(other[0]); err != nil {
+		t.Fatalf("Failed to push packet: %v", err)
+	}
+	if frames := depacketizer.Flush(); len(frames) != 1 || !frames[0].NewSource || frames[0].Lost != 0 {
+		t.Errorf("Expected one frame of a new source, got %+v", frames)
+	}
+	depacketizer.SSRC = 0x5678
+	if err := depacketizer.Push(packets[0]); err == nil {
+		t.Error("Expected error for unexpected SSRC")
+	}
+}
+
+func TestSequenceWraparound(t *testing.T) {
+	depacketizer := rtpopus.NewDepacketizer(0)
+	payload := []byte{0x08} // SILK NB 20 ms TOC, no frame data
+	for i := 0; i < 4; i++ {
+		packet := &rtpopus.Packet{
+			Header:  rtpopus.Header{SequenceNumber: 65534 + uint16(i), Timestamp: uint32(i * 960), SSRC: 1},
+			Payload: payload,
+		}
+		data, _ := packet.Marshal()
+		if err := depacketizer.Push(data); err != nil {
+			t.Fatalf("Failed to push packet: %v", err)
+		}
+		frame, ok := depacketizer.Pop()
+		if !ok || frame.Lost != 0 || frame.Gap != 0 {
+			t.Errorf("Packet %d: expected in-order frame, got %+v", i, frame)
+		}
+	}
+	if stats := depacketizer.Stats(); stats.Received != 4 || stats.Lost != 0 || stats.Late != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	packets := encodePackets(t, rtpopus.NewPacketizer(111, 0), 10)
+	decoder, err := opus.NewDecoder(16000, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+	rtpDecoder := rtpopus.NewDecoder(decoder, 16000, 1)
+
+	// Losing 3 and 4 is concealed, so no time is lost
+	depacketizer := rtpopus.NewDepacketizer(0)
+	total := 0
+	for i, packet := range packets {
+		if i == 3 || i == 4 {
+			continue
+		}
+		if err := depacketizer.Push(packet); err != nil {
+			t.Fatalf("Failed to push packet: %v", err)
+		}
+		frame, ok := depacketizer.Pop()
+		if !ok {
+			t.Fatalf("Expected frame for packet %d", i)
+		}
+		pcm, err := rtpDecoder.Decode(frame)
+		if err != nil {
+			t.Fatalf("Failed to decode frame %d: %v", i, err)
+		}
+		total += len(pcm) / 2
+	}
+	if total != 10*testFrameSize {
+		t.Errorf("Expected %d samples, got %d", 10*testFrameSize, total)
+	}
+}
+
+// arrival is a packet delivered by the simulated network
+type arrival struct {
+	packet []byte
+	at     time.Duration
+}
+
+// deliver sends packet i of packets at i*20ms, delayed by delay(i), and
+// returns them in arrival order
+func deliver(packets [][]byte, delay func(i int) time.Duration, lost map[int]bool) []arrival {
+	var arrivals []arrival
+	for i, packet := range packets {
+		if !lost[i] {
+			arrivals = append(arrivals, arrival{packet, time.Duration(i)*20*time.Millisecond + delay(i)})
+		}
+	}
+	sort.SliceStable(arrivals, func(a, b int) bool { return arrivals[a].at < arrivals[b].at })
+	return arrivals
+}
+
+// playOut pushes arrivals into jb and reads a frame every 20ms until end,
+// returning the number of samples read
+func playOut(t *testing.T, jb *rtpopus.JitterBuffer, arrivals []arrival, end time.Duration) int {
+	epoch := time.Unix(0, 0)
+	samples := 0
+	for now := time.Duration(0); now < end; now += 20 * time.Millisecond {
+		for len(arrivals) > 0 && arrivals[0].at <= now {
+			if err := jb.Push(arrivals[0].packet, epoch.Add(arrivals[0].at)); err != nil {
+				t.Fatalf("Failed to push packet: %v", err)
+			}
+			arrivals = arrivals[1:]
+		}
+		pcm, err := jb.Read()
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		samples += len(pcm) / 2
+	}
+	return samples
+}
+
+func TestJitterBuffer(t *testing.T) {
+	encoder, err := opus.NewEncoder(16000, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	encoder.CtlInt(opus.OPUS_SET_INBAND_FEC_REQUEST, 1)
+	encoder.CtlInt(opus.OPUS_SET_PACKET_LOSS_PERC_REQUEST, 20)
+
+	packetizer := rtpopus.NewPacketizer(111, 0)
+	var packets [][]byte
+	buf := make([]byte, 1500)
+	for i := 0; i < 100; i++ {
+		n, err := encoder.Encode(testFrame(i, false), buf)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		packet, _ := packetizer.Packetize(buf[:n])
+		data, _ := packet.Marshal()
+		packets = append(packets, data)
+	}
+
+	decoder, err := opus.NewDecoder(16000, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+	jb := rtpopus.NewJitterBuffer(decoder, 16000, 1)
+
+	// Up to 50 ms of jitter, which reorders packets. Frame 30 is recovered by
+	// FEC from 31, of 60 and 61 only 61 is.
+	delay := func(i int) time.Duration { return time.Duration(i*37%6) * 10 * time.Millisecond }
+	arrivals := deliver(packets, delay, map[int]bool{30: true, 60: true, 61: true})
+	samples := playOut(t, jb, arrivals, 3*time.Second)
+
+	stats := jb.Stats()
+	t.Logf("%+v", stats)
+	// Packets late while the delay adapts to the jitter are lost as well
+	if stats.Received+stats.Late != 97 || stats.Lost != 3+stats.Late || stats.Duplicates != 0 {
+		t.Errorf("Unexpected packet counts %+v", stats)
+	}
+	if stats.ConcealedFEC+stats.ConcealedPLC != stats.Lost || stats.ConcealedFEC < 2 {
+		t.Errorf("Expected lost frames concealed, frames 30 and 61 by FEC, got %+v", stats)
+	}
+	if stats.Jitter < 5*time.Millisecond || stats.TargetDelay <= 20*time.Millisecond || stats.TargetDelay > 200*time.Millisecond {
+		t.Errorf("Unexpected jitter %v and target delay %v", stats.Jitter, stats.TargetDelay)
+	}
+	// Every read after the start returned a decoded or concealed frame, those
+	// after the end of the stream underruns
+	played := stats.Received - stats.Dropped + stats.ConcealedFEC + stats.ConcealedPLC + stats.Underruns
+	if samples != played*testFrameSize {
+		t.Errorf("Expected %d frames of samples, got %d samples", played, samples)
+	}
+}
+
+func TestJitterBufferDurationChange(t *testing.T) {
+	// An encoder switching frame durations leaves out the FEC of the first
+	// packet after the switch, so the 40 ms packets come from a second
+	// encoder coding 40 ms frames throughout
+	var encoders [2]*opus.OpusEncoder
+	for i := range encoders {
+		encoder, err := opus.NewEncoder(16000, 1, opus.OpusApplicationVoIP)
+		if err != nil {
+			t.Fatalf("Failed to create encoder: %v", err)
+		}
+		defer encoder.Close()
+		encoder.SetBitrate(24000)
+		encoder.CtlInt(opus.OPUS_SET_INBAND_FEC_REQUEST, 1)
+		encoder.CtlInt(opus.OPUS_SET_PACKET_LOSS_PERC_REQUEST, 20)
+		encoders[i] = encoder
+	}
+
+	// 20 ms packets, then 40 ms ones, arriving on time
+	packetizer := rtpopus.NewPacketizer(111, 0)
+	var arrivals []arrival
+	buf := make([]byte, 1500)
+	at := time.Duration(0)
+	for i := 0; i < 15; i++ {
+		short, err := encoders[0].Encode(testFrame(i, false), buf)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		payload := buf[:short]
+		long, err := encoders[1].Encode(append(testFrame(2*i, false), testFrame(2*i+1, false)...), buf[short:])
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		if i >= 10 {
+			payload = buf[short : short+long]
+		}
+		packet, _ := packetizer.Packetize(payload)
+		data, _ := packet.Marshal()
+		// The last 20 ms packet is lost before the first 40 ms one, whose
+		// FEC covers 40 ms, and a 40 ms packet between two others
+		if i != 9 && i != 12 {
+			arrivals = append(arrivals, arrival{data, at})
+		}
+		if has, _ := opus.PacketHasLBRR(payload); (i == 10 || i == 13) && !has {
+			t.Fatalf("Expected FEC in packet %d", i)
+		}
+		if i < 10 {
+			at += 20 * time.Millisecond
+		} else {
+			at += 40 * time.Millisecond
+		}
+	}
+
+	decoder, err := opus.NewDecoder(16000, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+	jb := rtpopus.NewJitterBuffer(decoder, 16000, 1)
+	epoch := time.Unix(0, 0)
+	for now := time.Duration(0); now < time.Second; {
+		for len(arrivals) > 0 && arrivals[0].at <= now {
+			if err := jb.Push(arrivals[0].packet, epoch.Add(arrivals[0].at)); err != nil {
+				t.Fatalf("Failed to push packet: %v", err)
+			}
+			arrivals = arrivals[1:]
+		}
+		pcm, err := jb.Read()
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		// Read once per frame duration
+		now += max(time.Duration(len(pcm)/2)*time.Second/16000, 20*time.Millisecond)
+	}
+
+	// Only the gap of the packet's own duration is recovered by FEC
+	stats := jb.Stats()
+	if stats.Lost != 2 || stats.ConcealedFEC != 1 || stats.ConcealedPLC != 1 {
+		t.Errorf("Expected one gap concealed by FEC and one by PLC, got %+v", stats)
+	}
+}
+
+func TestJitterBufferLate(t *testing.T) {
+	packets := encodePackets(t, rtpopus.NewPacketizer(111, 0), 20)
+	decoder, err := opus.NewDecoder(16000, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+	jb := rtpopus.NewJitterBuffer(decoder, 16000, 1)
+
+	// Packet 5 arrives 200 ms late, after later packets were played
+	delay := func(i int) time.Duration {
+		if i == 5 {
+			return 200 * time.Millisecond
+		}
+		return 0
+	}
+	playOut(t, jb, deliver(packets, delay, nil), time.Second)
+	stats := jb.Stats()
+	if stats.Late != 1 || stats.Lost != 1 || stats.ConcealedFEC+stats.ConcealedPLC != 1 {
+		t.Errorf("Expected packet 5 late and concealed, got %+v", stats)
+	}
+}
+
+func TestRED(t *testing.T) {
+	blocks := []rtpopus.REDBlock{
+		{PayloadType: 111, TimestampOffset: 1920, Payload: []byte{1, 2, 3}},
+		{PayloadType: 111, TimestampOffset: 960, Payload: nil},
+		{PayloadType: 111, Payload: []byte{4, 5}},
+	}
+	payload, err := rtpopus.MarshalRED(blocks)
+	if err != nil {
+		t.Fatalf("Failed to marshal RED payload: %v", err)
+	}
+	if !bytes.Equal(payload, []byte{0xef, 0x1e, 0x00, 0x03, 0xef, 0x0f, 0x00, 0x00, 0x6f, 1, 2, 3, 4, 5}) {
+		t.Errorf("Unexpected RED payload % x", payload)
+	}
+	parsed, err := rtpopus.UnmarshalRED(payload)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal RED payload: %v", err)
+	}
+	if len(parsed) != 3 || parsed[0].TimestampOffset != 1920 || !bytes.Equal(parsed[0].Payload, blocks[0].Payload) ||
+		len(parsed[1].Payload) != 0 || parsed[2].PayloadType != 111 || !bytes.Equal(parsed[2].Payload, blocks[2].Payload) {
+		t.Errorf("RED blocks mismatch: %+v", parsed)
+	}
+	for _, bad := range [][]byte{{}, {0xef, 0x1e, 0x00}, {0xef, 0x1e, 0x00, 0x03, 0x6f, 1}} {
+		if _, err := rtpopus.UnmarshalRED(bad); err == nil {
+			t.Errorf("Expected error for % x", bad)
+		}
+	}
+
+	// Send with a redundancy distance of 2, losing runs of 1, 2 and 3 packets
+	encoder, err := opus.NewEncoder(16000, 1, opus.OpusApplicationVoIP)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	packetizer := rtpopus.NewREDPacketizer(63, 111, 0, 2)
+	var frames, packets [][]byte
+	buf := make([]byte, 1500)
+	for i := 0; i < 30; i++ {
+		n, err := encoder.Encode(testFrame(i, false), buf)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		frames = append(frames, append([]byte(nil), buf[:n]...))
+		packet, err := packetizer.Packetize(buf[:n])
+		if err != nil {
+			t.Fatalf("Failed to packetize: %v", err)
+		}
+		if packet.PayloadType != 63 {
+			t.Fatalf("Expected RED payload type, got %d", packet.PayloadType)
+		}
+		data, err := packet.Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal packet: %v", err)
+		}
+		packets = append(packets, data)
+	}
+
+	lost := map[int]bool{5: true, 10: true, 11: true, 20: true, 21: true, 22: true}
+	unpacker := rtpopus.NewREDUnpacker(63)
+	depacketizer := rtpopus.NewDepacketizer(0)
+	depacketizer.PayloadType = 111
+	var received []rtpopus.Frame
+	for i, data := range packets {
+		if lost[i] {
+			continue
+		}
+		unpacked, err := unpacker.Unpack(data)
+		if err != nil {
+			t.Fatalf("Failed to unpack packet %d: %v", i, err)
+		}
+		if i == 3 {
+			// A duplicate adds nothing
+			if again, _ := unpacker.Unpack(data); len(again) != 0 {
+				t.Errorf("Expected duplicate dropped, got %d packets", len(again))
+			}
+		}
+		for _, data := range unpacked {
+			if err := depacketizer.Push(data); err != nil {
+				t.Fatalf("Failed to push packet %d: %v", i, err)
+			}
+		}
+		for {
+			frame, ok := depacketizer.Pop()
+			if !ok {
+				break
+			}
+			received = append(received, frame)
+		}
+	}
+
+	// Only the oldest packet of the run of 3 is beyond the distance
+	if len(received) != 29 {
+		t.Fatalf("Expected 29 frames, got %d", len(received))
+	}
+	for _, frame := range received {
+		i := int(frame.SequenceNumber - received[0].SequenceNumber)
+		if !bytes.Equal(frame.Payload, frames[i]) {
+			t.Errorf("Frame %d payload mismatch", i)
+		}
+		if expected := i == 21; (frame.Lost == 1) != expected {
+			t.Errorf("Frame %d: unexpected %d lost", i, frame.Lost)
+		}
+	}
+	if stats := unpacker.Stats(); stats != (rtpopus.REDStats{Received: 24, Recovered: 5, Duplicates: 1}) {
+		t.Errorf("Unexpected RED stats %+v", stats)
+	}
+}
@@ -0,0 +1,261 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// unknownSize is the chunk size of streamed files whose size was not known
+// when the header was written, and of RF64 sizes held in the ds64 chunk
+const unknownSize = 0xffffffff
+
+// Reader reads the samples of a WAV file
+type Reader struct {
+	format Format
+	frames int64     // sample frames in the data chunk, -1 if unknown
+	data   io.Reader // the data chunk
+	buf    []byte
+	pcm    []byte // frame buffer of EncodeFrame
+}
+
+// NewReader reads the header of a RIFF or RF64 WAV file from r, leaving r at
+// the start of the samples. Chunks other than the format and the ds64 chunk
+// of RF64 files are skipped. A data chunk of unknown size, as written by
+// streaming writers, extends to the end of r.
+func NewReader(r io.Reader) (*Reader, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("failed to read RIFF header: %w", err)
+	}
+	id := string(header[:4])
+	if id != "RIFF" && id != "RF64" || string(header[8:]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+	rf64 := id == "RF64"
+
+	var format Format
+	hasFormat := false
+	ds64DataSize := int64(-1)
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			if err == io.EOF {
+				return nil, errors.New("no data chunk")
+			}
+			return nil, fmt.Errorf("failed to read chunk header: %w", err)
+		}
+		id, size := string(chunk[:4]), binary.LittleEndian.Uint32(chunk[4:])
+
+		switch id {
+		case "fmt ", "ds64":
+			if size > 1024 {
+				return nil, fmt.Errorf("%q chunk too large", id)
+			}
+			body := make([]byte, size+size&1)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return nil, fmt.Errorf("failed to read %q chunk: %w", id, err)
+			}
+			body = body[:size]
+			if id == "ds64" {
+				if size < 24 {
+					return nil, errors.New("ds64 chunk too short")
+				}
+				ds64DataSize = int64(binary.LittleEndian.Uint64(body[8:]))
+				continue
+			}
+			var err error
+			if format, err = parseFormat(body); err != nil {
+				return nil, err
+			}
+			hasFormat = true
+
+		case "data":
+			if !hasFormat {
+				return nil, errors.New("data chunk before fmt chunk")
+			}
+			dataSize := int64(size)
+			switch {
+			case size == unknownSize && rf64 && ds64DataSize >= 0:
+				dataSize = ds64DataSize
+			case size == unknownSize:
+				dataSize = -1
+			}
+			wr := &Reader{format: format, frames: -1, data: r}
+			if dataSize >= 0 {
+				wr.frames = dataSize / int64(format.frameSize())
+				wr.data = io.LimitReader(r, dataSize)
+			}
+			return wr, nil
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size)+int64(size&1)); err != nil {
+				return nil, fmt.Errorf("failed to skip %q chunk: %w", id, err)
+			}
+		}
+	}
+}
+
+// parseFormat parses the body of a fmt chunk
+func parseFormat(body []byte) (Format, error) {
+	if len(body) < 16 {
+		return Format{}, errors.New("fmt chunk too short")
+	}
+	code := binary.LittleEndian.Uint16(body)
+	format := Format{
+		Channels:   int(binary.LittleEndian.Uint16(body[2:])),
+		SampleRate: int(binary.LittleEndian.Uint32(body[4:])),
+	}
+	blockAlign := int(binary.LittleEndian.Uint16(body[12:]))
+	bits := int(binary.LittleEndian.Uint16(body[14:]))
+
+	if code == formatExtensible {
+		if len(body) < 40 {
+			return Format{}, errors.New("WAVE_FORMAT_EXTENSIBLE fmt chunk too short")
+		}
+		format.ChannelMask = binary.LittleEndian.Uint32(body[20:])
+		if [14]byte(body[26:40]) != subFormatSuffix {
+			return Format{}, errors.New("unsupported WAVE_FORMAT_EXTENSIBLE subformat")
+		}
+		code = binary.LittleEndian.Uint16(body[24:])
+	}
+	switch {
+	case code == formatPCM && bits == 16:
+		format.SampleFormat = PCM16
+	case code == formatPCM && bits == 24:
+		format.SampleFormat = PCM24
+	case code == formatPCM && bits == 32:
+		format.SampleFormat = PCM32
+	case code == formatIEEEFloat && bits == 32:
+		format.SampleFormat = Float32
+	default:
+		return Format{}, fmt.Errorf("unsupported WAV format %d with %d bits", code, bits)
+	}
+	if err := format.validate(); err != nil {
+		return Format{}, err
+	}
+	if blockAlign != format.frameSize() {
+		return Format{}, errors.New("invalid block alignment")
+	}
+	return format, nil
+}
+
+// Format returns the format of the samples
+func (r *Reader) Format() Format {
+	return r.format
+}
+
+// Frames returns the number of sample frames, one sample per channel, or
+// -1 if the file does not tell
+func (r *Reader) Frames() int64 {
+	return r.frames
+}
+
+// Read reads sample data in the format of the file
+func (r *Reader) Read(p []byte) (int, error) {
+	return r.data.Read(p)
+}
+
+// readFrames reads up to frames whole sample frames into the internal
+// buffer, dropping a truncated frame at the end of the file
+func (r *Reader) readFrames(frames int) ([]byte, error) {
+	size := frames * r.format.frameSize()
+	if cap(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+	n, err := io.ReadFull(r.data, r.buf[:size])
+	n -= n % r.format.frameSize()
+	if n == 0 {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return r.buf[:n], nil
+}
+
+// ReadPCM16 reads whole sample frames into p as interleaved little-endian
+// 16-bit PCM, converting from the format of the file, and returns the number
+// of bytes read. It returns io.EOF at the end of the samples.
+func (r *Reader) ReadPCM16(p []byte) (int, error) {
+	data, err := r.readFrames(len(p) / (2 * r.format.Channels))
+	if err != nil {
+		return 0, err
+	}
+	size := r.format.SampleFormat.Size()
+	n := 0
+	for i := 0; i < len(data); i += size {
+		binary.LittleEndian.PutUint16(p[n:], uint16(toInt16(r.format.SampleFormat, data[i:])))
+		n += 2
+	}
+	return n, nil
+}
+
+// ReadFloat32 reads whole sample frames into samples, interleaved with full
+// scale at ±1, and returns the number of samples read. It returns io.EOF at
+// the end of the samples.
+func (r *Reader) ReadFloat32(samples []float32) (int, error) {
+	data, err := r.readFrames(len(samples) / r.format.Channels)
+	if err != nil {
+		return 0, err
+	}
+	size := r.format.SampleFormat.Size()
+	n := 0
+	for i := 0; i < len(data); i += size {
+		samples[n] = toFloat32(r.format.SampleFormat, data[i:])
+		n++
+	}
+	return n, nil
+}
+
+// EncodeFrame reads the next frameSize samples per channel and encodes them
+// with encoder, which takes the sample rate and channels of the file, into
+// packet. The last frame is padded with silence. It returns the size of the
+// packet, or io.EOF at the end of the samples.
+func (r *Reader) EncodeFrame(encoder Encoder, frameSize int, packet []byte) (int, error) {
+	size := frameSize * r.format.Channels * 2
+	if cap(r.pcm) < size {
+		r.pcm = make([]byte, size)
+	}
+	pcm := r.pcm[:size]
+	n, err := r.ReadPCM16(pcm)
+	if err != nil {
+		return 0, err
+	}
+	clear(pcm[n:])
+	return encoder.Encode(pcm, packet)
+}
+
+// toInt16 converts the sample at the start of b to 16 bits, dropping the
+// low bits of wider integers
+func toInt16(format SampleFormat, b []byte) int16 {
+	switch format {
+	case PCM16:
+		return int16(binary.LittleEndian.Uint16(b))
+	case PCM24:
+		return int16(binary.LittleEndian.Uint16(b[1:]))
+	case PCM32:
+		return int16(binary.LittleEndian.Uint16(b[2:]))
+	}
+	return floatToInt16(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+}
+
+// toFloat32 converts the sample at the start of b to a float
+func toFloat32(format SampleFormat, b []byte) float32 {
+	switch format {
+	case PCM16:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case PCM24:
+		return float32(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / (1 << 23)
+	case PCM32:
+		return float32(float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31))
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(b))
+}
+
+// floatToInt16 converts a float sample to 16 bits, rounding and clipping
+func floatToInt16(v float32) int16 {
+	return int16(min(max(math.Round(float64(v)*(1<<15)), math.MinInt16), math.MaxInt16))
+}
@@ -0,0 +1,138 @@
+// Package wav reads and writes WAV files: RIFF and RF64 (EBU Tech 3306)
+// WAVE files with 16, 24 or 32-bit integer or 32-bit float samples,
+// including WAVE_FORMAT_EXTENSIBLE channel masks. Readers and writers
+// convert from and to the interleaved little-endian 16-bit PCM that
+// OpusEncoder.Encode takes and OpusDecoder.Decode returns.
+package wav
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SampleFormat is the coding of the samples in a WAV file
+type SampleFormat int
+
+// Sample formats
+const (
+	PCM16   SampleFormat = iota + 1 // signed 16-bit integer
+	PCM24                           // signed 24-bit integer, packed in 3 bytes
+	PCM32                           // signed 32-bit integer
+	Float32                         // IEEE 754 32-bit float, full scale at ±1
+)
+
+// Size returns the size of a sample in bytes
+func (f SampleFormat) Size() int {
+	switch f {
+	case PCM16:
+		return 2
+	case PCM24:
+		return 3
+	case PCM32, Float32:
+		return 4
+	}
+	return 0
+}
+
+// String returns the name of the sample format
+func (f SampleFormat) String() string {
+	switch f {
+	case PCM16:
+		return "PCM16"
+	case PCM24:
+		return "PCM24"
+	case PCM32:
+		return "PCM32"
+	case Float32:
+		return "Float32"
+	}
+	return fmt.Sprintf("SampleFormat(%d)", int(f))
+}
+
+// Speaker positions of WAVE_FORMAT_EXTENSIBLE channel masks. The channels
+// of a file are in the order of their bits.
+const (
+	SpeakerFrontLeft          = 0x1
+	SpeakerFrontRight         = 0x2
+	SpeakerFrontCenter        = 0x4
+	SpeakerLowFrequency       = 0x8
+	SpeakerBackLeft           = 0x10
+	SpeakerBackRight          = 0x20
+	SpeakerFrontLeftOfCenter  = 0x40
+	SpeakerFrontRightOfCenter = 0x80
+	SpeakerBackCenter         = 0x100
+	SpeakerSideLeft           = 0x200
+	SpeakerSideRight          = 0x400
+)
+
+// DefaultChannelMask returns the usual channel mask for a channel count:
+// mono, stereo, 3.0, quad, 5.0, 5.1, 6.1 and 7.1, or 0 for other counts
+func DefaultChannelMask(channels int) uint32 {
+	switch channels {
+	case 1:
+		return SpeakerFrontCenter
+	case 2:
+		return SpeakerFrontLeft | SpeakerFrontRight
+	case 3:
+		return SpeakerFrontLeft | SpeakerFrontRight | SpeakerFrontCenter
+	case 4:
+		return SpeakerFrontLeft | SpeakerFrontRight | SpeakerBackLeft | SpeakerBackRight
+	case 5:
+		return SpeakerFrontLeft | SpeakerFrontRight | SpeakerFrontCenter | SpeakerBackLeft | SpeakerBackRight
+	case 6:
+		return SpeakerFrontLeft | SpeakerFrontRight | SpeakerFrontCenter | SpeakerLowFrequency | SpeakerBackLeft | SpeakerBackRight
+	case 7:
+		return SpeakerFrontLeft | SpeakerFrontRight | SpeakerFrontCenter | SpeakerLowFrequency | SpeakerBackCenter | SpeakerSideLeft | SpeakerSideRight
+	case 8:
+		return SpeakerFrontLeft | SpeakerFrontRight | SpeakerFrontCenter | SpeakerLowFrequency | SpeakerBackLeft | SpeakerBackRight | SpeakerSideLeft | SpeakerSideRight
+	}
+	return 0
+}
+
+// Format describes the audio of a WAV file
+type Format struct {
+	SampleRate   int
+	Channels     int
+	SampleFormat SampleFormat
+	ChannelMask  uint32 // speaker positions, 0 if unspecified
+}
+
+// frameSize returns the size of a sample frame, one sample per channel
+func (f Format) frameSize() int {
+	return f.Channels * f.SampleFormat.Size()
+}
+
+// validate checks that the format can be read and written
+func (f Format) validate() error {
+	if f.SampleRate <= 0 {
+		return errors.New("invalid sample rate")
+	}
+	if f.Channels <= 0 || f.Channels > 0xffff {
+		return errors.New("invalid channel count")
+	}
+	if f.SampleFormat.Size() == 0 {
+		return errors.New("unsupported sample format")
+	}
+	return nil
+}
+
+// WAVE format codes
+const (
+	formatPCM        = 1
+	formatIEEEFloat  = 3
+	formatExtensible = 0xfffe
+)
+
+// subFormatSuffix follows the format code in the SubFormat GUID of
+// WAVE_FORMAT_EXTENSIBLE
+var subFormatSuffix = [14]byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xaa, 0x00, 0x38, 0x9b, 0x71}
+
+// Encoder is implemented by *opus.OpusEncoder
+type Encoder interface {
+	Encode(input []byte, output []byte) (int, error)
+}
+
+// Decoder is implemented by *opus.OpusDecoder
+type Decoder interface {
+	Decode(input []byte, output []byte) (int, error)
+}
@@ -0,0 +1,271 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justa-cai/go-libopus/opus"
+	"github.com/justa-cai/go-libopus/wav"
+)
+
+// testSamples generates frames of a 440 Hz tone at half scale, with a
+// different phase on each channel
+func testSamples(frames, channels int) []float32 {
+	samples := make([]float32, frames*channels)
+	for i := range samples {
+		frame, channel := i/channels, i%channels
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*440*float64(frame)/48000+float64(channel)))
+	}
+	return samples
+}
+
+// writeFile writes samples to a new file with format and returns its contents
+func writeFile(t *testing.T, format wav.Format, samples []float32, rf64 bool) []byte {
+	path := filepath.Join(t.TempDir(), "test.wav")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+	writer, err := wav.NewWriter(file, format)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	writer.RF64 = rf64
+	if err := writer.WriteFloat32(samples); err != nil {
+		t.Fatalf("Failed to write samples: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	// Chunks after the data are not samples
+	if _, err := file.Write([]byte("LIST\x04\x00\x00\x00INFO")); err != nil {
+		t.Fatalf("Failed to write trailing chunk: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return data
+}
+
+// readAll reads all samples of a file as floats
+func readAll(t *testing.T, reader *wav.Reader) []float32 {
+	var samples []float32
+	buf := make([]float32, 1000*reader.Format().Channels)
+	for {
+		n, err := reader.ReadFloat32(buf)
+		if err == io.EOF {
+			return samples
+		}
+		if err != nil {
+			t.Fatalf("Failed to read samples: %v", err)
+		}
+		samples = append(samples, buf[:n]...)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tolerance := map[wav.SampleFormat]float64{wav.PCM16: 1.0 / (1 << 15), wav.PCM24: 1.0 / (1 << 23), wav.PCM32: 1e-7, wav.Float32: 0}
+	for _, sampleFormat := range []wav.SampleFormat{wav.PCM16, wav.PCM24, wav.PCM32, wav.Float32} {
+		for _, channels := range []int{1, 2, 6} {
+			for _, rf64 := range []bool{false, true} {
+				format := wav.Format{SampleRate: 48000, Channels: channels, SampleFormat: sampleFormat}
+				if channels == 6 {
+					format.ChannelMask = wav.DefaultChannelMask(6)
+				}
+				// An odd number of frames, for odd data sizes
+				samples := testSamples(1001, channels)
+				data := writeFile(t, format, samples, rf64)
+
+				name := sampleFormat.String()
+				if string(data[:4]) != map[bool]string{false: "RIFF", true: "RF64"}[rf64] {
+					t.Errorf("%s: unexpected file type %q", name, data[:4])
+				}
+				if !rf64 && int(binary.LittleEndian.Uint32(data[4:])) != len(data)-12-8 {
+					t.Errorf("%s: RIFF size %d for %d bytes", name, binary.LittleEndian.Uint32(data[4:]), len(data))
+				}
+				if (len(data)-12)%2 != 0 {
+					t.Errorf("%s: data chunk not padded", name)
+				}
+
+				reader, err := wav.NewReader(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("%s: failed to read header: %v", name, err)
+				}
+				if reader.Format() != format {
+					t.Errorf("%s: expected format %+v, got %+v", name, format, reader.Format())
+				}
+				if reader.Frames() != 1001 {
+					t.Errorf("%s: expected 1001 frames, got %d", name, reader.Frames())
+				}
+				read := readAll(t, reader)
+				if len(read) != len(samples) {
+					t.Fatalf("%s: expected %d samples, got %d", name, len(samples), len(read))
+				}
+				for i := range read {
+					if math.Abs(float64(read[i]-samples[i])) > tolerance[sampleFormat] {
+						t.Fatalf("%s: sample %d is %v, expected %v", name, i, read[i], samples[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	// Stereo 16-bit is plain PCM, 24-bit and masks are extensible
+	for _, test := range []struct {
+		format wav.Format
+		code   uint16
+	}{
+		{wav.Format{SampleRate: 16000, Channels: 2, SampleFormat: wav.PCM16}, 1},
+		{wav.Format{SampleRate: 16000, Channels: 1, SampleFormat: wav.Float32}, 3},
+		{wav.Format{SampleRate: 16000, Channels: 1, SampleFormat: wav.PCM24}, 0xfffe},
+		{wav.Format{SampleRate: 16000, Channels: 2, SampleFormat: wav.PCM16, ChannelMask: wav.SpeakerFrontLeft | wav.SpeakerFrontCenter}, 0xfffe},
+	} {
+		data := writeFile(t, test.format, nil, false)
+		// fmt follows the RIFF header and the 28-byte JUNK chunk
+		if string(data[48:52]) != "fmt " || binary.LittleEndian.Uint16(data[56:]) != test.code {
+			t.Errorf("Expected format code %#x for %+v, got %#x", test.code, test.format, binary.LittleEndian.Uint16(data[56:]))
+		}
+		reader, err := wav.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Failed to read header: %v", err)
+		}
+		if reader.Format() != test.format || reader.Frames() != 0 {
+			t.Errorf("Expected empty %+v, got %+v with %d frames", test.format, reader.Format(), reader.Frames())
+		}
+	}
+
+	if _, err := wav.NewWriter(io.Discard, wav.Format{SampleRate: 16000, Channels: 0, SampleFormat: wav.PCM16}); err == nil {
+		t.Error("Expected error for no channels")
+	}
+	for _, bad := range [][]byte{
+		[]byte("RIFF\x00\x00\x00\x00AVI "),
+		[]byte("RIFF\x00\x00\x00\x00WAVEdata\x00\x00\x00\x00"),
+		// 8-bit PCM
+		[]byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1f\x00\x00\x40\x1f\x00\x00\x01\x00\x08\x00data\x00\x00\x00\x00"),
+	} {
+		if _, err := wav.NewReader(bytes.NewReader(bad)); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	// Without seeking the sizes stay unknown and the data runs to the end
+	var buf bytes.Buffer
+	writer, err := wav.NewWriter(&buf, wav.Format{SampleRate: 8000, Channels: 1, SampleFormat: wav.PCM16})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	pcm := []byte{1, 0, 2, 0, 0xff, 0xff}
+	if err := writer.WritePCM16(pcm); err != nil {
+		t.Fatalf("Failed to write samples: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	if binary.LittleEndian.Uint32(buf.Bytes()[4:]) != 0xffffffff {
+		t.Error("Expected unknown RIFF size")
+	}
+
+	reader, err := wav.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Failed to read header: %v", err)
+	}
+	if reader.Frames() != -1 {
+		t.Errorf("Expected unknown length, got %d frames", reader.Frames())
+	}
+	read := make([]byte, 100)
+	n, err := reader.ReadPCM16(read)
+	if err != nil || !bytes.Equal(read[:n], pcm) {
+		t.Errorf("Expected % x, got % x (%v)", pcm, read[:n], err)
+	}
+}
+
+func TestOpusAdapters(t *testing.T) {
+	const sampleRate, frameSize = 16000, 320
+
+	for _, channels := range []int{1, 2} {
+		// A second of tone at 16kHz in 24-bit
+		var file bytes.Buffer
+		writer, err := wav.NewWriter(&file, wav.Format{SampleRate: sampleRate, Channels: channels, SampleFormat: wav.PCM24})
+		if err != nil {
+			t.Fatalf("Failed to create writer: %v", err)
+		}
+		if err := writer.WriteFloat32(testSamples(sampleRate, channels)); err != nil {
+			t.Fatalf("Failed to write samples: %v", err)
+		}
+		writer.Close()
+		reader, err := wav.NewReader(&file)
+		if err != nil {
+			t.Fatalf("Failed to read header: %v", err)
+		}
+
+		encoder, err := opus.NewEncoder(sampleRate, channels, opus.OpusApplicationAudio)
+		if err != nil {
+			t.Fatalf("Failed to create encoder: %v", err)
+		}
+		defer encoder.Close()
+		decoder, err := opus.NewDecoder(sampleRate, channels)
+		if err != nil {
+			t.Fatalf("Failed to create decoder: %v", err)
+		}
+		defer decoder.Close()
+
+		var decoded bytes.Buffer
+		output, err := wav.NewWriter(&decoded, wav.Format{SampleRate: sampleRate, Channels: channels, SampleFormat: wav.Float32})
+		if err != nil {
+			t.Fatalf("Failed to create writer: %v", err)
+		}
+		packet := make([]byte, 1500)
+		packets, samples := 0, 0
+		for {
+			n, err := reader.EncodeFrame(encoder, frameSize, packet)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+			decodedSamples, err := output.DecodePacket(decoder, packet[:n])
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			packets++
+			samples += decodedSamples
+		}
+		output.Close()
+		if packets != 50 || samples != sampleRate {
+			t.Errorf("%d channels: expected 50 packets of 320 samples, got %d packets and %d samples", channels, packets, samples)
+		}
+
+		// The channels keep their own phase
+		result, err := wav.NewReader(&decoded)
+		if err != nil {
+			t.Fatalf("Failed to read decoded header: %v", err)
+		}
+		pcm := make([]float32, sampleRate*channels)
+		if n, err := result.ReadFloat32(pcm); err != nil || n != len(pcm) {
+			t.Fatalf("Failed to read decoded samples: %d, %v", n, err)
+		}
+		var power, difference float64
+		for i := sampleRate / 10 * channels; i < len(pcm); i += channels {
+			power += float64(pcm[i]) * float64(pcm[i])
+			difference += math.Abs(float64(pcm[i+channels-1] - pcm[i]))
+		}
+		if rms := math.Sqrt(power / (sampleRate * 0.9)); rms < 0.3 || rms > 0.4 {
+			t.Errorf("%d channels: expected the tone at RMS 0.35, got %.3f", channels, rms)
+		}
+		if channels == 2 && difference < sampleRate*0.1 {
+			t.Errorf("Expected the stereo channels to differ, got a mean difference of %.3f", difference/sampleRate)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// junkSize is the size of the JUNK chunk reserving room for the ds64 chunk
+// of RF64, which replaces it when the file outgrows RIFF
+const junkSize = 28
+
+// Writer writes a WAV file. The header is written by NewWriter with sizes
+// marking the length as unknown, as for a stream, and fixed up by Close if
+// the underlying writer is an io.Seeker. Files of 4 GiB and more are turned
+// into RF64 files.
+type Writer struct {
+	// RF64 makes Close write an RF64 file whatever its size
+	RF64 bool
+
+	w          io.Writer
+	format     Format
+	base       int64 // offset of the file in w, -1 if w cannot seek
+	headerSize int64
+	factOffset int64 // offset of the fact chunk, 0 if none
+	dataSize   int64
+	buf        []byte
+	pcm        []byte // decoding buffer of DecodePacket
+	closed     bool
+}
+
+// NewWriter writes the header of a WAV file with format to w. The file uses
+// WAVE_FORMAT_EXTENSIBLE if it has a channel mask, more than 2 channels or
+// integer samples of more than 16 bits.
+func NewWriter(w io.Writer, format Format) (*Writer, error) {
+	if err := format.validate(); err != nil {
+		return nil, err
+	}
+	wr := &Writer{w: w, format: format, base: -1}
+	if seeker, ok := w.(io.Seeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			wr.base = offset
+		}
+	}
+
+	code := uint16(formatPCM)
+	if format.SampleFormat == Float32 {
+		code = formatIEEEFloat
+	}
+	bits := uint16(format.SampleFormat.Size() * 8)
+	extensible := format.ChannelMask != 0 || format.Channels > 2 || format.SampleFormat == PCM24 || format.SampleFormat == PCM32
+
+	le := binary.LittleEndian
+	h := make([]byte, 0, 104)
+	h = append(h, "RIFF"...)
+	h = le.AppendUint32(h, unknownSize)
+	h = append(h, "WAVE"...)
+	h = append(h, "JUNK"...)
+	h = le.AppendUint32(h, junkSize)
+	h = append(h, make([]byte, junkSize)...)
+
+	h = append(h, "fmt "...)
+	switch {
+	case extensible:
+		h = le.AppendUint32(h, 40)
+		h = le.AppendUint16(h, formatExtensible)
+	case code == formatIEEEFloat:
+		h = le.AppendUint32(h, 18)
+		h = le.AppendUint16(h, code)
+	default:
+		h = le.AppendUint32(h, 16)
+		h = le.AppendUint16(h, code)
+	}
+	h = le.AppendUint16(h, uint16(format.Channels))
+	h = le.AppendUint32(h, uint32(format.SampleRate))
+	h = le.AppendUint32(h, uint32(format.SampleRate*format.frameSize()))
+	h = le.AppendUint16(h, uint16(format.frameSize()))
+	h = le.AppendUint16(h, bits)
+	switch {
+	case extensible:
+		h = le.AppendUint16(h, 22)
+		h = le.AppendUint16(h, bits)
+		h = le.AppendUint32(h, format.ChannelMask)
+		h = le.AppendUint16(h, code)
+		h = append(h, subFormatSuffix[:]...)
+	case code == formatIEEEFloat:
+		h = le.AppendUint16(h, 0)
+	}
+
+	// Formats other than PCM have a fact chunk with the number of frames
+	if code != formatPCM {
+		wr.factOffset = int64(len(h))
+		h = append(h, "fact"...)
+		h = le.AppendUint32(h, 4)
+		h = le.AppendUint32(h, unknownSize)
+	}
+	h = append(h, "data"...)
+	h = le.AppendUint32(h, unknownSize)
+	wr.headerSize = int64(len(h))
+
+	if _, err := w.Write(h); err != nil {
+		return nil, err
+	}
+	return wr, nil
+}
+
+// Format returns the format of the samples
+func (w *Writer) Format() Format {
+	return w.format
+}
+
+// Write writes sample data in the format of the file, which should be
+// whole sample frames
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("writer closed")
+	}
+	n, err := w.w.Write(p)
+	w.dataSize += int64(n)
+	return n, err
+}
+
+// WritePCM16 writes interleaved little-endian 16-bit PCM, converting it to
+// the format of the file
+func (w *Writer) WritePCM16(p []byte) error {
+	size := w.format.SampleFormat.Size()
+	buf := w.buffer(len(p) / 2 * size)
+	for i := 0; i+1 < len(p); i += 2 {
+		fromInt16(w.format.SampleFormat, buf[i/2*size:], int16(binary.LittleEndian.Uint16(p[i:])))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// WriteFloat32 writes interleaved samples with full scale at ±1, converting
+// them to the format of the file and clipping them for integer formats
+func (w *Writer) WriteFloat32(samples []float32) error {
+	size := w.format.SampleFormat.Size()
+	buf := w.buffer(len(samples) * size)
+	for i, v := range samples {
+		fromFloat32(w.format.SampleFormat, buf[i*size:], v)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// DecodePacket decodes packet with decoder, which produces the sample rate
+// and channels of the file, and writes the decoded samples. It returns the
+// number of samples per channel written.
+func (w *Writer) DecodePacket(decoder Decoder, packet []byte) (int, error) {
+	// Room for the longest Opus packet, 120 ms
+	if w.pcm == nil {
+		w.pcm = make([]byte, w.format.SampleRate*120/1000*w.format.Channels*2)
+	}
+	n, err := decoder.Decode(packet, w.pcm)
+	if err != nil {
+		return 0, err
+	}
+	return n, w.WritePCM16(w.pcm[:n*w.format.Channels*2])
+}
+
+// buffer returns the conversion buffer of size bytes
+func (w *Writer) buffer(size int) []byte {
+	if cap(w.buf) < size {
+		w.buf = make([]byte, size)
+	}
+	return w.buf[:size]
+}
+
+// Close pads the data chunk to an even size and, if the underlying writer
+// can seek, fixes up the sizes in the header. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	end := w.headerSize + w.dataSize
+	if w.dataSize%2 != 0 {
+		if _, err := w.w.Write([]byte{0}); err != nil {
+			return err
+		}
+		end++
+	}
+	if w.base < 0 {
+		return nil
+	}
+
+	seeker := w.w.(io.Seeker)
+	le := binary.LittleEndian
+	riffSize := end - 8
+	frames := w.dataSize / int64(w.format.frameSize())
+	rf64 := w.RF64 || riffSize > math.MaxUint32
+	patch := func(offset int64, data []byte) error {
+		if _, err := seeker.Seek(w.base+offset, io.SeekStart); err != nil {
+			return err
+		}
+		_, err := w.w.Write(data)
+		return err
+	}
+
+	riff := le.AppendUint32([]byte("RIFF"), uint32(riffSize))
+	data := le.AppendUint32([]byte("data"), uint32(w.dataSize))
+	if rf64 {
+		riff = le.AppendUint32([]byte("RF64"), unknownSize)
+		data = le.AppendUint32([]byte("data"), unknownSize)
+		ds64 := le.AppendUint32([]byte("ds64"), junkSize)
+		ds64 = le.AppendUint64(ds64, uint64(riffSize))
+		ds64 = le.AppendUint64(ds64, uint64(w.dataSize))
+		ds64 = le.AppendUint64(ds64, uint64(frames))
+		ds64 = le.AppendUint32(ds64, 0)
+		if err := patch(12, ds64); err != nil {
+			return err
+		}
+	}
+	if err := patch(0, riff); err != nil {
+		return err
+	}
+	if w.factOffset != 0 {
+		if err := patch(w.factOffset+8, le.AppendUint32(nil, uint32(min(frames, math.MaxUint32)))); err != nil {
+			return err
+		}
+	}
+	if err := patch(w.headerSize-8, data); err != nil {
+		return err
+	}
+	_, err := seeker.Seek(w.base+end, io.SeekStart)
+	return err
+}
+
+// fromInt16 stores a 16-bit sample at the start of b in format
+func fromInt16(format SampleFormat, b []byte, v int16) {
+	switch format {
+	case PCM16:
+		binary.LittleEndian.PutUint16(b, uint16(v))
+	case PCM24:
+		b[0] = 0
+		binary.LittleEndian.PutUint16(b[1:], uint16(v))
+	case PCM32:
+		binary.LittleEndian.PutUint32(b, uint32(v)<<16)
+	case Float32:
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)/(1<<15)))
+	}
+}
+
+// fromFloat32 stores a float sample at the start of b in format
+func fromFloat32(format SampleFormat, b []byte, v float32) {
+	switch format {
+	case PCM16:
+		binary.LittleEndian.PutUint16(b, uint16(floatToInt16(v)))
+	case PCM24:
+		s := int32(min(max(math.Round(float64(v)*(1<<23)), -(1<<23)), 1<<23-1))
+		b[0], b[1], b[2] = byte(s), byte(s>>8), byte(s>>16)
+	case PCM32:
+		s := int32(min(max(math.Round(float64(v)*(1<<31)), math.MinInt32), math.MaxInt32))
+		binary.LittleEndian.PutUint32(b, uint32(s))
+	case Float32:
+		binary.LittleEndian.PutUint32(b, math.Float32bits(v))
+	}
+}